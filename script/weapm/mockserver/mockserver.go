@@ -0,0 +1,306 @@
+// Package mockserver 提供离线的 WEAPM-LOGSERVER 模拟服务端,用于本地开发、演示及测试
+//
+// 实现了数据大盘、集群及子系统的查询接口,返回与客户端响应模型一致的固定数据:
+//
+//	srv := mockserver.NewMockServer()
+//	defer srv.Close()
+//	client := NewClient(DefaultConfig(srv.URL))
+//
+// 不校验认证信息,变更接口及未实现的接口返回 404
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+// ==================== 模拟数据 ====================
+
+type cluster struct {
+	ClusterName   string `json:"clustername"`
+	IsDefault     int    `json:"isdefault"`
+	Topic         string `json:"topic"`
+	BucketNames   string `json:"bucketnames"`
+	BackendDomain string `json:"backenddomain"`
+	StorageDomain string `json:"storagedomain"`
+}
+
+type node struct {
+	Address     string `json:"address"`
+	ClusterName string `json:"clustername"`
+	Role        string `json:"role"`
+	Status      string `json:"status"`
+	CpuLimit    string `json:"cpulimit"`
+	MemLimit    string `json:"memlimit"`
+}
+
+type subsystem struct {
+	ID             int    `json:"id"`
+	SubsysID       string `json:"subsys_id"`
+	SubsysName     string `json:"subsys_name"`
+	SubsysChtname  string `json:"subsys_chtname"`
+	DevDept        string `json:"devdept"`
+	BusinessOwner  string `json:"business_owner"`
+	SubsystemOwner string `json:"subsystem_owner"`
+	SystemName     string `json:"system_name"`
+	State          string `json:"state"`
+	ImportantLevel string `json:"important_level"`
+
+	cluster string
+	traffic int64
+}
+
+var clusters = []cluster{
+	{ClusterName: "LOG001", IsDefault: 1, Topic: "log-topic-001", BucketNames: "log-bucket-001", BackendDomain: "backend001.example.com", StorageDomain: "storage001.example.com"},
+	{ClusterName: "LOG002", Topic: "log-topic-002", BucketNames: "log-bucket-002", BackendDomain: "backend002.example.com", StorageDomain: "storage002.example.com"},
+}
+
+var nodes = []node{
+	{Address: "10.0.1.11", ClusterName: "LOG001", Role: "master", Status: "running", CpuLimit: "8", MemLimit: "16"},
+	{Address: "10.0.1.12", ClusterName: "LOG001", Role: "write", Status: "running", CpuLimit: "8", MemLimit: "16"},
+	{Address: "10.0.1.13", ClusterName: "LOG001", Role: "read", Status: "running", CpuLimit: "4", MemLimit: "8"},
+	{Address: "10.0.2.11", ClusterName: "LOG002", Role: "master", Status: "running", CpuLimit: "8", MemLimit: "16"},
+	{Address: "10.0.2.12", ClusterName: "LOG002", Role: "write", Status: "stopped", CpuLimit: "8", MemLimit: "16"},
+}
+
+var subsystems = []subsystem{
+	{ID: 1, SubsysID: "SYS001", SubsysName: "payment", SubsysChtname: "支付系统", DevDept: "交易研发部", BusinessOwner: "zhangsan", SubsystemOwner: "lisi", SystemName: "trade", State: "enabled", ImportantLevel: "A", cluster: "LOG001", traffic: 2048},
+	{ID: 2, SubsysID: "SYS002", SubsysName: "order", SubsysChtname: "订单系统", DevDept: "交易研发部", BusinessOwner: "zhangsan", SubsystemOwner: "wangwu", SystemName: "trade", State: "enabled", ImportantLevel: "A", cluster: "LOG001", traffic: 1024},
+	{ID: 3, SubsysID: "SYS003", SubsysName: "account", SubsysChtname: "账户系统", DevDept: "基础研发部", BusinessOwner: "zhaoliu", SubsystemOwner: "sunqi", SystemName: "core", State: "enabled", ImportantLevel: "B", cluster: "LOG002", traffic: 512},
+	{ID: 4, SubsysID: "SYS004", SubsysName: "report", SubsysChtname: "报表系统", DevDept: "数据部", BusinessOwner: "zhouba", SubsystemOwner: "wujiu", SystemName: "bi", State: "disabled", ImportantLevel: "C", cluster: "LOG002", traffic: 0},
+}
+
+// clusterSubsystem 集群纳管的子系统 (LogSubClusterSubSystem)
+func clusterSubsystem(s subsystem) map[string]interface{} {
+	return map[string]interface{}{
+		"clustername":     s.cluster,
+		"subsystemid":     s.SubsysID,
+		"subsys_name":     s.SubsysName,
+		"subsystem_owner": s.SubsystemOwner,
+		"business_owner":  s.BusinessOwner,
+		"devdept":         s.DevDept,
+		"traffic":         s.traffic,
+		"status":          s.State,
+	}
+}
+
+func findCluster(name string) (cluster, bool) {
+	for _, c := range clusters {
+		if c.ClusterName == name {
+			return c, true
+		}
+	}
+	return cluster{}, false
+}
+
+func findSubsystem(id string) (subsystem, bool) {
+	for _, s := range subsystems {
+		if s.SubsysID == id {
+			return s, true
+		}
+	}
+	return subsystem{}, false
+}
+
+// ==================== 接口实现 ====================
+
+// writeResult 以 {"code": 0, "message": "success", "result": ...} 格式返回
+func writeResult(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "message": "success", "result": result})
+}
+
+func writeNotFound(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": http.StatusNotFound, "message": message})
+}
+
+func dashboard() map[string]interface{} {
+	var clusterTraffic, logCounts []map[string]interface{}
+	for i, c := range clusters {
+		clusterTraffic = append(clusterTraffic, map[string]interface{}{
+			"clusterName":  c.ClusterName,
+			"trafficBytes": int64(i+1) << 30,
+			"timestamp":    "2026-01-01T00:00:00Z",
+		})
+		logCounts = append(logCounts, map[string]interface{}{
+			"clustername":  c.ClusterName,
+			"total_log_gb": 300 * (i + 1),
+			"capacity":     1024,
+		})
+	}
+	var top []map[string]interface{}
+	for _, s := range subsystems {
+		top = append(top, map[string]interface{}{
+			"department":      s.DevDept,
+			"subsys_name":     s.SubsysName,
+			"business_owner":  s.BusinessOwner,
+			"subsystem_owner": s.SubsystemOwner,
+			"subsys_id":       s.SubsysID,
+			"cluster_name":    s.cluster,
+			"total_log_mb":    s.traffic,
+		})
+	}
+	return map[string]interface{}{
+		"subsystemCount":     len(subsystems),
+		"clusterNum":         len(clusters),
+		"clusterTrafficData": clusterTraffic,
+		"topSubsystems":      top,
+		"clusterLogCounts":   logCounts,
+	}
+}
+
+func clusterDetail(c cluster) map[string]interface{} {
+	groups := map[string][]node{}
+	var roles []string
+	for _, n := range nodes {
+		if n.ClusterName != c.ClusterName {
+			continue
+		}
+		if _, ok := groups[n.Role]; !ok {
+			roles = append(roles, n.Role)
+		}
+		groups[n.Role] = append(groups[n.Role], n)
+	}
+	var nodeGroups []map[string]interface{}
+	for _, role := range roles {
+		nodeGroups = append(nodeGroups, map[string]interface{}{"role": role, "nodes": groups[role]})
+	}
+
+	managed := []map[string]interface{}{}
+	var peak int64
+	for _, s := range subsystems {
+		if s.cluster == c.ClusterName {
+			managed = append(managed, clusterSubsystem(s))
+			peak += s.traffic
+		}
+	}
+	return map[string]interface{}{
+		"clusterInfo":       c,
+		"nodeGroups":        nodeGroups,
+		"managedSubSystems": managed,
+		"reportData": map[string]interface{}{
+			"peakTraffic":     peak,
+			"peakTime":        "2026-01-01T10:00:00Z",
+			"totalSubSystems": len(managed),
+			"topicBacklog":    0,
+		},
+	}
+}
+
+func subsystemDetail(s subsystem) map[string]interface{} {
+	return map[string]interface{}{
+		"subsystemInfo":     s,
+		"collected":         s.State == "enabled",
+		"scanFileWhitelist": []string{"/var/log/" + s.SubsysName + "/*.log"},
+		"expectedTraffic":   s.traffic,
+		"actualTraffic":     s.traffic * 9 / 10,
+		"keywordFilters":    []string{"ERROR", "FATAL"},
+		"clusterName":       s.cluster,
+		"instances": []map[string]interface{}{
+			{"address": "10.1.0.1", "files": []string{"/var/log/" + s.SubsysName + "/app.log"}},
+		},
+	}
+}
+
+// paginate 按 offset/limit 查询参数截取,未指定 limit 时返回 offset 之后的全部
+func paginate(r *http.Request, n int) (int, int) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 || offset > n {
+		offset = n
+	}
+	end := n
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit >= 0 && offset+limit < n {
+		end = offset + limit
+	}
+	return offset, end
+}
+
+// Handler 返回模拟服务端的 http.Handler,可挂载到自定义的服务上
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeNotFound(w, "模拟服务端不支持变更接口")
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/operation")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		switch {
+		case path == "/dashboard":
+			writeResult(w, dashboard())
+		case path == "/clusters":
+			writeResult(w, clusters)
+		case len(parts) == 2 && parts[0] == "clusters":
+			c, ok := findCluster(parts[1])
+			if !ok {
+				writeNotFound(w, "集群不存在: "+parts[1])
+				return
+			}
+			writeResult(w, clusterDetail(c))
+		case len(parts) == 3 && parts[0] == "cluster" && parts[2] == "subsystems":
+			managed := []map[string]interface{}{}
+			for _, s := range subsystems {
+				if s.cluster == parts[1] {
+					managed = append(managed, clusterSubsystem(s))
+				}
+			}
+			start, end := paginate(r, len(managed))
+			w.Header().Set("X-Total-Count", strconv.Itoa(len(managed)))
+			writeResult(w, managed[start:end])
+		case path == "/subsystems":
+			start, end := paginate(r, len(subsystems))
+			w.Header().Set("X-Total-Count", strconv.Itoa(len(subsystems)))
+			writeResult(w, subsystems[start:end])
+		case path == "/subsystems/search":
+			query := r.URL.Query()
+			matched := []subsystem{}
+			for _, s := range subsystems {
+				if id := query.Get("subsysId"); id != "" && !strings.Contains(s.SubsysID, id) {
+					continue
+				}
+				if c := query.Get("cluster"); c != "" && s.cluster != c {
+					continue
+				}
+				if dept := query.Get("devDept"); dept != "" && s.DevDept != dept {
+					continue
+				}
+				if owner := query.Get("businessOwner"); owner != "" && s.BusinessOwner != owner {
+					continue
+				}
+				if state := query.Get("state"); state != "" && s.State != state {
+					continue
+				}
+				matched = append(matched, s)
+			}
+			start, end := paginate(r, len(matched))
+			writeResult(w, matched[start:end])
+		case len(parts) == 3 && parts[0] == "subsystem" && parts[1] == "exists":
+			s, ok := findSubsystem(parts[2])
+			writeResult(w, map[string]interface{}{
+				"subsystemId":   parts[2],
+				"exists":        ok,
+				"subsystemName": s.SubsysName,
+				"clusterName":   s.cluster,
+			})
+		case len(parts) == 2 && parts[0] == "subsystem":
+			s, ok := findSubsystem(parts[1])
+			if !ok {
+				writeNotFound(w, "子系统不存在: "+parts[1])
+				return
+			}
+			writeResult(w, subsystemDetail(s))
+		default:
+			writeNotFound(w, "模拟服务端未实现该接口: "+r.URL.Path)
+		}
+	})
+}
+
+// NewMockServer 启动模拟服务端,调用方负责 Close
+func NewMockServer() *httptest.Server {
+	return httptest.NewServer(Handler())
+}
@@ -0,0 +1,139 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ==================== 分页 ====================
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		query              string
+		wantStart, wantEnd int
+	}{
+		{"", 0, 4},
+		{"limit=2", 0, 2},
+		{"offset=1&limit=2", 1, 3},
+		{"offset=3&limit=5", 3, 4},
+		{"offset=9", 4, 4},
+		{"offset=-1", 4, 4},
+		{"limit=-1", 0, 4},
+		{"limit=0", 0, 0},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/operation/subsystems?"+tt.query, nil)
+		if start, end := paginate(r, 4); start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("paginate(%q) = %d, %d, want %d, %d", tt.query, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+// ==================== 接口实现 ====================
+
+// get 请求 Handler 并解析响应中的 result
+func get(t *testing.T, method, target string) (*httptest.ResponseRecorder, json.RawMessage) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	var resp struct {
+		Code   int             `json:"code"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: invalid JSON %q", method, target, rec.Body.String())
+	}
+	if (rec.Code == http.StatusOK) != (resp.Code == 0) {
+		t.Errorf("%s %s: status %d with code %d", method, target, rec.Code, resp.Code)
+	}
+	return rec, resp.Result
+}
+
+func TestHandlerLists(t *testing.T) {
+	tests := []struct {
+		target    string
+		wantIDs   []string
+		wantTotal string
+	}{
+		{"/operation/subsystems?offset=1&limit=2", []string{"SYS002", "SYS003"}, "4"},
+		{"/operation/subsystems/search?cluster=LOG002", []string{"SYS003", "SYS004"}, ""},
+		{"/operation/subsystems/search?subsysId=SYS00&state=enabled&limit=2", []string{"SYS001", "SYS002"}, ""},
+		{"/operation/subsystems/search?devDept=" + "%E6%95%B0%E6%8D%AE%E9%83%A8", []string{"SYS004"}, ""},
+		{"/operation/cluster/LOG001/subsystems?offset=1", []string{"SYS002"}, "2"},
+		{"/operation/cluster/LOG009/subsystems", []string{}, "0"},
+	}
+	for _, tt := range tests {
+		rec, result := get(t, "GET", tt.target)
+		var items []map[string]interface{}
+		if err := json.Unmarshal(result, &items); err != nil {
+			t.Fatalf("GET %s: result %s is not a list", tt.target, result)
+		}
+		ids := []string{}
+		for _, item := range items {
+			id, _ := item["subsys_id"].(string)
+			if id == "" {
+				id, _ = item["subsystemid"].(string)
+			}
+			ids = append(ids, id)
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+			t.Errorf("GET %s = %v, want %v", tt.target, ids, tt.wantIDs)
+		}
+		if got := rec.Header().Get("X-Total-Count"); got != tt.wantTotal {
+			t.Errorf("GET %s X-Total-Count = %q, want %q", tt.target, got, tt.wantTotal)
+		}
+	}
+}
+
+func TestHandlerStatus(t *testing.T) {
+	tests := []struct {
+		method, target string
+		want           int
+	}{
+		{"GET", "/operation/dashboard", http.StatusOK},
+		{"GET", "/operation/clusters", http.StatusOK},
+		{"GET", "/operation/clusters/LOG001", http.StatusOK},
+		{"GET", "/operation/clusters/LOG009", http.StatusNotFound},
+		{"GET", "/operation/subsystem/SYS001", http.StatusOK},
+		{"GET", "/operation/subsystem/SYS999", http.StatusNotFound},
+		{"GET", "/operation/subsystem/exists/SYS999", http.StatusOK},
+		{"GET", "/operation/unknown", http.StatusNotFound},
+		// 变更接口不支持
+		{"POST", "/operation/clusters/LOG001/nodes", http.StatusNotFound},
+		{"DELETE", "/operation/subsystem/SYS001", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec, _ := get(t, tt.method, tt.target); rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestHandlerClusterDetail(t *testing.T) {
+	_, result := get(t, "GET", "/operation/clusters/LOG001")
+	var detail struct {
+		ClusterInfo struct {
+			ClusterName string `json:"clustername"`
+		} `json:"clusterInfo"`
+		NodeGroups []struct {
+			Role  string `json:"role"`
+			Nodes []node `json:"nodes"`
+		} `json:"nodeGroups"`
+	}
+	if err := json.Unmarshal(result, &detail); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, g := range detail.NodeGroups {
+		for _, n := range g.Nodes {
+			got = append(got, g.Role+"/"+n.Address)
+		}
+	}
+	// 只包含该集群的节点,按角色分组
+	if detail.ClusterInfo.ClusterName != "LOG001" || strings.Join(got, ",") != "master/10.0.1.11,write/10.0.1.12,read/10.0.1.13" {
+		t.Errorf("detail = %s, want the three LOG001 nodes grouped by role", result)
+	}
+}
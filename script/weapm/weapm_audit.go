@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ==================== 审计事件 ====================
+
+// AuditEvent 一次变更请求 (非 GET/HEAD) 的审计记录,以 JSON 写入 syslog
+type AuditEvent struct {
+	Time     time.Time `json:"time"`
+	Server   string    `json:"server"`
+	User     string    `json:"user,omitempty"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	OK       bool      `json:"ok"`
+	Status   int       `json:"status,omitempty"` // 失败时的 HTTP 状态码,非 HTTP 错误为 0
+	Error    string    `json:"error,omitempty"`
+}
+
+// auditWriter 审计事件的写入目标,*syslog.Writer 满足该接口
+type auditWriter interface {
+	Info(m string) error
+	Warning(m string) error
+	Close() error
+}
+
+// 审计 syslog 默认的 facility 及 tag
+const (
+	defaultAuditFacility = "local0"
+	defaultAuditTag      = "weapm"
+)
+
+// errSyslogUnsupported 当前平台不支持 syslog
+var errSyslogUnsupported = errors.New("当前平台不支持 syslog")
+
+// parseAuditSyslog 解析 audit_syslog 配置: "local" 写入本机 syslog,
+// "udp://host:514" / "tcp://host:514" 写入远程 syslog; 返回的 network 为空表示本机
+func parseAuditSyslog(target string) (network, addr string, err error) {
+	if target == "local" {
+		return "", "", nil
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+		return "", "", fmt.Errorf("audit_syslog 无效: %q (可用: local, udp://host:port, tcp://host:port)", target)
+	}
+	return u.Scheme, u.Host, nil
+}
+
+// newAuditWriter 按配置连接 syslog,连接失败或平台不支持时记录警告并关闭审计
+func newAuditWriter(config *Config) auditWriter {
+	network, addr, err := parseAuditSyslog(config.AuditSyslog)
+	if err == nil {
+		var w auditWriter
+		if w, err = dialSyslog(network, addr, config.AuditSyslogFacility, config.AuditSyslogTag); err == nil {
+			return w
+		}
+	}
+	logger.Printf("⚠️  无法写入 syslog,已关闭审计: %v", err)
+	return nil
+}
+
+// auditRequest 记录一次变更请求的结果,未配置审计或非变更请求时不记录
+// 写入失败只记录日志,不影响请求结果
+func (c *Client) auditRequest(method, endpoint string, err error) {
+	if c.audit == nil || method == "GET" || method == "HEAD" {
+		return
+	}
+
+	event := AuditEvent{
+		Time:     c.clock.Now(),
+		Server:   c.config.BaseURL,
+		User:     c.config.Username,
+		Method:   method,
+		Endpoint: endpoint,
+		OK:       err == nil,
+	}
+	if err != nil {
+		event.Status = statusCodeOf(err)
+		event.Error = err.Error()
+	}
+	line, _ := json.Marshal(event)
+
+	write := c.audit.Info
+	if err != nil {
+		write = c.audit.Warning
+	}
+	if err := write(string(line)); err != nil {
+		logger.Printf("⚠️  写入审计事件失败: %v", err)
+	}
+}
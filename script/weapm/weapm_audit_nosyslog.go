@@ -0,0 +1,8 @@
+//go:build windows || plan9
+
+package main
+
+// dialSyslog 当前平台没有 log/syslog,审计事件不写入
+func dialSyslog(network, addr, facility, tag string) (auditWriter, error) {
+	return nil, errSyslogUnsupported
+}
@@ -0,0 +1,38 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// syslogFacilities audit_syslog_facility 可用的取值
+var syslogFacilities = map[string]syslog.Priority{
+	"kern": syslog.LOG_KERN, "user": syslog.LOG_USER, "mail": syslog.LOG_MAIL,
+	"daemon": syslog.LOG_DAEMON, "auth": syslog.LOG_AUTH, "syslog": syslog.LOG_SYSLOG,
+	"lpr": syslog.LOG_LPR, "news": syslog.LOG_NEWS, "uucp": syslog.LOG_UUCP,
+	"cron": syslog.LOG_CRON, "authpriv": syslog.LOG_AUTHPRIV, "ftp": syslog.LOG_FTP,
+	"local0": syslog.LOG_LOCAL0, "local1": syslog.LOG_LOCAL1, "local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3, "local4": syslog.LOG_LOCAL4, "local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6, "local7": syslog.LOG_LOCAL7,
+}
+
+// dialSyslog 连接 syslog,network 为空时使用本机 syslog; facility/tag 为空时使用默认值
+func dialSyslog(network, addr, facility, tag string) (auditWriter, error) {
+	if facility == "" {
+		facility = defaultAuditFacility
+	}
+	if tag == "" {
+		tag = defaultAuditTag
+	}
+	priority, ok := syslogFacilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("未知的 syslog facility: %q", facility)
+	}
+	w, err := syslog.Dial(network, addr, priority|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
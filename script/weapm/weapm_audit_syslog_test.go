@@ -0,0 +1,55 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	api := newFakeAPI()
+	api.handle("DELETE /operation/subsystem/SYS001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+	client := newTestClient(t, api, func(c *Config) {
+		c.AuditSyslog = "udp://" + conn.LocalAddr().String()
+		c.AuditSyslogTag = "weapm-test"
+	})
+	if client.audit == nil {
+		t.Fatal("audit writer not created")
+	}
+	if err := client.DeleteSubsystem(context.Background(), "SYS001"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// local0 (16) * 8 + info (6) = 134
+	for _, want := range []string{"<134>", "weapm-test", `"method":"DELETE"`, `"ok":true`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("syslog message missing %q: %s", want, msg)
+		}
+	}
+}
+
+func TestDialSyslogUnknownFacility(t *testing.T) {
+	if _, err := dialSyslog("udp", "127.0.0.1:514", "local9", ""); err == nil || !strings.Contains(err.Error(), "local9") {
+		t.Errorf("error = %v, want an unknown facility error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ==================== 审计事件 ====================
+
+func TestParseAuditSyslog(t *testing.T) {
+	tests := []struct {
+		target      string
+		wantNetwork string
+		wantAddr    string
+		wantErr     bool
+	}{
+		{"local", "", "", false},
+		{"udp://syslog.example.com:514", "udp", "syslog.example.com:514", false},
+		{"tcp://10.0.0.1:601", "tcp", "10.0.0.1:601", false},
+		{"http://syslog.example.com", "", "", true},
+		{"udp://", "", "", true},
+		{"syslog.example.com:514", "", "", true},
+	}
+	for _, tt := range tests {
+		network, addr, err := parseAuditSyslog(tt.target)
+		if (err != nil) != tt.wantErr || network != tt.wantNetwork || addr != tt.wantAddr {
+			t.Errorf("parseAuditSyslog(%q) = %q, %q, %v, want %q, %q (wantErr %v)", tt.target, network, addr, err, tt.wantNetwork, tt.wantAddr, tt.wantErr)
+		}
+	}
+}
+
+// fakeAuditWriter 记录写入的审计事件及级别
+type fakeAuditWriter struct {
+	mu     sync.Mutex
+	events []AuditEvent
+	levels []string
+}
+
+func (w *fakeAuditWriter) write(level, m string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var event AuditEvent
+	if err := json.Unmarshal([]byte(m), &event); err != nil {
+		return err
+	}
+	w.events = append(w.events, event)
+	w.levels = append(w.levels, level)
+	return nil
+}
+
+func (w *fakeAuditWriter) Info(m string) error    { return w.write("info", m) }
+func (w *fakeAuditWriter) Warning(m string) error { return w.write("warning", m) }
+func (w *fakeAuditWriter) Close() error           { return nil }
+
+func TestAuditRequest(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", resultHandler(`[]`))
+	api.handle("DELETE /operation/subsystem/SYS001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+	api.handle("DELETE /operation/subsystem/SYS002", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusForbidden, 403, "无权限")
+	})
+	client := newTestClient(t, api, func(c *Config) { c.Username = "alice" })
+	now := time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC)
+	client.SetClock(NewFakeClock(now))
+	audit := &fakeAuditWriter{}
+	client.audit = audit
+	ctx := context.Background()
+
+	client.GetClusters(ctx)
+	client.DeleteSubsystem(ctx, "SYS001")
+	client.DeleteSubsystem(ctx, "SYS002")
+
+	// GET 不审计,失败的变更以 warning 级别写入
+	if len(audit.events) != 2 {
+		t.Fatalf("events = %+v, want 2 mutation events", audit.events)
+	}
+	ok, failed := audit.events[0], audit.events[1]
+	if !ok.OK || ok.Method != "DELETE" || ok.Endpoint != "/operation/subsystem/SYS001" || ok.User != "alice" || !ok.Time.Equal(now) {
+		t.Errorf("success event = %+v", ok)
+	}
+	if failed.OK || failed.Status != http.StatusForbidden || failed.Error == "" {
+		t.Errorf("failure event = %+v, want status 403 with an error", failed)
+	}
+	if audit.levels[0] != "info" || audit.levels[1] != "warning" {
+		t.Errorf("levels = %v, want [info warning]", audit.levels)
+	}
+}
+
+func TestLoadConfigAuditSyslog(t *testing.T) {
+	clearCredentialEnv(t)
+	path := writeConfig(t, "config.yaml", `
+dev:
+  base_url: "http://dev.example.com"
+  audit_syslog: "udp://127.0.0.1:514"
+prod:
+  base_url: "https://prod.example.com"
+  audit_syslog: "syslog.example.com"
+`)
+	var dev *Config
+	var err error
+	captureStdout(t, func() { dev, err = LoadConfigFromYAML(path, "dev") })
+	if err != nil || dev.AuditSyslog != "udp://127.0.0.1:514" {
+		t.Errorf("LoadConfigFromYAML(dev) = %+v, %v, want the audit target", dev, err)
+	}
+	captureStdout(t, func() { _, err = LoadConfigFromYAML(path, "prod") })
+	if err == nil {
+		t.Error("LoadConfigFromYAML(prod) error = nil, want an invalid audit_syslog error")
+	}
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ==================== 批量调整归属集群 ====================
+
+// ClusterMove 一条子系统归属集群调整
+type ClusterMove struct {
+	Row            int    `json:"row"` // CSV 行号 (含表头,从 1 开始)
+	SubsysID       string `json:"subsysId"`
+	TargetCluster  string `json:"targetCluster"`
+	Traffic        int64  `json:"traffic"`
+	LogImportValue string `json:"logImportValue,omitempty"`
+	LogImportFiles string `json:"logImportFiles,omitempty"`
+}
+
+// ClusterMoveResult 单条调整的执行结果
+type ClusterMoveResult struct {
+	ClusterMove
+	Status string `json:"status"` // ok / failed / skipped / dry-run
+	Error  string `json:"error,omitempty"`
+}
+
+// 批量调整 CSV 的必填列与可选列
+var (
+	clusterMoveRequired = []string{"subsys_id", "target_cluster", "traffic"}
+	clusterMoveOptional = []string{"log_import_value", "log_import_files"}
+)
+
+// readClusterMoves 解析批量调整 CSV,表头需包含 subsys_id,target_cluster,traffic,
+// 可选 log_import_value,log_import_files; 表头或任一行格式错误时整体返回错误
+func readClusterMoves(r io.Reader) ([]ClusterMove, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取 CSV 表头失败: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range clusterMoveRequired {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV 缺少必填列 %q (必填: %s, 可选: %s)", name,
+				strings.Join(clusterMoveRequired, ","), strings.Join(clusterMoveOptional, ","))
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var moves []ClusterMove
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取 CSV 第 %d 行失败: %w", row, err)
+		}
+
+		move := ClusterMove{
+			Row:            row,
+			SubsysID:       field(record, "subsys_id"),
+			TargetCluster:  field(record, "target_cluster"),
+			LogImportValue: field(record, "log_import_value"),
+			LogImportFiles: field(record, "log_import_files"),
+		}
+		if move.SubsysID == "" || move.TargetCluster == "" {
+			return nil, fmt.Errorf("CSV 第 %d 行缺少 subsys_id 或 target_cluster", row)
+		}
+		if move.Traffic, err = strconv.ParseInt(field(record, "traffic"), 10, 64); err != nil {
+			return nil, fmt.Errorf("CSV 第 %d 行 traffic 不是整数: %w", row, err)
+		}
+		moves = append(moves, move)
+	}
+
+	return moves, nil
+}
+
+// BulkAdjustClusters 以有限并发批量调整子系统归属集群,单条失败不影响其余条目
+// onSuccess 在每条成功后调用 (可为 nil),用于记录进度以便中断后续跑
+func (c *Client) BulkAdjustClusters(ctx context.Context, moves []ClusterMove, concurrency int, dryRun bool, onSuccess func(ClusterMove)) []ClusterMoveResult {
+	results := make([]ClusterMoveResult, len(moves))
+	forEachConcurrent(len(moves), concurrency, func(i int) {
+		move := moves[i]
+		results[i] = ClusterMoveResult{ClusterMove: move}
+		if dryRun {
+			results[i].Status = "dry-run"
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			results[i].Status, results[i].Error = "skipped", err.Error()
+			return
+		}
+
+		err := c.AdjustSubsystemCluster(ctx, move.SubsysID, &AdjustClusterRequest{
+			TargetClusterName: move.TargetCluster,
+			LogImportValue:    move.LogImportValue,
+			LogImportFiles:    move.LogImportFiles,
+			Traffic:           move.Traffic,
+		})
+		if err != nil {
+			results[i].Status, results[i].Error = "failed", err.Error()
+			return
+		}
+		results[i].Status = "ok"
+		if onSuccess != nil {
+			onSuccess(move)
+		}
+	})
+	return results
+}
+
+// progressFile 断点续跑进度文件,每行记录一个已成功调整的子系统ID
+type progressFile struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// loadProgress 读取已完成的子系统ID,文件不存在时返回空集合
+func loadProgress(path string) (map[string]bool, error) {
+	done := make(map[string]bool)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取进度文件失败: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			done[id] = true
+		}
+	}
+	return done, scanner.Err()
+}
+
+// openProgress 打开进度文件: resume 时在原有记录后追加,否则清空,
+// 避免上次运行的记录在之后的 --resume 中跳过本次并未执行的条目
+func openProgress(path string, resume bool) (*progressFile, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开进度文件失败: %w", err)
+	}
+	return &progressFile{f: f}, nil
+}
+
+func (p *progressFile) record(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintln(p.f, id)
+}
+
+func (p *progressFile) Close() error {
+	return p.f.Close()
+}
+
+func cmdBulkAdjustCluster(client *Client, args *CommandLineArgs) error {
+	if args.File == "" {
+		return fmt.Errorf("请使用 --file 指定 CSV 文件")
+	}
+
+	f, err := os.Open(args.File)
+	if err != nil {
+		return fmt.Errorf("打开 CSV 文件失败: %w", err)
+	}
+	moves, err := readClusterMoves(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	// 断点续跑: 跳过进度文件中已成功的子系统
+	progressPath := args.File + ".progress"
+	var skipped []ClusterMoveResult
+	if args.Resume {
+		done, err := loadProgress(progressPath)
+		if err != nil {
+			return err
+		}
+		pending := moves[:0]
+		for _, move := range moves {
+			if done[move.SubsysID] {
+				skipped = append(skipped, ClusterMoveResult{ClusterMove: move, Status: "skipped", Error: "已在上次运行中完成"})
+				continue
+			}
+			pending = append(pending, move)
+		}
+		moves = pending
+	}
+
+	var onSuccess func(ClusterMove)
+	if !args.DryRun {
+		progress, err := openProgress(progressPath, args.Resume)
+		if err != nil {
+			return err
+		}
+		defer progress.Close()
+		onSuccess = func(move ClusterMove) { progress.record(move.SubsysID) }
+	}
+
+	results := client.BulkAdjustClusters(args.Context(), moves, args.Concurrency, args.DryRun, onSuccess)
+	results = append(skipped, results...)
+	if err := printResult(args, results); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, r := range results {
+		if r.Status == "failed" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d 条调整失败,修正后可使用 --resume 跳过已完成的条目重新执行", failed, len(results))
+	}
+	return nil
+}
+
+// ==================== 批量删除节点 ====================
+
+// masterRole 集群主节点角色
+const masterRole = "master"
+
+// NodeDeleteResult 单个节点的删除结果
+type NodeDeleteResult struct {
+	Address string `json:"address"`
+	Role    string `json:"role"`
+	Status  string `json:"status"` // ok / failed / skipped / dry-run
+	Error   string `json:"error,omitempty"`
+}
+
+// planNodeDeletion 计算待删除节点,ips 为空表示删除集群全部节点
+// 不在集群中的 IP 以及会导致集群失去最后一个 master 的节点标记为 skipped
+func planNodeDeletion(nodes []LogStoreInstance, ips []string) (targets []LogStoreInstance, skipped []NodeDeleteResult) {
+	byAddress := make(map[string]LogStoreInstance, len(nodes))
+	for _, node := range nodes {
+		byAddress[node.Address] = node
+	}
+
+	selected := make(map[string]bool)
+	if len(ips) == 0 {
+		targets = append(targets, nodes...)
+	} else {
+		for _, ip := range ips {
+			node, ok := byAddress[ip]
+			if !ok {
+				skipped = append(skipped, NodeDeleteResult{Address: ip, Status: "skipped", Error: "节点不在集群中"})
+				continue
+			}
+			if !selected[ip] {
+				targets = append(targets, node)
+			}
+			selected[ip] = true
+		}
+	}
+	for _, node := range targets {
+		selected[node.Address] = true
+	}
+
+	// 至少保留一个 master: 删除后没有剩余 master 时,保留待删除列表中的最后一个
+	remainingMasters := 0
+	for _, node := range nodes {
+		if node.Role == masterRole && !selected[node.Address] {
+			remainingMasters++
+		}
+	}
+	if remainingMasters == 0 {
+		for i := len(targets) - 1; i >= 0; i-- {
+			if targets[i].Role == masterRole {
+				skipped = append(skipped, NodeDeleteResult{
+					Address: targets[i].Address, Role: masterRole, Status: "skipped",
+					Error: "集群最后一个 master 节点,请确认后使用 delete-node 单独删除",
+				})
+				targets = append(targets[:i], targets[i+1:]...)
+				break
+			}
+		}
+	}
+	return targets, skipped
+}
+
+// DeleteClusterNodes 以有限并发从集群删除节点,单个失败不影响其余节点
+func (c *Client) DeleteClusterNodes(ctx context.Context, clusterName string, nodes []LogStoreInstance, concurrency int, dryRun bool) []NodeDeleteResult {
+	results := make([]NodeDeleteResult, len(nodes))
+	forEachConcurrent(len(nodes), concurrency, func(i int) {
+		node := nodes[i]
+		results[i] = NodeDeleteResult{Address: node.Address, Role: node.Role}
+		if dryRun {
+			results[i].Status = "dry-run"
+			return
+		}
+		if err := c.deleteClusterNodeFrom(ctx, clusterName, node.Address); err != nil {
+			results[i].Status, results[i].Error = "failed", err.Error()
+			return
+		}
+		results[i].Status = "ok"
+	})
+	return results
+}
+
+// readIPList 读取 IP 列表文件,每行一个 IP,忽略空行和 # 注释
+func readIPList(path string) ([]string, error) {
+	return readListFile(path, "IP 列表")
+}
+
+// readListFile 读取每行一项的列表文件,忽略空行和 # 注释,kind 用于错误信息
+func readListFile(path, kind string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开%s文件失败: %w", kind, err)
+	}
+	defer f.Close()
+
+	var items []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items = append(items, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取%s文件失败: %w", kind, err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s文件为空: %s", kind, path)
+	}
+	return items, nil
+}
+
+func cmdDeleteNodes(client *Client, args *CommandLineArgs) error {
+	if args.ClusterName == "" {
+		return fmt.Errorf("请使用 --cluster 指定集群")
+	}
+	if args.All == (args.File != "") {
+		return fmt.Errorf("请使用 --all 或 --file 之一指定要删除的节点")
+	}
+	if !args.Confirm && !args.DryRun {
+		return fmt.Errorf("批量删除节点不可恢复,请加 --confirm 确认执行 (或先用 --dry-run 预览)")
+	}
+
+	var ips []string
+	if args.File != "" {
+		var err error
+		if ips, err = readIPList(args.File); err != nil {
+			return err
+		}
+	}
+
+	ctx := args.Context()
+	nodes, err := client.GetClusterNodes(ctx, args.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	targets, skipped := planNodeDeletion(nodes, ips)
+	results := append(client.DeleteClusterNodes(ctx, args.ClusterName, targets, args.Concurrency, args.DryRun), skipped...)
+	if err := printResult(args, results); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, r := range results {
+		if r.Status == "failed" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d 个节点删除失败", failed, len(results))
+	}
+	return nil
+}
+
+// ==================== 批量添加节点 ====================
+
+// NodeResult 单个节点的添加结果
+type NodeResult struct {
+	Address string `json:"address"`
+	Role    string `json:"role"`
+	Status  string `json:"status"` // ok / exists / failed
+	Error   string `json:"error,omitempty"`
+}
+
+// SetBatchConcurrency 设置 AddClusterNodes、GetSubsystemDetails 等批量操作的并发数,n <= 0 时使用 defaultConcurrency
+func (c *Client) SetBatchConcurrency(n int) {
+	c.batchConcurrency = n
+}
+
+// AddClusterNodes 以有限并发向集群添加节点,每个节点的结果按输入顺序记录在 NodeResult 中,
+// 单个失败不影响其余节点; 节点已存在记为 exists,不算失败,便于重复执行
+// 有节点添加失败时返回结果及汇总错误; 集群名称不符合格式时不发送任何请求,直接返回 ErrInvalidClusterName
+func (c *Client) AddClusterNodes(ctx context.Context, clusterName string, nodes []*AddClusterNodeRequest) ([]NodeResult, error) {
+	if err := c.validateClusterName(clusterName); err != nil {
+		return nil, err
+	}
+
+	results := make([]NodeResult, len(nodes))
+	forEachConcurrent(len(nodes), c.batchConcurrency, func(i int) {
+		node := nodes[i]
+		if node == nil {
+			results[i] = NodeResult{Status: "failed", Error: "节点参数为空"}
+			return
+		}
+		results[i] = NodeResult{Address: node.Address, Role: node.Role}
+		switch err := c.AddClusterNode(ctx, clusterName, node); {
+		case errors.Is(err, ErrNodeExists):
+			results[i].Status = "exists"
+		case err != nil:
+			results[i].Status, results[i].Error = "failed", err.Error()
+		default:
+			results[i].Status = "ok"
+		}
+	})
+
+	var failed int
+	var first string
+	for _, r := range results {
+		if r.Status == "failed" {
+			if failed == 0 {
+				first = fmt.Sprintf("%s: %s", r.Address, r.Error)
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d/%d 个节点添加失败,首个错误: %s", failed, len(nodes), first)
+	}
+	return results, nil
+}
+
+// readNodeRequests 读取 JSON 数组形式的节点列表,每个元素同 AddClusterNodeRequest,
+// 集群名称以 --cluster 为准,元素中的 clustername 会被忽略
+func readNodeRequests(path string) ([]*AddClusterNodeRequest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取节点文件失败: %w", err)
+	}
+	var nodes []*AddClusterNodeRequest
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("解析节点文件失败 (应为 JSON 数组): %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("节点文件为空: %s", path)
+	}
+	for i, node := range nodes {
+		if node == nil || node.Address == "" || node.Role == "" {
+			return nil, fmt.Errorf("节点文件第 %d 个节点缺少 address 或 role", i+1)
+		}
+	}
+	return nodes, nil
+}
+
+func cmdAddNodes(client *Client, args *CommandLineArgs) error {
+	if args.ClusterName == "" {
+		return fmt.Errorf("请使用 --cluster 指定集群")
+	}
+	if args.FromFile == "" {
+		return fmt.Errorf("请使用 --from-file 指定节点文件 (JSON 数组)")
+	}
+
+	nodes, err := readNodeRequests(args.FromFile)
+	if err != nil {
+		return err
+	}
+
+	client.SetBatchConcurrency(args.Concurrency)
+	results, err := client.AddClusterNodes(args.Context(), args.ClusterName, nodes)
+	if results == nil {
+		return err
+	}
+	if printErr := printResult(args, results); printErr != nil {
+		return printErr
+	}
+	return err
+}
+
+// ==================== 批量查询子系统详情 ====================
+
+func cmdDetails(client *Client, args *CommandLineArgs) error {
+	if args.File == "" {
+		return fmt.Errorf("请使用 --file 指定子系统 ID 列表文件 (每行一个)")
+	}
+	ids, err := readListFile(args.File, "子系统 ID 列表")
+	if err != nil {
+		return err
+	}
+
+	client.SetBatchConcurrency(args.Concurrency)
+	details, err := client.GetSubsystemDetails(args.Context(), ids)
+
+	// 按文件中的顺序输出获取成功的详情
+	ordered := make([]*SubsystemDetailResult, 0, len(details))
+	for _, id := range dedupIDs(ids) {
+		if detail, ok := details[id]; ok {
+			ordered = append(ordered, detail)
+		}
+	}
+	if printErr := printResult(args, ordered); printErr != nil {
+		return printErr
+	}
+	return err
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// ==================== 批量调整归属集群 ====================
+
+func TestReadClusterMoves(t *testing.T) {
+	input := "Subsys_ID, target_cluster, traffic, log_import_value\n" +
+		"SYS001, LOG002, 2048, v1\n" +
+		"SYS002, LOG003, 0,\n"
+
+	moves, err := readClusterMoves(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ClusterMove{
+		{Row: 2, SubsysID: "SYS001", TargetCluster: "LOG002", Traffic: 2048, LogImportValue: "v1"},
+		{Row: 3, SubsysID: "SYS002", TargetCluster: "LOG003"},
+	}
+	if !reflect.DeepEqual(moves, want) {
+		t.Errorf("moves = %+v, want %+v", moves, want)
+	}
+}
+
+func TestReadClusterMovesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"empty", "", "读取 CSV 表头失败"},
+		{"missing column", "subsys_id,target_cluster\nSYS001,LOG002\n", `缺少必填列 "traffic"`},
+		{"missing id", "subsys_id,target_cluster,traffic\n,LOG002,1\n", "第 2 行缺少 subsys_id"},
+		{"bad traffic", "subsys_id,target_cluster,traffic\nSYS001,LOG002,1\nSYS002,LOG002,lots\n", "第 3 行 traffic 不是整数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readClusterMoves(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBulkAdjustClusters(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("POST /operation/subsystem/SYS001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+	api.handle("POST /operation/subsystem/SYS002", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusBadRequest, 400, "目标集群容量不足")
+	})
+	client := newTestClient(t, api)
+	moves := []ClusterMove{
+		{Row: 2, SubsysID: "SYS001", TargetCluster: "LOG002"},
+		{Row: 3, SubsysID: "SYS002", TargetCluster: "LOG002"},
+	}
+
+	var mu sync.Mutex
+	var succeeded []string
+	results := client.BulkAdjustClusters(context.Background(), moves, 2, false, func(move ClusterMove) {
+		mu.Lock()
+		defer mu.Unlock()
+		succeeded = append(succeeded, move.SubsysID)
+	})
+
+	// 单条失败不影响其余条目,结果顺序与输入一致
+	if results[0].Status != "ok" || results[1].Status != "failed" {
+		t.Errorf("statuses = %s/%s, want ok/failed", results[0].Status, results[1].Status)
+	}
+	if !strings.Contains(results[1].Error, "容量不足") {
+		t.Errorf("error = %q, want the server message", results[1].Error)
+	}
+	if !reflect.DeepEqual(succeeded, []string{"SYS001"}) {
+		t.Errorf("onSuccess called for %v, want [SYS001]", succeeded)
+	}
+}
+
+func TestBulkAdjustClustersDryRun(t *testing.T) {
+	api := newFakeAPI()
+	client := newTestClient(t, api)
+
+	results := client.BulkAdjustClusters(context.Background(), []ClusterMove{{SubsysID: "SYS001", TargetCluster: "LOG002"}}, 1, true, nil)
+	if results[0].Status != "dry-run" {
+		t.Errorf("status = %s, want dry-run", results[0].Status)
+	}
+	if len(api.requests()) != 0 {
+		t.Errorf("requests = %v, want none in dry-run", api.requests())
+	}
+}
+
+func TestProgressFileResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "moves.csv.progress")
+
+	done, err := loadProgress(path)
+	if err != nil || len(done) != 0 {
+		t.Fatalf("loadProgress(missing) = %v, %v, want empty set", done, err)
+	}
+
+	run := func(resume bool, ids ...string) map[string]bool {
+		t.Helper()
+		progress, err := openProgress(path, resume)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range ids {
+			progress.record(id)
+		}
+		if err := progress.Close(); err != nil {
+			t.Fatal(err)
+		}
+		done, err := loadProgress(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return done
+	}
+
+	// --resume 的运行追加写入同一进度文件
+	run(false, "SYS001")
+	done = run(true, "SYS002", "SYS003")
+	want := map[string]bool{"SYS001": true, "SYS002": true, "SYS003": true}
+	if !reflect.DeepEqual(done, want) {
+		t.Errorf("done = %v, want %v", done, want)
+	}
+
+	// 不带 --resume 的运行清空上次的记录
+	done = run(false, "SYS004")
+	if want := map[string]bool{"SYS004": true}; !reflect.DeepEqual(done, want) {
+		t.Errorf("done after a fresh run = %v, want %v", done, want)
+	}
+}
+
+// ==================== 批量删除节点 ====================
+
+func TestPlanNodeDeletion(t *testing.T) {
+	nodes := []LogStoreInstance{
+		{Address: "10.0.0.1", Role: "master"},
+		{Address: "10.0.0.2", Role: "master"},
+		{Address: "10.0.0.3", Role: "write"},
+	}
+	tests := []struct {
+		name        string
+		ips         []string
+		wantTargets []string
+		wantSkipped []string
+	}{
+		{"all keeps last master", nil, []string{"10.0.0.1", "10.0.0.3"}, []string{"10.0.0.2"}},
+		{"one master remains", []string{"10.0.0.1", "10.0.0.3"}, []string{"10.0.0.1", "10.0.0.3"}, nil},
+		{"both masters", []string{"10.0.0.2", "10.0.0.1"}, []string{"10.0.0.2"}, []string{"10.0.0.1"}},
+		{"unknown and duplicate ips", []string{"10.0.0.3", "10.0.0.9", "10.0.0.3"}, []string{"10.0.0.3"}, []string{"10.0.0.9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, skipped := planNodeDeletion(nodes, tt.ips)
+			var gotTargets, gotSkipped []string
+			for _, node := range targets {
+				gotTargets = append(gotTargets, node.Address)
+			}
+			for _, r := range skipped {
+				if r.Status != "skipped" {
+					t.Errorf("skipped %s has status %q", r.Address, r.Status)
+				}
+				gotSkipped = append(gotSkipped, r.Address)
+			}
+			if !reflect.DeepEqual(gotTargets, tt.wantTargets) {
+				t.Errorf("targets = %v, want %v", gotTargets, tt.wantTargets)
+			}
+			if !reflect.DeepEqual(gotSkipped, tt.wantSkipped) {
+				t.Errorf("skipped = %v, want %v", gotSkipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestDeleteClusterNodes(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("DELETE /operation/clusters/nodes/10.0.0.1", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+	api.handle("DELETE /operation/clusters/nodes/10.0.0.2", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusConflict, 409, "节点正在迁移")
+	})
+	client := newTestClient(t, api)
+	nodes := []LogStoreInstance{{Address: "10.0.0.1", Role: "write"}, {Address: "10.0.0.2", Role: "read"}}
+
+	results := client.DeleteClusterNodes(context.Background(), "LOG001", nodes, 2, false)
+	if results[0].Status != "ok" || results[1].Status != "failed" {
+		t.Errorf("statuses = %s/%s, want ok/failed", results[0].Status, results[1].Status)
+	}
+	// 服务端未声明按集群删除时不附加 clustername 参数
+	if got := api.count("DELETE /operation/clusters/nodes/10.0.0.1"); got != 1 || api.count("DELETE /operation/clusters/nodes/10.0.0.1?") != 0 {
+		t.Errorf("requests = %v, want one unscoped delete of 10.0.0.1", api.requests())
+	}
+
+	sent := len(api.requests())
+	results = client.DeleteClusterNodes(context.Background(), "LOG001", nodes, 2, true)
+	if results[0].Status != "dry-run" || results[1].Status != "dry-run" {
+		t.Errorf("dry-run statuses = %s/%s, want dry-run", results[0].Status, results[1].Status)
+	}
+	if n := len(api.requests()); n != sent {
+		t.Errorf("requests after dry-run = %d, want %d", n, sent)
+	}
+}
+
+func TestDeleteClusterNodesScoped(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, Capabilities{ScopedNodeDelete: true})
+	})
+	api.handle("DELETE /operation/clusters/nodes/10.0.0.1", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+	client := newTestClient(t, api)
+
+	results := client.DeleteClusterNodes(context.Background(), "LOG001", []LogStoreInstance{{Address: "10.0.0.1"}}, 1, false)
+	if results[0].Status != "ok" {
+		t.Errorf("status = %s (%s), want ok", results[0].Status, results[0].Error)
+	}
+	if got := api.count("DELETE /operation/clusters/nodes/10.0.0.1?clustername=LOG001"); got != 1 {
+		t.Errorf("requests = %v, want the delete scoped to LOG001", api.requests())
+	}
+}
+
+func TestDeleteNodeNotFoundAfterRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		failing int // 返回 404 之前返回 503 的次数
+		wantErr bool
+	}{
+		// 之前的尝试可能已删除成功,重试后的 404 视为成功
+		{"404 after retry", 1, false},
+		{"404 on first attempt", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			api := newFakeAPI()
+			api.handle("DELETE /operation/clusters/nodes/10.0.0.1", func(w http.ResponseWriter, r *http.Request) {
+				if int(calls.Add(1)) <= tt.failing {
+					respondError(w, http.StatusServiceUnavailable, 503, "busy")
+					return
+				}
+				respondError(w, http.StatusNotFound, 404, "节点不存在")
+			})
+			err := newTestClient(t, api).DeleteClusterNode(context.Background(), "10.0.0.1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadIPList(t *testing.T) {
+	path := writeConfig(t, "ips.txt", "# 待下线节点\n10.0.0.1\n\n  10.0.0.2  \n")
+	ips, err := readIPList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.1", "10.0.0.2"}; !reflect.DeepEqual(ips, want) {
+		t.Errorf("ips = %v, want %v", ips, want)
+	}
+
+	if _, err := readIPList(writeConfig(t, "empty.txt", "# 仅注释\n")); err == nil {
+		t.Error("expected an error for a list with no IPs")
+	}
+}
+
+// ==================== 批量添加节点 ====================
+
+func TestAddClusterNodes(t *testing.T) {
+	var inflight, peak atomic.Int32
+	api := newFakeAPI()
+	api.handle("POST /operation/clusters/LOG001/nodes", func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		var req AddClusterNodeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Address {
+		case "10.0.0.2":
+			respondError(w, http.StatusConflict, 409, "conflict")
+		case "10.0.0.3":
+			respondError(w, http.StatusBadRequest, 400, "bad role")
+		default:
+			respondResult(w, nil)
+		}
+	})
+	client := newTestClient(t, api)
+	client.SetBatchConcurrency(2)
+
+	nodes := []*AddClusterNodeRequest{
+		{Address: "10.0.0.1", Role: "write"},
+		{Address: "10.0.0.2", Role: "write"},
+		{Address: "10.0.0.3", Role: "oops"},
+		{Address: "10.0.0.4", Role: "master"},
+	}
+	results, err := client.AddClusterNodes(context.Background(), "LOG001", nodes)
+	if err == nil || !strings.Contains(err.Error(), "1/4") || !strings.Contains(err.Error(), "10.0.0.3") {
+		t.Errorf("error = %v, want an aggregated error naming 10.0.0.3", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Address+"/"+r.Status)
+	}
+	want := []string{"10.0.0.1/ok", "10.0.0.2/exists", "10.0.0.3/failed", "10.0.0.4/ok"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if results[2].Error == "" {
+		t.Error("failed result has no error message")
+	}
+	if n := api.count("POST /operation/clusters/LOG001/nodes"); n != 4 {
+		t.Errorf("requests = %d, want 4", n)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", p)
+	}
+}
+
+func TestAddClusterNodesAllExist(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("POST /operation/clusters/LOG001/nodes", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusOK, codeNodeExists, "node exists")
+	})
+	// 重复执行同一节点文件不算失败
+	results, err := newTestClient(t, api).AddClusterNodes(context.Background(), "LOG001", []*AddClusterNodeRequest{{Address: "10.0.0.1", Role: "write"}})
+	if err != nil || len(results) != 1 || results[0].Status != "exists" {
+		t.Errorf("AddClusterNodes() = %+v, %v, want exists and no error", results, err)
+	}
+}
+
+func TestReadNodeRequests(t *testing.T) {
+	path := writeConfig(t, "nodes.json", `[{"address":"10.0.0.1","role":"write","cpulimit":"8"},{"address":"10.0.0.2","role":"master"}]`)
+	nodes, err := readNodeRequests(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || nodes[0].Address != "10.0.0.1" || nodes[0].CpuLimit != "8" || nodes[1].Role != "master" {
+		t.Errorf("nodes = %+v, want both nodes parsed", nodes)
+	}
+
+	tests := []struct {
+		name, content string
+	}{
+		{"not an array", `{"address":"10.0.0.1"}`},
+		{"empty", `[]`},
+		{"missing role", `[{"address":"10.0.0.1"}]`},
+		{"null element", `[null]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readNodeRequests(writeConfig(t, "nodes.json", tt.content)); err == nil {
+				t.Error("readNodeRequests() error = nil, want an error")
+			}
+		})
+	}
+}
+
+// ==================== 批量查询子系统详情 ====================
+
+func TestDedupIDs(t *testing.T) {
+	got := dedupIDs([]string{"SYS002", " SYS001 ", "", "SYS002", "  ", "SYS001", "SYS003"})
+	if want := []string{"SYS002", "SYS001", "SYS003"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupIDs() = %v, want %v", got, want)
+	}
+}
+
+// subsystemDetailHandler 返回 SYS001/SYS003 的详情,其余子系统返回 404
+func subsystemDetailHandler(api *fakeAPI) {
+	for _, id := range []string{"SYS001", "SYS003"} {
+		api.handle("GET /operation/subsystem/"+id, resultHandler(`{"subsystemInfo":{"subsys_id":"`+id+`","subsys_name":"app-`+id+`"},"clusterName":"LOG001","collected":true,"expectedTraffic":100,"actualTraffic":80}`))
+	}
+}
+
+func TestGetSubsystemDetails(t *testing.T) {
+	api := newFakeAPI()
+	subsystemDetailHandler(api)
+	client := newTestClient(t, api)
+	client.SetBatchConcurrency(2)
+
+	details, err := client.GetSubsystemDetails(context.Background(), []string{"SYS001", " SYS001", "", "SYS002", "SYS003"})
+	if err == nil || !strings.Contains(err.Error(), "1/3") || !strings.Contains(err.Error(), "SYS002") {
+		t.Errorf("error = %v, want an aggregated error naming SYS002", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusNotFound {
+		t.Errorf("error = %v, want the 404 APIError wrapped", err)
+	}
+	if len(details) != 2 || details["SYS001"] == nil || details["SYS003"].ClusterName != "LOG001" {
+		t.Errorf("details = %v, want SYS001 and SYS003", details)
+	}
+	// 重复 ID 只请求一次
+	if n := api.count("GET /operation/subsystem/SYS001"); n != 1 {
+		t.Errorf("SYS001 requests = %d, want 1", n)
+	}
+}
+
+func TestCmdDetails(t *testing.T) {
+	api := newFakeAPI()
+	subsystemDetailHandler(api)
+	client := newTestClient(t, api)
+	path := writeConfig(t, "ids.txt", "# 待查询\nSYS003\nSYS002\nSYS001\nSYS003\n")
+
+	var err error
+	output := captureStdout(t, func() {
+		err = cmdDetails(client, &CommandLineArgs{File: path, Output: "csv"})
+	})
+	if err == nil {
+		t.Error("error = nil, want the SYS002 failure reported")
+	}
+	// 按文件顺序输出获取成功的详情
+	want := "ID,NAME,CLUSTER,COLLECTED,EXPECTED_TRAFFIC,ACTUAL_TRAFFIC\n" +
+		"SYS003,app-SYS003,LOG001,true,100,80\n" +
+		"SYS001,app-SYS001,LOG001,true,100,80\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+
+	if err := cmdDetails(client, &CommandLineArgs{}); err == nil {
+		t.Error("missing --file: error = nil, want an error")
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ==================== 响应缓存 ====================
+
+// CacheStats 响应缓存命中统计
+type CacheStats struct {
+	Hits   int64 // weapm_cache_hits_total
+	Misses int64 // weapm_cache_misses_total
+}
+
+// HitRatio 缓存命中率,无查询时返回 0
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type cacheEntry struct {
+	resp    APIResponse
+	expires time.Time
+}
+
+// responseCache GET 请求的内存 TTL 缓存
+type responseCache struct {
+	ttl     time.Duration
+	clock   Clock
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+func newResponseCache(ttl time.Duration, clock Clock) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		clock:   clock,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// get 查找未过期的缓存响应,并记录命中/未命中
+func (c *responseCache) get(key string) (*APIResponse, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && c.clock.Now().After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	resp := entry.resp
+	return &resp, true
+}
+
+func (c *responseCache) put(key string, resp *APIResponse) {
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{resp: *resp, expires: c.clock.Now().Add(c.ttl)}
+	c.mu.Unlock()
+}
+
+func (c *responseCache) stats() CacheStats {
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// CacheStats 返回响应缓存的命中统计,未启用缓存时返回零值
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.stats()
+}
+
+// ==================== 配置缓存 ====================
+
+// configCacheEnabled 是否缓存配置文件的解析结果,由 CLI 根据 WEAPM_CONFIG_CACHE 开启
+var configCacheEnabled bool
+
+// EnvConfigCache 设置为 1 时开启配置缓存
+const EnvConfigCache = "WEAPM_CONFIG_CACHE"
+
+// configCacheVersion 缓存格式版本,ConfigFile 结构变化时递增使旧缓存失效
+const configCacheVersion = 2
+
+// configSource 参与缓存校验的源文件
+type configSource struct {
+	Path    string `json:"path"`
+	ModTime int64  `json:"modTime"`
+	Size    int64  `json:"size"`
+}
+
+// configCacheEntry 缓存文件内容: 源文件状态及合并后的配置
+type configCacheEntry struct {
+	Version int            `json:"version"`
+	Sources []configSource `json:"sources"`
+	File    ConfigFile     `json:"file"`
+}
+
+// statConfigSources 获取基础配置文件及覆盖文件的绝对路径、修改时间和大小
+func statConfigSources(configPath string, overlayPaths []string) ([]configSource, error) {
+	paths := append([]string{configPath}, overlayPaths...)
+	sources := make([]configSource, 0, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, configSource{Path: abs, ModTime: info.ModTime().UnixNano(), Size: info.Size()})
+	}
+	return sources, nil
+}
+
+// configCacheDir 缓存目录: 用户缓存目录 (如 ~/.cache) 下的 weapm/config
+func configCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "weapm", "config"), nil
+}
+
+// configCachePath 缓存文件路径,文件名取自源文件路径的哈希
+func configCachePath(dir string, sources []configSource) string {
+	h := sha256.New()
+	for _, src := range sources {
+		h.Write([]byte(src.Path + "\n"))
+	}
+	return filepath.Join(dir, hex.EncodeToString(h.Sum(nil))[:16]+".json")
+}
+
+// checkCacheDir 确认缓存目录是当前用户所有、仅所有者可访问的真实目录 (不跟随符号链接)
+func checkCacheDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s 不是目录", dir)
+	}
+	return checkPrivate(info)
+}
+
+// readConfigCache 读取缓存文件,缓存目录或文件不满足 checkPrivate 时拒绝使用
+func readConfigCache(cachePath string) (*configCacheEntry, error) {
+	if err := checkCacheDir(filepath.Dir(cachePath)); err != nil {
+		return nil, err
+	}
+	info, err := os.Lstat(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s 不是普通文件", cachePath)
+	}
+	if err := checkPrivate(info); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	var entry configCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// hasPlaintextSecrets 配置中是否有直接写明的密码、Token、API Key 或签名密钥;
+// 只由 ${VAR} 引用组成的值在加载时才展开,不算明文
+func hasPlaintextSecrets(configFile *ConfigFile) bool {
+	for _, envConfig := range configFile.Environments {
+		for _, secret := range []string{envConfig.Password, envConfig.Token, envConfig.APIKey, envConfig.SigningSecret} {
+			if strings.TrimSpace(envRefPattern.ReplaceAllString(secret, "")) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadConfigFileCached 与 loadMergedConfigFile 相同,但源文件 (含覆盖文件) 的路径、修改时间和大小
+// 均未变化时直接使用缓存; 缓存读写失败不影响加载,只回退到解析 YAML
+// 缓存只保存合并后的文件内容,${VAR} 展开、环境变量覆盖和默认值仍在每次加载时处理;
+// 含明文密钥的配置不写入缓存
+func loadConfigFileCached(configPath string, overlayPaths []string) (*ConfigFile, error) {
+	sources, err := statConfigSources(configPath, overlayPaths)
+	if err != nil {
+		// 文件不存在等错误交给 loadMergedConfigFile 报告
+		return loadMergedConfigFile(configPath, overlayPaths)
+	}
+	dir, err := configCacheDir()
+	if err != nil {
+		return loadMergedConfigFile(configPath, overlayPaths)
+	}
+	cachePath := configCachePath(dir, sources)
+
+	if entry, err := readConfigCache(cachePath); err == nil {
+		if entry.Version == configCacheVersion && reflect.DeepEqual(entry.Sources, sources) {
+			return &entry.File, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		logger.Printf("⚠️  忽略配置缓存: %v", err)
+	}
+
+	configFile, err := loadMergedConfigFile(configPath, overlayPaths)
+	if err != nil {
+		return nil, err
+	}
+	if hasPlaintextSecrets(configFile) {
+		return configFile, nil
+	}
+	if err := writeConfigCache(cachePath, configCacheEntry{Version: configCacheVersion, Sources: sources, File: *configFile}); err != nil {
+		logger.Printf("⚠️  写入配置缓存失败: %v", err)
+	}
+	return configFile, nil
+}
+
+// writeConfigCache 写入缓存文件 (先写临时文件再重命名),缓存目录须为当前用户所有且仅所有者可访问
+func writeConfigCache(cachePath string, entry configCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(cachePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := checkCacheDir(dir); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cachePath)
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package main
+
+import "os"
+
+// checkPrivate 当前平台没有 Unix 所有者及权限位,依赖 os.UserCacheDir 本身按用户隔离
+func checkPrivate(info os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// ==================== 响应缓存 ====================
+
+func TestResponseCacheHitsAndExpiry(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{{ClusterName: "LOG001"}})
+	})
+	client := newTestClient(t, api, func(c *Config) { c.CacheTTL = time.Minute })
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	client.SetClock(clock)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		clusters, err := client.GetClusters(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(clusters) != 1 || clusters[0].ClusterName != "LOG001" {
+			t.Fatalf("clusters = %+v", clusters)
+		}
+	}
+	if n := api.count("GET /operation/clusters"); n != 1 {
+		t.Errorf("server requests = %d, want 1 while the entry is fresh", n)
+	}
+	if stats := client.CacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("stats = %+v, want 1 hit and 1 miss", stats)
+	}
+
+	clock.Advance(time.Minute + time.Second)
+	if _, err := client.GetClusters(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := api.count("GET /operation/clusters"); n != 2 {
+		t.Errorf("server requests = %d, want 2 after the entry expired", n)
+	}
+	if stats := client.CacheStats(); stats.Misses != 2 {
+		t.Errorf("misses = %d, want 2", stats.Misses)
+	}
+}
+
+func TestResponseCacheSkipsMutations(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("POST /operation/subsystem", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+	client := newTestClient(t, api, func(c *Config) { c.CacheTTL = time.Minute })
+
+	for i := 0; i < 2; i++ {
+		if err := client.AddSubsystem(context.Background(), &AddSubsystemRequest{SubSystemID: "SYS001"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := api.count("POST /operation/subsystem"); n != 2 {
+		t.Errorf("POST requests = %d, want 2", n)
+	}
+	if stats := client.CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("stats = %+v, want no lookups for POST", stats)
+	}
+}
+
+func TestCacheStatsDisabled(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler())
+	if stats := client.CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("stats = %+v, want zero value without a cache", stats)
+	}
+}
+
+func TestCacheStatsHitRatio(t *testing.T) {
+	tests := []struct {
+		stats CacheStats
+		want  float64
+	}{
+		{CacheStats{}, 0},
+		{CacheStats{Hits: 3, Misses: 1}, 0.75},
+		{CacheStats{Misses: 2}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.stats.HitRatio(); got != tt.want {
+			t.Errorf("%+v.HitRatio() = %v, want %v", tt.stats, got, tt.want)
+		}
+	}
+}
+
+// ==================== 配置缓存 ====================
+
+// cachedConfigPath 返回 path 对应的配置缓存文件路径,缓存目录指向临时目录
+func cachedConfigPath(t *testing.T, path string) string {
+	t.Helper()
+	sources, err := statConfigSources(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := configCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return configCachePath(dir, sources)
+}
+
+func TestLoadConfigFileCached(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path := writeConfig(t, "config.yaml", "dev:\n  base_url: \"http://dev.example.com\"\n  password: \"${WEAPM_PASSWORD}\"\n")
+
+	configFile, err := loadConfigFileCached(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := configFile.Environments["dev"].BaseURL; got != "http://dev.example.com" {
+		t.Fatalf("BaseURL = %q, want the file value", got)
+	}
+
+	// 修改缓存内容,源文件未变化时应读到缓存中的值
+	cachePath := cachedConfigPath(t, path)
+	entry, err := readConfigCache(cachePath)
+	if err != nil {
+		t.Fatalf("readConfigCache: %v", err)
+	}
+	dev := entry.File.Environments["dev"]
+	dev.BaseURL = "http://cached.example.com"
+	entry.File.Environments["dev"] = dev
+	if err := writeConfigCache(cachePath, *entry); err != nil {
+		t.Fatal(err)
+	}
+	configFile, err = loadConfigFileCached(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := configFile.Environments["dev"].BaseURL; got != "http://cached.example.com" {
+		t.Errorf("BaseURL = %q, want the cached value", got)
+	}
+
+	// 源文件变化后缓存失效
+	if err := os.WriteFile(path, []byte("dev:\n  base_url: \"http://changed.example.com\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	configFile, err = loadConfigFileCached(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := configFile.Environments["dev"].BaseURL; got != "http://changed.example.com" {
+		t.Errorf("BaseURL = %q, want the changed file value", got)
+	}
+}
+
+func TestLoadConfigFileCachedSkipsPlaintextSecrets(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path := writeConfig(t, "config.yaml", "dev:\n  base_url: \"http://dev.example.com\"\n  password: \"plain-password\"\n")
+
+	if _, err := loadConfigFileCached(path, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cachedConfigPath(t, path)); !os.IsNotExist(err) {
+		t.Errorf("cache file stat error = %v, want it not to exist for a plaintext password", err)
+	}
+}
+
+func TestHasPlaintextSecrets(t *testing.T) {
+	tests := []struct {
+		env  EnvConfig
+		want bool
+	}{
+		{EnvConfig{Username: "alice"}, false},
+		{EnvConfig{Password: "${WEAPM_PASSWORD}", Token: " ${WEAPM_TOKEN} "}, false},
+		{EnvConfig{Password: "prefix-${WEAPM_PASSWORD}"}, true},
+		{EnvConfig{SigningSecret: "s3cret"}, true},
+	}
+	for _, tt := range tests {
+		configFile := &ConfigFile{Environments: map[string]EnvConfig{"dev": tt.env}}
+		if got := hasPlaintextSecrets(configFile); got != tt.want {
+			t.Errorf("hasPlaintextSecrets(%+v) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
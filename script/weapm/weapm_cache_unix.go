@@ -0,0 +1,25 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivate 要求 info 属于当前用户且组和其他用户没有任何权限,
+// 避免读取其他用户预先创建或篡改的配置缓存
+func checkPrivate(info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("无法获取 %s 的所有者", info.Name())
+	}
+	if int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s 不属于当前用户 (uid %d)", info.Name(), stat.Uid)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("%s 权限过宽: %#o", info.Name(), perm)
+	}
+	return nil
+}
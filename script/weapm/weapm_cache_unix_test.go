@@ -0,0 +1,43 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// 其他用户可读写的缓存文件可能已被篡改,应忽略并重新解析配置文件
+func TestConfigCacheIgnoredWhenNotPrivate(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path := writeConfig(t, "config.yaml", "dev:\n  base_url: \"http://dev.example.com\"\n")
+	if _, err := loadConfigFileCached(path, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	cachePath := cachedConfigPath(t, path)
+	entry, err := readConfigCache(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := entry.File.Environments["dev"]
+	dev.BaseURL = "http://attacker.example.com"
+	entry.File.Environments["dev"] = dev
+	if err := writeConfigCache(cachePath, *entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(cachePath, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readConfigCache(cachePath); err == nil {
+		t.Error("readConfigCache accepted a world-writable cache file")
+	}
+	configFile, err := loadConfigFileCached(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := configFile.Environments["dev"].BaseURL; got != "http://dev.example.com" {
+		t.Errorf("BaseURL = %q, want the file value", got)
+	}
+}
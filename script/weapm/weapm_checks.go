@@ -0,0 +1,529 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ==================== 一致性检查 ====================
+
+// IntegrityIssue 一条一致性问题
+type IntegrityIssue struct {
+	Category    string `json:"category"`
+	ClusterName string `json:"clusterName,omitempty"`
+	SubsystemID string `json:"subsystemId,omitempty"`
+	Detail      string `json:"detail"`
+}
+
+// 一致性问题分类
+const (
+	IssueMissingCluster   = "missing_cluster"   // 子系统归属的集群不存在
+	IssueMissingSubsystem = "missing_subsystem" // 集群纳管的子系统不在子系统列表中
+	IssueCountMismatch    = "count_mismatch"    // 纳管子系统数与报表 TotalSubSystems 不一致
+	IssueFetchFailed      = "fetch_failed"      // 获取详情失败,无法校验
+)
+
+// IntegrityReport 子系统与集群引用一致性检查结果
+type IntegrityReport struct {
+	Clusters   int              `json:"clusters"`
+	Subsystems int              `json:"subsystems"`
+	Issues     []IntegrityIssue `json:"issues"`
+}
+
+// CountByCategory 按分类统计问题数
+func (r *IntegrityReport) CountByCategory() map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range r.Issues {
+		counts[issue.Category]++
+	}
+	return counts
+}
+
+// CheckIntegrity 校验子系统与集群之间的引用一致性:
+//   - 每个子系统归属的集群存在
+//   - 每个集群纳管的子系统都存在于子系统列表中
+//   - 集群纳管子系统数与 ClusterReportData.TotalSubSystems 一致
+func (c *Client) CheckIntegrity(ctx context.Context) (*IntegrityReport, error) {
+	var (
+		clusters   []LogClusterInfo
+		subsystems []SubSystem
+		errs       [2]error
+		wg         sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		clusters, errs[0] = c.GetClusters(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		subsystems, errs[1] = c.GetSubsystems(ctx)
+	}()
+	wg.Wait()
+	if errs[0] != nil {
+		return nil, fmt.Errorf("获取集群列表失败: %w", errs[0])
+	}
+	if errs[1] != nil {
+		return nil, fmt.Errorf("获取子系统列表失败: %w", errs[1])
+	}
+
+	clusterDetails := make([]*ClusterDetailResult, len(clusters))
+	clusterErrs := make([]error, len(clusters))
+	subsystemDetails := make([]*SubsystemDetailResult, len(subsystems))
+	subsystemErrs := make([]error, len(subsystems))
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		forEachConcurrent(len(clusters), defaultConcurrency, func(i int) {
+			clusterDetails[i], clusterErrs[i] = c.GetClusterDetail(ctx, clusters[i].ClusterName)
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		forEachConcurrent(len(subsystems), defaultConcurrency, func(i int) {
+			subsystemDetails[i], subsystemErrs[i] = c.GetSubsystemDetail(ctx, subsystems[i].SubsysID)
+		})
+	}()
+	wg.Wait()
+
+	report := &IntegrityReport{Clusters: len(clusters), Subsystems: len(subsystems)}
+
+	clusterNames := make(map[string]bool, len(clusters))
+	for _, cluster := range clusters {
+		clusterNames[cluster.ClusterName] = true
+	}
+	subsystemIDs := make(map[string]bool, len(subsystems))
+	for _, subsystem := range subsystems {
+		subsystemIDs[subsystem.SubsysID] = true
+	}
+
+	for i, detail := range subsystemDetails {
+		id := subsystems[i].SubsysID
+		if subsystemErrs[i] != nil {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				Category: IssueFetchFailed, SubsystemID: id, Detail: subsystemErrs[i].Error(),
+			})
+			continue
+		}
+		if detail.ClusterName != "" && !clusterNames[detail.ClusterName] {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				Category: IssueMissingCluster, ClusterName: detail.ClusterName, SubsystemID: id,
+				Detail: fmt.Sprintf("子系统 %s 归属的集群 %s 不存在", id, detail.ClusterName),
+			})
+		}
+	}
+
+	for i, detail := range clusterDetails {
+		name := clusters[i].ClusterName
+		if clusterErrs[i] != nil {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				Category: IssueFetchFailed, ClusterName: name, Detail: clusterErrs[i].Error(),
+			})
+			continue
+		}
+		for _, managed := range detail.ManagedSubSystems {
+			if !subsystemIDs[managed.SubsystemID] {
+				report.Issues = append(report.Issues, IntegrityIssue{
+					Category: IssueMissingSubsystem, ClusterName: name, SubsystemID: managed.SubsystemID,
+					Detail: fmt.Sprintf("集群 %s 纳管的子系统 %s 不存在", name, managed.SubsystemID),
+				})
+			}
+		}
+		if got, want := len(detail.ManagedSubSystems), detail.ReportData.TotalSubSystems; got != want {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				Category: IssueCountMismatch, ClusterName: name,
+				Detail: fmt.Sprintf("集群 %s 纳管子系统 %d 个,报表记录 %d 个", name, got, want),
+			})
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Category < report.Issues[j].Category
+	})
+	return report, nil
+}
+
+func cmdIntegrityCheck(client *Client, args *CommandLineArgs) error {
+	ctx := args.Context()
+	report, err := client.CheckIntegrity(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := printResult(args, report); err != nil {
+		return err
+	}
+
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("发现 %d 个一致性问题: %v", len(report.Issues), report.CountByCategory())
+	}
+	return nil
+}
+
+// ==================== 负责人检查 ====================
+
+// ownerFields 可检查的负责人字段 (JSON 字段名)
+var ownerFields = map[string]func(SubSystem) string{
+	"business_owner":  func(s SubSystem) string { return s.BusinessOwner },
+	"subsystem_owner": func(s SubSystem) string { return s.SubsystemOwner },
+}
+
+// parseOwnerFields 解析 --field 参数,为空时检查全部负责人字段
+func parseOwnerFields(value string) ([]string, error) {
+	if value == "" {
+		return []string{"business_owner", "subsystem_owner"}, nil
+	}
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if _, ok := ownerFields[field]; !ok {
+			return nil, fmt.Errorf("不支持的负责人字段: %q (可用: business_owner, subsystem_owner)", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// UnownedSubsystem 缺少负责人的子系统
+type UnownedSubsystem struct {
+	SubsysID      string   `json:"subsys_id"`
+	SubsysName    string   `json:"subsys_name"`
+	DevDept       string   `json:"devdept"`
+	MissingFields []string `json:"missingFields"`
+}
+
+// findUnownedSubsystems 找出指定负责人字段为空的子系统
+func findUnownedSubsystems(subsystems []SubSystem, fields []string) []UnownedSubsystem {
+	var unowned []UnownedSubsystem
+	for _, s := range subsystems {
+		var missing []string
+		for _, field := range fields {
+			if strings.TrimSpace(ownerFields[field](s)) == "" {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			unowned = append(unowned, UnownedSubsystem{
+				SubsysID: s.SubsysID, SubsysName: s.SubsysName, DevDept: s.DevDept, MissingFields: missing,
+			})
+		}
+	}
+	return unowned
+}
+
+func cmdCheckOwners(client *Client, args *CommandLineArgs) error {
+	fields, err := parseOwnerFields(args.Field)
+	if err != nil {
+		return err
+	}
+
+	subsystems, err := client.GetSubsystems(args.Context())
+	if err != nil {
+		return err
+	}
+
+	unowned := findUnownedSubsystems(subsystems, fields)
+	if err := printResult(args, unowned); err != nil {
+		return err
+	}
+	if len(unowned) > 0 {
+		return fmt.Errorf("%d/%d 个子系统缺少负责人", len(unowned), len(subsystems))
+	}
+	return nil
+}
+
+// ==================== 节点资源限制检查 ====================
+
+// LimitRange 资源允许范围,值为字符串形式 (如 "8"、"16Gi"),为空表示不限制
+type LimitRange struct {
+	Min string `yaml:"min"`
+	Max string `yaml:"max"`
+}
+
+// RoleLimits 单个角色的资源限制
+type RoleLimits struct {
+	CPU LimitRange `yaml:"cpu"`
+	Mem LimitRange `yaml:"mem"`
+}
+
+// NodeLimitPolicy 节点资源限制策略文件
+//
+//	roles:
+//	  write:
+//	    cpu: {min: "4", max: "16"}
+//	    mem: {min: "8Gi", max: "64Gi"}
+//	default:           # 未单独配置的角色使用此范围 (可选)
+//	  cpu: {max: "32"}
+type NodeLimitPolicy struct {
+	Roles   map[string]RoleLimits `yaml:"roles"`
+	Default *RoleLimits           `yaml:"default"`
+}
+
+// NodeLimitViolation 一条不符合策略的节点资源限制
+type NodeLimitViolation struct {
+	ClusterName string `json:"clusterName"`
+	Address     string `json:"address"`
+	Role        string `json:"role"`
+	Resource    string `json:"resource"` // cpu / mem / policy
+	Value       string `json:"value,omitempty"`
+	Detail      string `json:"detail"`
+}
+
+// parseCPU 解析 CPU 核数,支持 "8"、"0.5" 及毫核 "500m"
+func parseCPU(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "m") {
+		milli, err := strconv.ParseFloat(strings.TrimSuffix(s, "m"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("无法解析 CPU %q", s)
+		}
+		return milli / 1000, nil
+	}
+	cores, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析 CPU %q", s)
+	}
+	return cores, nil
+}
+
+// parseMem 解析内存大小 (字节),支持 "16Gi"、"16GB" 等,不带单位时按 GB 计算
+func parseMem(s string) (float64, error) {
+	n, err := parseSize(s, gigabyte)
+	return float64(n), err
+}
+
+// loadNodeLimitPolicy 读取策略文件,并校验其中的范围均可解析
+func loadNodeLimitPolicy(path string) (*NodeLimitPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取策略文件失败: %w", err)
+	}
+	var policy NodeLimitPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("解析策略文件失败: %w", err)
+	}
+
+	validate := func(name string, limits RoleLimits) error {
+		for _, v := range []string{limits.CPU.Min, limits.CPU.Max} {
+			if _, err := parseCPU(v); v != "" && err != nil {
+				return fmt.Errorf("策略 %s: %w", name, err)
+			}
+		}
+		for _, v := range []string{limits.Mem.Min, limits.Mem.Max} {
+			if _, err := parseMem(v); v != "" && err != nil {
+				return fmt.Errorf("策略 %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+	for role, limits := range policy.Roles {
+		if err := validate(role, limits); err != nil {
+			return nil, err
+		}
+	}
+	if policy.Default != nil {
+		if err := validate("default", *policy.Default); err != nil {
+			return nil, err
+		}
+	}
+	return &policy, nil
+}
+
+// checkRange 检查 value 是否落在 r 内,返回不符合时的说明
+func checkRange(value string, r LimitRange, parse func(string) (float64, error)) string {
+	if r.Min == "" && r.Max == "" {
+		return ""
+	}
+	v, err := parse(value)
+	if err != nil {
+		return err.Error()
+	}
+	if r.Min != "" {
+		if min, _ := parse(r.Min); v < min {
+			return fmt.Sprintf("低于允许下限 %s", r.Min)
+		}
+	}
+	if r.Max != "" {
+		if max, _ := parse(r.Max); v > max {
+			return fmt.Sprintf("超过允许上限 %s", r.Max)
+		}
+	}
+	return ""
+}
+
+// CheckNodeLimits 按角色检查节点 CPU/内存限制是否在策略范围内
+// 策略中既没有该角色也没有 default 时,该节点记为一条 policy 问题
+func CheckNodeLimits(nodes []LogStoreInstance, policy *NodeLimitPolicy) []NodeLimitViolation {
+	var violations []NodeLimitViolation
+	for _, node := range nodes {
+		limits, ok := policy.Roles[node.Role]
+		if !ok && policy.Default != nil {
+			limits, ok = *policy.Default, true
+		}
+		violation := NodeLimitViolation{ClusterName: node.ClusterName, Address: node.Address, Role: node.Role}
+		if !ok {
+			violation.Resource, violation.Detail = "policy", fmt.Sprintf("策略未定义角色 %q 且没有 default", node.Role)
+			violations = append(violations, violation)
+			continue
+		}
+
+		if detail := checkRange(node.CpuLimit, limits.CPU, parseCPU); detail != "" {
+			violation.Resource, violation.Value, violation.Detail = "cpu", node.CpuLimit, detail
+			violations = append(violations, violation)
+		}
+		if detail := checkRange(node.MemLimit, limits.Mem, parseMem); detail != "" {
+			violation.Resource, violation.Value, violation.Detail = "mem", node.MemLimit, detail
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
+
+func cmdCheckNodeLimits(client *Client, args *CommandLineArgs) error {
+	if args.Policy == "" {
+		return fmt.Errorf("请使用 --policy 指定策略文件")
+	}
+	policy, err := loadNodeLimitPolicy(args.Policy)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := client.ListAllNodes(args.Context())
+	if err != nil {
+		return err
+	}
+
+	violations := CheckNodeLimits(nodes, policy)
+	if err := printResult(args, violations); err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d 条节点资源限制不符合策略 (共检查 %d 个节点)", len(violations), len(nodes))
+	}
+	return nil
+}
+
+// ==================== 默认集群检查 ====================
+
+// CheckResult 一项检查的结果
+type CheckResult struct {
+	Check  string `json:"check"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// CheckDefaultCluster 依次检查: 有且仅有一个默认集群、默认集群详情可获取、其节点均健康
+// 前一项失败时后续检查无法进行,不再执行
+func (c *Client) CheckDefaultCluster(ctx context.Context) []CheckResult {
+	var results []CheckResult
+
+	cluster, err := c.GetDefaultCluster(ctx)
+	if err != nil {
+		return append(results, CheckResult{Check: "唯一默认集群", Detail: err.Error()})
+	}
+	results = append(results, CheckResult{Check: "唯一默认集群", OK: true, Detail: cluster.ClusterName})
+
+	health, err := c.GetClusterHealth(ctx, cluster.ClusterName)
+	if err != nil {
+		return append(results, CheckResult{Check: "默认集群存在", Detail: err.Error()})
+	}
+	results = append(results, CheckResult{Check: "默认集群存在", OK: true})
+
+	result := CheckResult{Check: "默认集群节点健康", OK: health.Healthy()}
+	switch {
+	case health.NodeCount == 0:
+		result.Detail = "集群没有节点"
+	case len(health.Unhealthy) > 0:
+		unhealthy := make([]string, len(health.Unhealthy))
+		for i, node := range health.Unhealthy {
+			unhealthy[i] = fmt.Sprintf("%s(%s)", node.Address, node.Status)
+		}
+		result.Detail = fmt.Sprintf("%d/%d 个节点不健康: %s", len(unhealthy), health.NodeCount, strings.Join(unhealthy, ", "))
+	default:
+		result.Detail = fmt.Sprintf("%d 个节点均健康", health.NodeCount)
+	}
+	return append(results, result)
+}
+
+func cmdCheckDefaultCluster(client *Client, args *CommandLineArgs) error {
+	results := client.CheckDefaultCluster(args.Context())
+	if err := printResult(args, results); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if !r.OK {
+			return fmt.Errorf("默认集群检查未通过: %s", r.Check)
+		}
+	}
+	return nil
+}
+
+// ==================== Topic 积压检查 ====================
+
+// BacklogAlert Topic 积压超过阈值的集群及其纳管的子系统
+// 积压高而子系统流量低通常说明消费端卡住
+type BacklogAlert struct {
+	ClusterName  string                   `json:"clusterName"`
+	TopicBacklog int64                    `json:"topicBacklog"`
+	TotalTraffic int64                    `json:"totalTraffic"` // 纳管子系统流量之和
+	Subsystems   []LogSubClusterSubSystem `json:"subsystems"`
+}
+
+// findBacklogAlerts 找出 TopicBacklog 超过 threshold 的集群,按积压降序排列; 未获取到的集群 (nil) 跳过
+func findBacklogAlerts(details []*ClusterDetailResult, threshold int64) []BacklogAlert {
+	var alerts []BacklogAlert
+	for _, detail := range details {
+		if detail == nil || detail.ReportData.TopicBacklog <= threshold {
+			continue
+		}
+		alert := BacklogAlert{
+			ClusterName:  detail.ClusterInfo.ClusterName,
+			TopicBacklog: detail.ReportData.TopicBacklog,
+			Subsystems:   detail.ManagedSubSystems,
+		}
+		for _, s := range detail.ManagedSubSystems {
+			alert.TotalTraffic += s.Traffic
+		}
+		alerts = append(alerts, alert)
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].TopicBacklog > alerts[j].TopicBacklog
+	})
+	return alerts
+}
+
+// CheckBacklog 并发获取所有集群详情,返回 Topic 积压超过 threshold 的集群
+// 部分集群获取失败时仍返回其余集群的检查结果及错误
+func (c *Client) CheckBacklog(ctx context.Context, threshold int64) ([]BacklogAlert, error) {
+	details, err := c.GetAllClusterDetails(ctx)
+	return findBacklogAlerts(details, threshold), err
+}
+
+func cmdCheckBacklog(client *Client, args *CommandLineArgs) error {
+	if args.Threshold <= 0 {
+		return fmt.Errorf("请使用 --threshold 指定 Topic 积压阈值")
+	}
+
+	alerts, err := client.CheckBacklog(args.Context(), args.Threshold)
+	if alerts == nil && err != nil {
+		return err
+	}
+	if err := printResult(args, alerts); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if len(alerts) > 0 {
+		return fmt.Errorf("%d 个集群 Topic 积压超过 %d", len(alerts), args.Threshold)
+	}
+	return nil
+}
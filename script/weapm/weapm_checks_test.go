@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== 一致性检查 ====================
+
+// integrityFixture LOG001 纳管 SYS001 及已不存在的 SYS404,报表记录 3 个;
+// SYS002 归属已下线的 LOG009,SYS003 详情获取失败
+func integrityFixture() *fakeAPI {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{{ClusterName: "LOG001"}})
+	})
+	api.handle("GET /operation/subsystems", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []SubSystem{{SubsysID: "SYS001"}, {SubsysID: "SYS002"}, {SubsysID: "SYS003"}})
+	})
+	api.handle("GET /operation/clusters/LOG001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, ClusterDetailResult{
+			ClusterInfo:       LogClusterInfo{ClusterName: "LOG001"},
+			ManagedSubSystems: []LogSubClusterSubSystem{{SubsystemID: "SYS001"}, {SubsystemID: "SYS404"}},
+			ReportData:        ClusterReportData{TotalSubSystems: 3},
+		})
+	})
+	api.handle("GET /operation/subsystem/SYS001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, SubsystemDetailResult{ClusterName: "LOG001"})
+	})
+	api.handle("GET /operation/subsystem/SYS002", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, SubsystemDetailResult{ClusterName: "LOG009"})
+	})
+	api.handle("GET /operation/subsystem/SYS003", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusBadRequest, 400, "bad subsystem")
+	})
+	return api
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	client := newTestClient(t, integrityFixture())
+
+	report, err := client.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Clusters != 1 || report.Subsystems != 3 {
+		t.Errorf("counted %d clusters, %d subsystems, want 1 and 3", report.Clusters, report.Subsystems)
+	}
+
+	want := map[string]int{
+		IssueCountMismatch:    1,
+		IssueFetchFailed:      1,
+		IssueMissingCluster:   1,
+		IssueMissingSubsystem: 1,
+	}
+	if got := report.CountByCategory(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CountByCategory() = %v, want %v", got, want)
+	}
+
+	// 问题按分类排序
+	for i := 1; i < len(report.Issues); i++ {
+		if report.Issues[i-1].Category > report.Issues[i].Category {
+			t.Errorf("issues not sorted by category: %+v", report.Issues)
+			break
+		}
+	}
+	for _, issue := range report.Issues {
+		switch issue.Category {
+		case IssueMissingCluster:
+			if issue.SubsystemID != "SYS002" || issue.ClusterName != "LOG009" {
+				t.Errorf("missing cluster issue = %+v", issue)
+			}
+		case IssueMissingSubsystem:
+			if issue.SubsystemID != "SYS404" || issue.ClusterName != "LOG001" {
+				t.Errorf("missing subsystem issue = %+v", issue)
+			}
+		case IssueFetchFailed:
+			if issue.SubsystemID != "SYS003" {
+				t.Errorf("fetch failed issue = %+v", issue)
+			}
+		}
+	}
+}
+
+func TestCheckIntegrityClean(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{{ClusterName: "LOG001"}})
+	})
+	api.handle("GET /operation/subsystems", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []SubSystem{{SubsysID: "SYS001"}})
+	})
+	api.handle("GET /operation/clusters/LOG001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, ClusterDetailResult{
+			ManagedSubSystems: []LogSubClusterSubSystem{{SubsystemID: "SYS001"}},
+			ReportData:        ClusterReportData{TotalSubSystems: 1},
+		})
+	})
+	api.handle("GET /operation/subsystem/SYS001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, SubsystemDetailResult{ClusterName: "LOG001"})
+	})
+	client := newTestClient(t, api)
+
+	report, err := client.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("issues = %+v, want none", report.Issues)
+	}
+}
+
+func TestCheckIntegrityListFailure(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{})
+	})
+	client := newTestClient(t, api)
+
+	if _, err := client.CheckIntegrity(context.Background()); err == nil {
+		t.Fatal("CheckIntegrity() error = nil, want subsystem list failure")
+	}
+}
+
+// ==================== 负责人检查 ====================
+
+func TestParseOwnerFields(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", []string{"business_owner", "subsystem_owner"}, false},
+		{"subsystem_owner", []string{"subsystem_owner"}, false},
+		{" business_owner , subsystem_owner", []string{"business_owner", "subsystem_owner"}, false},
+		{"devdept", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseOwnerFields(tt.value)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseOwnerFields(%q) = %v, %v, want %v (error: %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFindUnownedSubsystems(t *testing.T) {
+	subsystems := []SubSystem{
+		{SubsysID: "SYS001", BusinessOwner: "zhangsan", SubsystemOwner: "lisi"},
+		{SubsysID: "SYS002", BusinessOwner: "zhangsan", SubsystemOwner: "  "},
+		{SubsysID: "SYS003"},
+	}
+
+	unowned := findUnownedSubsystems(subsystems, []string{"business_owner", "subsystem_owner"})
+	want := []UnownedSubsystem{
+		{SubsysID: "SYS002", MissingFields: []string{"subsystem_owner"}},
+		{SubsysID: "SYS003", MissingFields: []string{"business_owner", "subsystem_owner"}},
+	}
+	if !reflect.DeepEqual(unowned, want) {
+		t.Errorf("unowned = %+v, want %+v", unowned, want)
+	}
+
+	// 只检查 business_owner 时 SYS002 不算缺失
+	unowned = findUnownedSubsystems(subsystems, []string{"business_owner"})
+	if len(unowned) != 1 || unowned[0].SubsysID != "SYS003" {
+		t.Errorf("unowned = %+v, want only SYS003", unowned)
+	}
+}
+
+// ==================== 节点资源限制检查 ====================
+
+func TestParseCPU(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    float64
+		wantErr bool
+	}{
+		{"8", 8, false},
+		{"0.5", 0.5, false},
+		{"500m", 0.5, false},
+		{" 2 ", 2, false},
+		{"two", 0, true},
+		{"xm", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCPU(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseCPU(%q) = %v, %v, want %v (error: %v)", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+const nodeLimitPolicyYAML = `
+roles:
+  write:
+    cpu: {min: "4", max: "16"}
+    mem: {min: "8Gi", max: "64Gi"}
+default:
+  cpu: {max: "2000m"}
+`
+
+func TestLoadNodeLimitPolicy(t *testing.T) {
+	policy, err := loadNodeLimitPolicy(writeConfig(t, "policy.yaml", nodeLimitPolicyYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Roles["write"].Mem.Max != "64Gi" || policy.Default == nil || policy.Default.CPU.Max != "2000m" {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	// 范围无法解析时在发送请求前报错
+	_, err = loadNodeLimitPolicy(writeConfig(t, "bad.yaml", "roles:\n  read:\n    mem: {max: \"lots\"}\n"))
+	if err == nil || !strings.Contains(err.Error(), "策略 read") {
+		t.Errorf("error = %v, want the read role named", err)
+	}
+}
+
+func TestCheckNodeLimits(t *testing.T) {
+	policy, err := loadNodeLimitPolicy(writeConfig(t, "policy.yaml", nodeLimitPolicyYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []LogStoreInstance{
+		{Address: "10.0.0.1", Role: "write", CpuLimit: "8", MemLimit: "16Gi"},  // 符合
+		{Address: "10.0.0.2", Role: "write", CpuLimit: "32", MemLimit: "4GB"},  // cpu 超上限, mem 低于下限
+		{Address: "10.0.0.3", Role: "read", CpuLimit: "4", MemLimit: "1"},      // 使用 default, cpu 超上限
+		{Address: "10.0.0.4", Role: "write", CpuLimit: "8", MemLimit: "bogus"}, // 无法解析
+	}
+
+	var got []string
+	for _, v := range CheckNodeLimits(nodes, policy) {
+		got = append(got, v.Address+"/"+v.Resource)
+	}
+	want := []string{"10.0.0.2/cpu", "10.0.0.2/mem", "10.0.0.3/cpu", "10.0.0.4/mem"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+
+	// 没有 default 时未配置的角色记为 policy 问题
+	policy.Default = nil
+	violations := CheckNodeLimits(nodes[2:3], policy)
+	if len(violations) != 1 || violations[0].Resource != "policy" {
+		t.Errorf("violations = %+v, want one policy violation", violations)
+	}
+}
+
+// ==================== 默认集群检查 ====================
+
+func TestGetDefaultCluster(t *testing.T) {
+	tests := []struct {
+		name     string
+		clusters []LogClusterInfo
+		want     string
+		wantErr  error
+	}{
+		{"single", []LogClusterInfo{{ClusterName: "LOG001"}, {ClusterName: "LOG002", IsDefault: 1}}, "LOG002", nil},
+		{"none", []LogClusterInfo{{ClusterName: "LOG001"}}, "", ErrNoDefaultCluster},
+		{"multiple", []LogClusterInfo{{ClusterName: "LOG001", IsDefault: 1}, {ClusterName: "LOG002", IsDefault: 1}}, "", ErrMultipleDefaultClusters},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+				respondResult(w, tt.clusters)
+			})
+			cluster, err := newTestClient(t, api).GetDefaultCluster(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cluster.ClusterName != tt.want {
+				t.Errorf("default cluster = %q, want %q", cluster.ClusterName, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDefaultCluster(t *testing.T) {
+	tests := []struct {
+		name   string
+		nodes  []LogStoreInstance
+		wantOK []bool
+		detail string
+	}{
+		{"healthy", []LogStoreInstance{{Address: "10.0.0.1", Status: "Running"}, {Address: "10.0.0.2", Status: " ok "}}, []bool{true, true, true}, "2 个节点均健康"},
+		{"unhealthy node", []LogStoreInstance{{Address: "10.0.0.1", Status: "running"}, {Address: "10.0.0.2", Status: "stopped"}}, []bool{true, true, false}, "10.0.0.2(stopped)"},
+		{"no nodes", nil, []bool{true, true, false}, "集群没有节点"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+				respondResult(w, []LogClusterInfo{{ClusterName: "LOG001", IsDefault: 1}})
+			})
+			api.handle("GET /operation/clusters/LOG001", clusterWithNodes("LOG001", tt.nodes...))
+
+			results := newTestClient(t, api).CheckDefaultCluster(context.Background())
+			var ok []bool
+			for _, r := range results {
+				ok = append(ok, r.OK)
+			}
+			if !reflect.DeepEqual(ok, tt.wantOK) {
+				t.Fatalf("results = %+v, want ok %v", results, tt.wantOK)
+			}
+			if last := results[len(results)-1]; !strings.Contains(last.Detail, tt.detail) {
+				t.Errorf("detail = %q, want it to contain %q", last.Detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestCheckDefaultClusterStopsAtFirstFailure(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{{ClusterName: "LOG001", IsDefault: 1}})
+	})
+	// 集群详情 404,节点健康检查不再执行
+
+	results := newTestClient(t, api).CheckDefaultCluster(context.Background())
+	if len(results) != 2 || !results[0].OK || results[1].OK {
+		t.Errorf("results = %+v, want default ok then detail failure", results)
+	}
+}
+
+// ==================== Topic 积压检查 ====================
+
+func TestFindBacklogAlerts(t *testing.T) {
+	details := []*ClusterDetailResult{
+		{ClusterInfo: LogClusterInfo{ClusterName: "LOG001"}, ReportData: ClusterReportData{TopicBacklog: 500}},
+		nil, // 获取失败的集群
+		{
+			ClusterInfo: LogClusterInfo{ClusterName: "LOG002"},
+			ReportData:  ClusterReportData{TopicBacklog: 9000},
+			ManagedSubSystems: []LogSubClusterSubSystem{
+				{SubsystemID: "SYS001", Traffic: 10},
+				{SubsystemID: "SYS002", Traffic: 20},
+			},
+		},
+		{ClusterInfo: LogClusterInfo{ClusterName: "LOG003"}, ReportData: ClusterReportData{TopicBacklog: 1000}},
+		{ClusterInfo: LogClusterInfo{ClusterName: "LOG004"}, ReportData: ClusterReportData{TopicBacklog: 1001}},
+	}
+
+	alerts := findBacklogAlerts(details, 1000)
+	var names []string
+	for _, a := range alerts {
+		names = append(names, a.ClusterName)
+	}
+	// 等于阈值不告警,按积压降序
+	if want := []string{"LOG002", "LOG004"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("alerts = %v, want %v", names, want)
+	}
+	if alerts[0].TotalTraffic != 30 || len(alerts[0].Subsystems) != 2 {
+		t.Errorf("LOG002 alert = %+v, want total traffic 30 from 2 subsystems", alerts[0])
+	}
+}
+
+func TestCheckBacklogPartialFailure(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{{ClusterName: "LOG001"}, {ClusterName: "LOG002"}})
+	})
+	api.handle("GET /operation/clusters/LOG001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, ClusterDetailResult{
+			ClusterInfo: LogClusterInfo{ClusterName: "LOG001"},
+			ReportData:  ClusterReportData{TopicBacklog: 5000},
+		})
+	})
+	// LOG002 未注册,返回 404
+
+	alerts, err := newTestClient(t, api).CheckBacklog(context.Background(), 100)
+	if err == nil || !strings.Contains(err.Error(), "LOG002") {
+		t.Errorf("error = %v, want it to name LOG002", err)
+	}
+	if len(alerts) != 1 || alerts[0].ClusterName != "LOG001" {
+		t.Errorf("alerts = %+v, want LOG001 despite the failure", alerts)
+	}
+}
+
+func TestCmdCheckBacklogRequiresThreshold(t *testing.T) {
+	api := newFakeAPI()
+	err := cmdCheckBacklog(newTestClient(t, api), &CommandLineArgs{})
+	if err == nil || !strings.Contains(err.Error(), "--threshold") {
+		t.Errorf("error = %v, want a hint to set --threshold", err)
+	}
+	if len(api.requests()) != 0 {
+		t.Errorf("requests = %v, want none without a threshold", api.requests())
+	}
+}
@@ -1,32 +1,66 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
 	"time"
+
+	"github.com/Dreamshe-92/skill/script/weapm/mockserver"
+	"gopkg.in/yaml.v3"
 )
 
 // ==================== 命令行参数 ====================
 
 type CommandLineArgs struct {
 	ConfigPath  string
+	OverridePath string
 	Env         string
 	BaseURL     string
 	Username    string
 	Password    string
+	CredsFromEnv bool
 	Timeout     int
 	Quiet       bool
 	Command     string
+	Positional  []string
 	ClusterName string
 	Detail      bool
+	Default     bool
+	ClusterSubsystems bool
+	Sort        string
 	Search      bool
 	SubsysID    string
+	Dept        string
+	Owner       string
+	State       string
 	Check       string
+	DetailID    string
+	DeleteID    string
+	DisableID   string
+	SuggestTarget string
+	TrafficHistory string
+	From        string
+	To          string
+	Granularity string
+	UpdateID    string
+	Traffic     int64
+	Keywords    string
+	Whitelist   string
 	Limit       int
+	Offset      int
 	Address     string
 	Role        string
 	CpuLimit    string
@@ -36,6 +70,69 @@ type CommandLineArgs struct {
 	BackendDomain string
 	StorageDomain string
 	Status      string
+	MaxColWidth int
+	IfNotExists bool
+	Dir         string
+	Interval    time.Duration
+	DryRun      bool
+	Effective   bool
+	Template     string
+	TemplateFile string
+	Format       string
+	Out          string
+	File         string
+	FromFile     string
+	Resume       bool
+	Concurrency  int
+	Addr         string
+	Field        string
+	Follow       bool
+	CostPerGB    float64
+	Threshold    int64
+	Policy       string
+	All          bool
+	Confirm      bool
+	Redact       string
+	RedactMode   string
+	Validate     bool
+	Timing       bool
+	Output       string
+	InitPath     string
+	Force        bool
+	Yes          bool
+	Version      bool
+	Stdin        bool
+	NoConfigCache bool
+	Mock         bool
+
+	tmpl *template.Template // 解析后的输出模板
+	ctx  context.Context    // 命令执行的 context,带 --timeout 截止时间
+
+	limitSet bool // 命令行中显式指定了 --limit
+}
+
+// Context 返回命令执行的 context,未设置时为 context.Background()
+func (a *CommandLineArgs) Context() context.Context {
+	if a.ctx == nil {
+		return context.Background()
+	}
+	return a.ctx
+}
+
+// hiddenFlags 不在 -help 中列出的参数
+var hiddenFlags = map[string]bool{"mock": true}
+
+// usageWithoutHidden 同 flag 包默认的用法输出,但跳过 hiddenFlags 中的参数
+func usageWithoutHidden() {
+	visible := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	visible.SetOutput(flag.CommandLine.Output())
+	flag.VisitAll(func(f *flag.Flag) {
+		if !hiddenFlags[f.Name] {
+			visible.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	fmt.Fprintf(visible.Output(), "Usage of %s:\n", os.Args[0])
+	visible.PrintDefaults()
 }
 
 func parseArgs() *CommandLineArgs {
@@ -44,28 +141,71 @@ func parseArgs() *CommandLineArgs {
 	// 全局参数
 	flag.StringVar(&args.ConfigPath, "config", "", "配置文件路径")
 	flag.StringVar(&args.ConfigPath, "c", "", "配置文件路径 (简写)")
-	flag.StringVar(&args.Env, "env", "", "环境名称 (dev/prod)")
+	flag.StringVar(&args.OverridePath, "config-override", "", "覆盖配置文件路径,非空字段覆盖基础配置")
+	flag.StringVar(&args.Env, "env", "", "环境名称 (如 dev/prod/staging,对应配置文件中的环境)")
+	flag.BoolVar(&args.NoConfigCache, "no-config-cache", false, "忽略配置缓存 (WEAPM_CONFIG_CACHE=1),重新解析配置文件")
+	// 隐藏参数,不在帮助中列出: 启动内置的模拟服务端,用于本地开发和演示
+	flag.BoolVar(&args.Mock, "mock", false, "使用内置的模拟服务端,不连接真实后端")
 	flag.StringVar(&args.Env, "e", "", "环境名称 (简写)")
 	flag.StringVar(&args.BaseURL, "base-url", "", "API 基础 URL")
 	flag.StringVar(&args.Username, "username", "", "用户名")
 	flag.StringVar(&args.Password, "password", "", "密码")
-	flag.IntVar(&args.Timeout, "timeout", 30, "请求超时时间(秒)")
+	flag.BoolVar(&args.CredsFromEnv, "creds-from-env", false, "只从 WEAPM_USERNAME/WEAPM_PASSWORD/WEAPM_TOKEN 读取凭据,缺少时报错而不使用默认值")
+	flag.IntVar(&args.Timeout, "timeout", 0, "命令整体超时时间(秒),包括所有重试 (0 表示不限制,单次请求超时由配置文件 timeout 控制)")
 	flag.BoolVar(&args.Quiet, "quiet", false, "静默模式,不输出日志")
 	flag.BoolVar(&args.Quiet, "q", false, "静默模式 (简写)")
+	flag.StringVar(&args.Template, "template", "", "使用 Go text/template 渲染输出,如 '{{ range . }}{{ .SubsysName }}{{ end }}'")
+	flag.StringVar(&args.TemplateFile, "template-file", "", "从文件读取输出模板")
+	flag.StringVar(&args.Format, "format", "", "导出/报表格式")
+	flag.StringVar(&args.Output, "output", "", "输出格式: json (默认) / table / csv, table 与 csv 支持 clusters、subsystems、nodes 列表及 details; status、report、cost-report、schema 中与 --format 等价")
+	flag.StringVar(&args.Output, "o", "", "输出格式 (简写)")
+	flag.StringVar(&args.Out, "out", "", "输出文件路径")
+	flag.BoolVar(&args.Timing, "timing", false, "在 stderr 输出配置加载、客户端初始化、网络请求及渲染各阶段耗时")
+	flag.IntVar(&args.MaxColWidth, "max-col-width", 0, "表格输出时单列最大显示宽度,超出部分以省略号截断 (0 表示不限制, json/yaml 输出不受影响)")
 
 	// 集群管理参数
 	flag.StringVar(&args.ClusterName, "cluster-name", "", "集群名称")
 	flag.StringVar(&args.ClusterName, "n", "", "集群名称 (简写)")
+	flag.StringVar(&args.ClusterName, "cluster", "", "集群名称 (同 --cluster-name)")
 	flag.BoolVar(&args.Detail, "detail", false, "显示详细信息")
 	flag.BoolVar(&args.Detail, "d", false, "显示详细信息 (简写)")
+	flag.BoolVar(&args.Default, "default", false, "clusters 只显示默认集群 (IsDefault == 1)")
+	flag.BoolVar(&args.ClusterSubsystems, "subsystems", false, "clusters 列出 --cluster-name 纳管的子系统,支持 --limit / --offset / --sort 服务端分页")
+	flag.StringVar(&args.Sort, "sort", "", "clusters --subsystems 排序字段: traffic / subsys_name / subsystemid / createtime / updatetime")
 
 	// 子系统参数
 	flag.BoolVar(&args.Search, "search", false, "搜索子系统")
 	flag.BoolVar(&args.Search, "s", false, "搜索子系统 (简写)")
 	flag.StringVar(&args.SubsysID, "subsys-id", "", "子系统ID")
+	flag.StringVar(&args.Dept, "dept", "", "--search 按所属部门过滤")
+	flag.StringVar(&args.Owner, "owner", "", "--search 按业务负责人过滤")
+	flag.StringVar(&args.State, "state", "", "--search 按子系统状态过滤")
 	flag.StringVar(&args.Check, "check", "", "检查子系统是否存在")
+	flag.StringVar(&args.DetailID, "subsys-detail", "", "查询子系统详情 (子系统ID)")
+	flag.StringVar(&args.DeleteID, "delete", "", "删除子系统 (子系统ID)")
+	flag.StringVar(&args.DisableID, "disable", "", "停用子系统 (子系统ID)")
+	flag.StringVar(&args.SuggestTarget, "suggest-target", "", "列出可容纳该子系统流量的候选目标集群 (子系统ID)")
+	flag.StringVar(&args.TrafficHistory, "traffic-history", "", "查询子系统流量时间序列 (子系统ID),配合 --from / --to / --granularity")
+	flag.StringVar(&args.From, "from", "24h", "--traffic-history 开始时间: RFC3339 或距今时长 (如 24h)")
+	flag.StringVar(&args.To, "to", "", "--traffic-history 结束时间: RFC3339 或距今时长,默认当前时间")
+	flag.StringVar(&args.Granularity, "granularity", "", "--traffic-history 粒度: minute / hour / day,默认由服务端决定")
+	flag.StringVar(&args.UpdateID, "update", "", "修改子系统 (子系统ID),配合 --traffic / --cluster / --keywords / --whitelist")
+	flag.Int64Var(&args.Traffic, "traffic", -1, "--update 设置的流量 (负数表示不修改)")
+	flag.StringVar(&args.Keywords, "keywords", "", "--update 设置的关键字过滤,逗号分隔")
+	flag.StringVar(&args.Whitelist, "whitelist", "", "--update 设置的扫描文件白名单,逗号分隔")
 	flag.IntVar(&args.Limit, "limit", 20, "返回结果数量限制")
 	flag.IntVar(&args.Limit, "l", 20, "返回结果数量限制 (简写)")
+	flag.IntVar(&args.Offset, "offset", 0, "subsystems 分页起始位置,与 --limit 配合使用")
+	flag.StringVar(&args.Field, "field", "", "check-owners 要求非空的负责人字段,逗号分隔 (默认 business_owner,subsystem_owner)")
+	flag.BoolVar(&args.All, "all", false, "delete-nodes 删除集群全部节点")
+	flag.BoolVar(&args.Confirm, "confirm", false, "确认执行不可恢复的批量操作")
+	flag.StringVar(&args.Policy, "policy", "", "check-node-limits 节点资源限制策略文件 (YAML)")
+	flag.Float64Var(&args.CostPerGB, "cost-per-gb", 0, "cost-report 每 GB 每月存储单价")
+	flag.Int64Var(&args.Threshold, "threshold", 0, "check-backlog Topic 积压告警阈值")
+	flag.BoolVar(&args.Follow, "follow", false, "subsystems 持续轮询并输出新增的子系统 (配合 --interval)")
+	flag.StringVar(&args.Redact, "redact", "", "导出时脱敏的字段,逗号分隔的 JSON 字段名 (如 business_owner,subsystem_owner)")
+	flag.StringVar(&args.RedactMode, "redact-mode", RedactMask, "脱敏方式: mask (***) / hash (稳定哈希)")
+	flag.BoolVar(&args.Validate, "validate", false, "schema 对请求/响应类型做往返序列化自检")
 
 	// 节点管理参数
 	flag.StringVar(&args.Address, "address", "", "节点IP地址")
@@ -77,12 +217,53 @@ func parseArgs() *CommandLineArgs {
 	flag.StringVar(&args.BackendDomain, "backenddomain", "", "后端域")
 	flag.StringVar(&args.StorageDomain, "storagedomain", "", "存储域")
 	flag.StringVar(&args.Status, "status", "", "状态")
+	flag.BoolVar(&args.Stdin, "stdin", false, "add-node 从标准输入读取节点 JSON (同位置参数 -),命令行参数覆盖其中的字段")
+	flag.BoolVar(&args.IfNotExists, "if-not-exists", false, "节点已存在时视为成功")
+
+	// 期望状态同步参数
+	flag.StringVar(&args.Dir, "dir", "", "期望状态目录 (每个集群一个 YAML 文件)")
+	flag.DurationVar(&args.Interval, "interval", time.Minute, "同步间隔")
+	flag.BoolVar(&args.DryRun, "dry-run", false, "仅打印将要执行的变更,不实际执行 (对所有变更命令生效,输出请求方法、URL 及请求体)")
+
+	// 批量操作参数
+	flag.StringVar(&args.File, "file", "", "批量操作输入文件")
+	flag.StringVar(&args.FromFile, "from-file", "", "add-nodes 的节点文件 (JSON 数组)")
+	flag.BoolVar(&args.Resume, "resume", false, "跳过上次运行中已成功的条目")
+	flag.IntVar(&args.Concurrency, "concurrency", defaultConcurrency, "批量操作并发数")
+
+	// 服务模式参数
+	flag.StringVar(&args.Addr, "addr", ":8080", "服务模式监听地址")
 
+	// 配置命令参数
+	flag.BoolVar(&args.Effective, "effective", false, "显示实际生效的配置 (合并覆盖文件、命令行参数和默认值后)")
+	flag.StringVar(&args.InitPath, "path", "", "config init 生成的配置文件路径 (默认为可执行文件同目录下的 config.yaml)")
+	flag.BoolVar(&args.Force, "force", false, "覆盖已存在的文件; delete-node 跳过节点存在性检查; reconcile 允许删除集群全部节点或最后一个 master")
+	flag.BoolVar(&args.Yes, "yes", false, "delete-node 跳过删除确认提示")
+	flag.BoolVar(&args.Version, "version", false, "显示版本信息")
+
+	flag.Usage = usageWithoutHidden
 	flag.Parse()
 
+	// flag 包遇到第一个非标志参数即停止解析,这里继续解析命令之后的参数,
+	// 使 "clusters --detail" 这类写法生效,并收集其中的位置参数
+	var positional []string
+	rest := flag.Args()
+	for len(rest) > 0 {
+		positional = append(positional, rest[0])
+		flag.CommandLine.Parse(rest[1:])
+		rest = flag.Args()
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "limit" || f.Name == "l" {
+			args.limitSet = true
+		}
+	})
+
 	// 获取命令 (第一个非标志参数)
-	if len(flag.Args()) > 0 {
-		args.Command = flag.Args()[0]
+	if len(positional) > 0 {
+		args.Command = positional[0]
+		args.Positional = positional[1:]
 	}
 
 	return args
@@ -90,20 +271,43 @@ func parseArgs() *CommandLineArgs {
 
 // ==================== 命令处理函数 ====================
 
-func cmdDashboard(client *Client) error {
-	ctx := context.Background()
+func cmdDashboard(client *Client, args *CommandLineArgs) error {
+	ctx := args.Context()
 	dashboard, err := client.GetDashboard(ctx)
 	if err != nil {
 		return err
 	}
 
-	result, _ := json.MarshalIndent(dashboard, "", "  ")
-	fmt.Println(string(result))
+	if err := printResult(args, dashboard); err != nil {
+		return err
+	}
+	if len(dashboard.FailedSections) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  以下数据大盘字段解析失败,已跳过: %s\n", strings.Join(dashboard.FailedSections, ", "))
+	}
 	return nil
 }
 
 func cmdClusters(client *Client, args *CommandLineArgs) error {
-	ctx := context.Background()
+	ctx := args.Context()
+
+	// --default 查询默认集群,与 --detail 同时使用时显示默认集群详情
+	if args.Default {
+		if args.ClusterName != "" {
+			return fmt.Errorf("--default 与 --cluster-name 不能同时使用")
+		}
+		cluster, err := client.GetDefaultCluster(ctx)
+		if err != nil {
+			return err
+		}
+		if !args.Detail {
+			return printResult(args, cluster)
+		}
+		args.ClusterName = cluster.ClusterName
+	}
+
+	if args.ClusterSubsystems {
+		return listClusterSubsystems(client, args)
+	}
 
 	if args.Detail {
 		if args.ClusterName == "" {
@@ -115,37 +319,193 @@ func cmdClusters(client *Client, args *CommandLineArgs) error {
 			return err
 		}
 
-		output, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(output))
-	} else {
-		clusters, err := client.GetClusters(ctx)
-		if err != nil {
-			return err
-		}
+		return printResult(args, result)
+	}
 
-		output, _ := json.MarshalIndent(clusters, "", "  ")
-		fmt.Println(string(output))
+	clusters, err := client.GetClusters(ctx)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return printResult(args, clusters)
 }
 
-func cmdSubsystems(client *Client, args *CommandLineArgs) error {
-	ctx := context.Background()
+// listClusterSubsystems 输出集群纳管的子系统,由服务端按 --limit/--offset/--sort 分页排序,分页信息输出到 stderr
+func listClusterSubsystems(client *Client, args *CommandLineArgs) error {
+	if args.ClusterName == "" {
+		return fmt.Errorf("使用 --subsystems 时必须指定 --cluster-name")
+	}
+	if args.Offset < 0 || args.Limit <= 0 {
+		return fmt.Errorf("--offset 不能为负数且 --limit 必须大于 0")
+	}
 
-	var result interface{}
-	var err error
+	subsystems, total, err := client.GetClusterSubsystemsPage(args.Context(), args.ClusterName, &GetClusterSubsystemsRequest{
+		Limit:  args.Limit,
+		Offset: args.Offset,
+		SortBy: args.Sort,
+	})
+	if err != nil {
+		return err
+	}
+
+	totalText := "未知"
+	if total >= 0 {
+		totalText = strconv.Itoa(total)
+	}
+	fmt.Fprintf(os.Stderr, "第 %d-%d 个,共 %s 个\n", args.Offset+1, args.Offset+len(subsystems), totalText)
+	return printResult(args, subsystems)
+}
 
+// listSubsystemsPage 按 --offset/--limit 输出一页子系统,分页信息输出到 stderr
+func listSubsystemsPage(client *Client, args *CommandLineArgs) error {
+	if args.Offset < 0 || args.Limit <= 0 {
+		return fmt.Errorf("--offset 不能为负数且 --limit 必须大于 0")
+	}
+	subsystems, total, err := client.GetSubsystemsPage(args.Context(), args.Offset, args.Limit)
+	if err != nil {
+		return err
+	}
+
+	totalText := "未知"
+	if total >= 0 {
+		totalText = strconv.Itoa(total)
+	}
+	fmt.Fprintf(os.Stderr, "第 %d-%d 个,共 %s 个\n", args.Offset+1, args.Offset+len(subsystems), totalText)
+	return printResult(args, subsystems)
+}
+
+// subsystems 子命令的操作
+const (
+	subsystemsList    = "list"
+	subsystemsSearch  = "search"
+	subsystemsCheck   = "check"
+	subsystemsDetail  = "detail"
+	subsystemsFollow  = "follow"
+	subsystemsDelete  = "delete"
+	subsystemsUpdate  = "update"
+	subsystemsDisable = "disable"
+	subsystemsSuggest = "suggest-target"
+	subsystemsTraffic = "traffic-history"
+	subsystemsCluster = "cluster"
+)
+
+// newSearchSubsystemsRequest 根据 --subsys-id / --dept / --owner / --state 构造搜索条件,只发送指定了的条件
+func newSearchSubsystemsRequest(args *CommandLineArgs) *SearchSubsystemsRequest {
+	req := &SearchSubsystemsRequest{Cluster: args.ClusterName, Limit: args.Limit}
+	if args.SubsysID != "" {
+		req.SubsysID = &args.SubsysID
+	}
+	if args.Dept != "" {
+		req.DevDept = &args.Dept
+	}
+	if args.Owner != "" {
+		req.BusinessOwner = &args.Owner
+	}
+	if args.State != "" {
+		req.State = &args.State
+	}
+	return req
+}
+
+// resolveSubsystemsAction 根据参数确定 subsystems 执行的操作,未指定时列出全部子系统
+// --search / --check / --subsys-detail / --follow / --delete / --update / --disable / --suggest-target / --traffic-history 互斥
+func resolveSubsystemsAction(args *CommandLineArgs) (string, error) {
+	var actions []string
 	if args.Search {
-		result, err = client.SearchSubsystems(ctx, &SearchSubsystemsRequest{
-			SubsysID: &args.SubsysID,
-			Limit:    args.Limit,
-		})
-	} else if args.Check != "" {
+		actions = append(actions, subsystemsSearch)
+	}
+	if args.Check != "" {
+		actions = append(actions, subsystemsCheck)
+	}
+	if args.DetailID != "" {
+		actions = append(actions, subsystemsDetail)
+	}
+	if args.Follow {
+		actions = append(actions, subsystemsFollow)
+	}
+	if args.DeleteID != "" {
+		actions = append(actions, subsystemsDelete)
+	}
+	if args.UpdateID != "" {
+		actions = append(actions, subsystemsUpdate)
+	}
+	if args.DisableID != "" {
+		actions = append(actions, subsystemsDisable)
+	}
+	if args.SuggestTarget != "" {
+		actions = append(actions, subsystemsSuggest)
+	}
+	if args.TrafficHistory != "" {
+		actions = append(actions, subsystemsTraffic)
+	}
+
+	switch len(actions) {
+	case 0:
+		// 单独使用 --cluster 时列出归属该集群的子系统 (配合 --update 时为目标集群)
+		if args.ClusterName != "" {
+			return subsystemsCluster, nil
+		}
+		return subsystemsList, nil
+	case 1:
+		return actions[0], nil
+	default:
+		return "", fmt.Errorf("--search、--check、--subsys-detail、--follow、--delete、--update、--disable、--suggest-target、--traffic-history 不能同时使用")
+	}
+}
+
+func cmdSubsystems(client *Client, args *CommandLineArgs) error {
+	action, err := resolveSubsystemsAction(args)
+	if err != nil {
+		return err
+	}
+	ctx := args.Context()
+
+	switch action {
+	case subsystemsFollow:
+		return followSubsystems(client, args)
+	case subsystemsDelete:
+		if err := client.DeleteSubsystem(ctx, args.DeleteID); err != nil {
+			return err
+		}
+		fmt.Println(`{"code": 0, "message": "子系统删除成功"}`)
+		return nil
+	case subsystemsUpdate:
+		req := newUpdateSubsystemRequest(args)
+		if req.Empty() {
+			return fmt.Errorf("--update 需要至少指定 --traffic、--cluster、--keywords、--whitelist 之一")
+		}
+		if err := client.UpdateSubsystem(ctx, args.UpdateID, req); err != nil {
+			return err
+		}
+		fmt.Println(`{"code": 0, "message": "子系统修改成功"}`)
+		return nil
+	case subsystemsDisable:
+		if err := client.DisableSubsystem(ctx, args.DisableID); err != nil {
+			return err
+		}
+		fmt.Println(`{"code": 0, "message": "子系统停用成功"}`)
+		return nil
+	}
+
+	var result interface{}
+	switch action {
+	case subsystemsSearch:
+		result, err = client.SearchSubsystems(ctx, newSearchSubsystemsRequest(args))
+	case subsystemsCluster:
+		result, err = client.GetSubsystemsByCluster(ctx, args.ClusterName)
+	case subsystemsCheck:
 		result, err = client.CheckSubsystemExists(ctx, args.Check)
-	} else if args.Detail != "" {
-		result, err = client.GetSubsystemDetail(ctx, args.Detail)
-	} else {
+	case subsystemsDetail:
+		result, err = client.GetSubsystemDetail(ctx, args.DetailID)
+	case subsystemsSuggest:
+		result, err = client.SuggestTargetClusters(ctx, args.SuggestTarget)
+	case subsystemsTraffic:
+		result, err = getTrafficHistory(client, args)
+	default:
+		// 指定 --offset 或 --limit 时分页获取
+		if args.Offset > 0 || args.limitSet {
+			return listSubsystemsPage(client, args)
+		}
 		result, err = client.GetSubsystems(ctx)
 	}
 
@@ -153,16 +513,240 @@ func cmdSubsystems(client *Client, args *CommandLineArgs) error {
 		return err
 	}
 
-	output, _ := json.MarshalIndent(result, "", "  ")
-	fmt.Println(string(output))
+	return printResult(args, result)
+}
+
+// parseTimeArg 解析时间参数: RFC3339 时间,或距 now 的时长 (如 24h 表示 24 小时前); 为空时返回 now
+func parseTimeArg(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return now, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	ago, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无法解析时间 %q (应为 RFC3339 或时长,如 24h)", value)
+	}
+	return now.Add(-ago), nil
+}
+
+// getTrafficHistory 按 --from / --to / --granularity 查询子系统流量时间序列
+func getTrafficHistory(client *Client, args *CommandLineArgs) ([]TrafficPoint, error) {
+	now := time.Now()
+	from, err := parseTimeArg(args.From, now)
+	if err != nil {
+		return nil, fmt.Errorf("--from: %w", err)
+	}
+	to, err := parseTimeArg(args.To, now)
+	if err != nil {
+		return nil, fmt.Errorf("--to: %w", err)
+	}
+	return client.GetSubsystemTraffic(args.Context(), args.TrafficHistory, from, to, args.Granularity)
+}
+
+// newUpdateSubsystemRequest 根据命令行参数构造修改请求,未指定的参数不修改
+func newUpdateSubsystemRequest(args *CommandLineArgs) *UpdateSubsystemRequest {
+	req := &UpdateSubsystemRequest{
+		Cluster:           args.ClusterName,
+		KeywordFilters:    splitList(args.Keywords),
+		ScanFileWhitelist: splitList(args.Whitelist),
+	}
+	if args.Traffic >= 0 {
+		traffic := args.Traffic
+		req.Traffic = &traffic
+	}
+	return req
+}
+
+// splitList 解析逗号分隔的参数,去除空白及空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newSubsystems 返回不在 seen 中的子系统,并将其加入 seen
+func newSubsystems(seen map[string]bool, subsystems []SubSystem) []SubSystem {
+	var added []SubSystem
+	for _, s := range subsystems {
+		if !seen[s.SubsysID] {
+			seen[s.SubsysID] = true
+			added = append(added, s)
+		}
+	}
+	return added
+}
+
+// followSubsystems 按 --interval 轮询子系统列表,只输出上次轮询后新出现的子系统,收到中断信号后退出
+// 首次轮询的结果作为基线,不输出
+func followSubsystems(client *Client, args *CommandLineArgs) error {
+	if args.Interval <= 0 {
+		return fmt.Errorf("--interval 必须大于 0")
+	}
+
+	// 轮询随命令 context 结束: 收到中断信号或超过 --timeout 时停止; 输出失败时也提前停止
+	ctx, stop := context.WithCancel(args.Context())
+	defer stop()
+
+	var seen map[string]bool
+	var printErr error
+	recordLoop(ctx, client.clock, args.Interval, func(now time.Time) {
+		subsystems, err := client.GetSubsystems(ctx)
+		if err != nil {
+			logger.Printf("获取子系统列表失败,等待下次轮询: %v", err)
+			return
+		}
+		if seen == nil {
+			seen = make(map[string]bool, len(subsystems))
+			newSubsystems(seen, subsystems)
+			logger.Printf("已记录 %d 个子系统,开始监听新增 (间隔 %s)", len(seen), args.Interval)
+			return
+		}
+
+		for _, s := range newSubsystems(seen, subsystems) {
+			if args.tmpl != nil {
+				err = printResult(args, s)
+			} else {
+				_, err = fmt.Printf("%s\t%s\t%s\t%s\n", now.Format(time.RFC3339), s.SubsysID, s.SubsysName, s.DevDept)
+			}
+			if err != nil {
+				printErr = err
+				stop()
+				return
+			}
+		}
+	})
+
+	return printErr
+}
+
+// filterNodes 按集群和角色过滤节点,空值表示不过滤
+func filterNodes(nodes []LogStoreInstance, cluster, role string) []LogStoreInstance {
+	filtered := make([]LogStoreInstance, 0, len(nodes))
+	for _, node := range nodes {
+		if cluster != "" && node.ClusterName != cluster {
+			continue
+		}
+		if role != "" && node.Role != role {
+			continue
+		}
+		filtered = append(filtered, node)
+	}
+	return filtered
+}
+
+func cmdNodes(client *Client, args *CommandLineArgs) error {
+	ctx := args.Context()
+
+	var nodes []LogStoreInstance
+	var err error
+	if args.ClusterName != "" {
+		nodes, err = client.GetClusterNodes(ctx, args.ClusterName)
+	} else {
+		nodes, err = client.ListAllNodes(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 未指定 -o 时默认以表格输出
+	if args.Output == "" {
+		args.Output = outputTable
+	}
+	return printResult(args, filterNodes(nodes, args.ClusterName, args.Role))
+}
+
+// readNodeSpec 从 r 读取单个 AddClusterNodeRequest JSON 对象,不允许未知字段,避免拼写错误的字段被静默忽略
+func readNodeSpec(r io.Reader) (*AddClusterNodeRequest, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var node AddClusterNodeRequest
+	if err := decoder.Decode(&node); err != nil {
+		return nil, fmt.Errorf("解析标准输入中的节点 JSON 失败: %w", err)
+	}
+	return &node, nil
+}
+
+// applyNodeFlags 用命令行中非空的节点参数覆盖 node 的对应字段
+func applyNodeFlags(node *AddClusterNodeRequest, args *CommandLineArgs) {
+	for _, field := range []struct {
+		dst *string
+		val string
+	}{
+		{&node.ClusterName, args.ClusterName},
+		{&node.Address, args.Address},
+		{&node.Role, args.Role},
+		{&node.CpuLimit, args.CpuLimit},
+		{&node.MemLimit, args.MemLimit},
+		{&node.Topic, args.Topic},
+		{&node.BucketNames, args.BucketNames},
+		{&node.BackendDomain, args.BackendDomain},
+		{&node.StorageDomain, args.StorageDomain},
+		{&node.Status, args.Status},
+	} {
+		if field.val != "" {
+			*field.dst = field.val
+		}
+	}
+}
+
+// validateNodeSpec 检查添加节点的必填字段
+func validateNodeSpec(node *AddClusterNodeRequest) error {
+	var missing []string
+	if node.ClusterName == "" {
+		missing = append(missing, "clustername (--cluster-name)")
+	}
+	if node.Address == "" {
+		missing = append(missing, "address (--address)")
+	}
+	if node.Role == "" {
+		missing = append(missing, "role (--role)")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("添加节点缺少必填字段: %s", strings.Join(missing, ", "))
+	}
 	return nil
 }
 
 func cmdAddNode(client *Client, args *CommandLineArgs) error {
-	ctx := context.Background()
+	ctx := args.Context()
+
+	node := &AddClusterNodeRequest{}
+	if args.Stdin || (len(args.Positional) > 0 && args.Positional[0] == "-") {
+		var err error
+		if node, err = readNodeSpec(os.Stdin); err != nil {
+			return err
+		}
+	}
+	applyNodeFlags(node, args)
+	if err := validateNodeSpec(node); err != nil {
+		return err
+	}
 
-	node := &AddClusterNodeRequest{
-		Address:       args.Address,
+	err := client.AddClusterNode(ctx, node.ClusterName, node)
+	if err != nil {
+		if args.IfNotExists && errors.Is(err, ErrNodeExists) {
+			fmt.Println(`{"code": 0, "message": "节点已存在,跳过添加"}`)
+			return nil
+		}
+		return err
+	}
+
+	fmt.Println(`{"code": 0, "message": "节点添加成功"}`)
+	return nil
+}
+
+func cmdUpdateNode(client *Client, args *CommandLineArgs) error {
+	if args.ClusterName == "" || args.Address == "" {
+		return fmt.Errorf("请使用 --cluster-name 和 --address 指定要修改的节点")
+	}
+
+	req := &UpdateClusterNodeRequest{
 		Role:          args.Role,
 		CpuLimit:      args.CpuLimit,
 		MemLimit:      args.MemLimit,
@@ -172,31 +756,52 @@ func cmdAddNode(client *Client, args *CommandLineArgs) error {
 		StorageDomain: args.StorageDomain,
 		Status:        args.Status,
 	}
+	if req.Empty() {
+		return fmt.Errorf("update-node 需要至少指定 --role、--cpulimit、--memlimit、--topic、--bucketnames、--backenddomain、--storagedomain、--status 之一")
+	}
 
-	err := client.AddClusterNode(ctx, args.ClusterName, node)
-	if err != nil {
+	if err := client.UpdateClusterNode(args.Context(), args.ClusterName, args.Address, req); err != nil {
 		return err
 	}
 
-	fmt.Println(`{"code": 0, "message": "节点添加成功"}`)
+	fmt.Println(`{"code": 0, "message": "节点修改成功"}`)
 	return nil
 }
 
 func cmdDeleteNode(client *Client, args *CommandLineArgs) error {
-	ctx := context.Background()
+	ctx := args.Context()
 
 	// 从 args 中获取 IP
 	ip := ""
-	if len(flag.Args()) > 1 {
-		ip = flag.Args()[1]
+	if len(args.Positional) > 0 {
+		ip = args.Positional[0]
 	}
 
 	if ip == "" {
 		return fmt.Errorf("请指定节点IP地址")
 	}
 
-	err := client.DeleteClusterNode(ctx, ip)
-	if err != nil {
+	// 删除前确认节点存在,避免 IP 写错时误删或无提示地删除失败; --force 跳过
+	target := ip
+	if !args.Force {
+		node, err := client.LookupClusterNode(ctx, args.ClusterName, ip)
+		if err != nil {
+			return err
+		}
+		target = fmt.Sprintf("%s (集群 %s, 角色 %s)", ip, node.ClusterName, node.Role)
+	}
+
+	if !args.Yes && !args.DryRun {
+		ok, err := confirmPrompt(os.Stdin, os.Stderr, fmt.Sprintf("确认删除节点 %s? [y/N] ", target))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("已取消删除节点 %s", ip)
+		}
+	}
+
+	if err := client.DeleteClusterNode(ctx, ip); err != nil {
 		return err
 	}
 
@@ -204,11 +809,115 @@ func cmdDeleteNode(client *Client, args *CommandLineArgs) error {
 	return nil
 }
 
+// confirmPrompt 向 w 输出提示并从 r 读取一行,仅 y/yes (不区分大小写) 视为确认
+// 读到 EOF (如非交互环境) 时视为未确认
+func confirmPrompt(r io.Reader, w io.Writer, prompt string) (bool, error) {
+	fmt.Fprint(w, prompt)
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("读取确认输入失败: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// configFileCommand 返回不需要加载配置的 config 子命令 (init / doctor / lint),其他命令返回 nil
+func configFileCommand(args *CommandLineArgs) func() error {
+	if args.Command != "config" || len(args.Positional) == 0 {
+		return nil
+	}
+	switch args.Positional[0] {
+	case "init":
+		return func() error { return cmdConfigInit(args.InitPath, args.Force) }
+	case "doctor":
+		return func() error { return cmdConfigDoctor(args.ConfigPath) }
+	case "lint":
+		return func() error { return cmdConfigLint(args.ConfigPath) }
+	}
+	return nil
+}
+
+func cmdConfig(client *Client, args *CommandLineArgs) error {
+	sub := ""
+	if len(args.Positional) > 0 {
+		sub = args.Positional[0]
+	}
+
+	switch sub {
+	case "show":
+		if args.Effective {
+			return printEffectiveConfig(client.EffectiveConfig())
+		}
+		return printConfigFile(args.ConfigPath)
+	default:
+		return fmt.Errorf("未知 config 子命令: %q (可用: init, show, doctor, lint)", sub)
+	}
+}
+
+// printEffectiveConfig 逐字段输出生效配置
+func printEffectiveConfig(config Config) error {
+	v := reflect.ValueOf(config)
+	rows := make([][]string, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		// 回调类字段 (如 RetryableStatus) 无法在配置文件中设置,不显示
+		if v.Field(i).Kind() == reflect.Func {
+			continue
+		}
+		rows = append(rows, []string{v.Type().Field(i).Name, fmt.Sprint(v.Field(i).Interface())})
+	}
+	return renderTable(os.Stdout, []string{"字段", "值"}, rows, 0)
+}
+
+// printConfigFile 输出配置文件内容 (密码已脱敏)
+func printConfigFile(configPath string) error {
+	if configPath == "" {
+		defaultPath, err := defaultConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath = defaultPath
+	}
+
+	configFile, err := readConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	for name, env := range configFile.Environments {
+		env.Password = redactSecret(env.Password)
+		env.Token = redactSecret(env.Token)
+		env.APIKey = redactSecret(env.APIKey)
+		env.SigningSecret = redactSecret(env.SigningSecret)
+		configFile.Environments[name] = env
+	}
+
+	output, err := yaml.Marshal(configFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("# %s\n%s", configPath, output)
+	return nil
+}
+
 // ==================== 主函数 ====================
 
+// exitMaintenance 服务端维护中的退出码,便于脚本区分维护与其他错误 (其他错误退出码为 1)
+const exitMaintenance = 3
+
+// exitInterrupted 命令被 SIGINT/SIGTERM 中断时的退出码 (同 shell 对 SIGINT 的约定 128+2)
+const exitInterrupted = 130
+
 func main() {
 	args := parseArgs()
 
+	// 版本信息不需要配置及服务端
+	if args.Version || args.Command == "version" {
+		printVersion(os.Stdout)
+		return
+	}
+
 	// 如果没有指定命令,显示帮助
 	if args.Command == "" {
 		fmt.Println("WEAPM-LOGSERVER API 客户端命令行工具")
@@ -216,44 +925,108 @@ func main() {
 		fmt.Println("  weapm_cli <命令> [参数]")
 		fmt.Println("\n可用命令:")
 		fmt.Println("  dashboard    获取数据大盘信息")
-		fmt.Println("  clusters     集群管理")
-		fmt.Println("  subsystems   子系统管理")
+		fmt.Println("  status       一屏状态总览: 连通性、集群/子系统数、容量告警、不健康节点 (--format json)")
+		fmt.Println("  clusters     集群管理 (--default 显示默认集群)")
+		fmt.Println("  subsystems   子系统管理 (--follow --interval 15s 监听新增子系统)")
 		fmt.Println("  add-node     添加集群节点")
-		fmt.Println("  delete-node  删除集群节点")
+		fmt.Println("  update-node  修改集群节点的角色/资源限制等 (--cluster-name X --address IP --cpulimit 16 ...)")
+		fmt.Println("  delete-node  删除集群节点 (IP [--cluster-name X] [--yes] [--force])")
+		fmt.Println("  delete-nodes 批量删除集群节点 (--cluster X --all|--file ips.txt --confirm [--dry-run])")
+		fmt.Println("  add-nodes    批量添加集群节点 (--cluster X --from-file nodes.json [--concurrency N])")
+		fmt.Println("  details      批量查询子系统详情 (--file ids.txt [--concurrency N] [-o table])")
+		fmt.Println("  nodes        列出所有集群的节点 (--cluster / --role 过滤)")
+		fmt.Println("  reconcile    按期望状态目录持续同步集群节点")
+		fmt.Println("  integrity-check  校验子系统与集群引用一致性")
+		fmt.Println("  check-owners     检查缺少负责人的子系统 (--field)")
+		fmt.Println("  check-node-limits  按策略文件检查节点 CPU/内存限制 (--policy FILE)")
+		fmt.Println("  check-default-cluster  检查默认集群唯一、存在且节点健康")
+		fmt.Println("  check-backlog  检查 Topic 积压超过阈值的集群及其纳管子系统 (--threshold N)")
+		fmt.Println("  report departments  按部门汇总子系统数与流量 (--format table|csv|json)")
+		fmt.Println("  report health  集群健康报告: 用量/节点/峰值、不健康节点、Top 子系统 (--format md|json, report --format md 同此)")
+		fmt.Println("  cost-report  按集群估算月度存储成本 (--cost-per-gb 0.12)")
+		fmt.Println("  config init  生成带注释的配置文件模板 (--path 指定路径, --force 覆盖已有文件)")
+		fmt.Println("  config show  显示配置 (--effective 显示实际生效配置)")
+		fmt.Println("  config doctor  检查配置文件权限及默认密码")
+		fmt.Println("  config lint  检查配置文件中的常见错误 (生产默认密码、明文 HTTP 等)")
+		fmt.Println("  export-subsystems  导出子系统清单 (--format xlsx|csv --out FILE [--redact FIELDS])")
+		fmt.Println("  bulk-adjust-cluster  按 CSV 批量调整子系统归属集群 (--file FILE)")
+		fmt.Println("  serve        以服务模式运行,提供 /healthz 和 /metrics (--addr :8080)")
+		fmt.Println("  record       按间隔记录数据大盘快照为 JSONL (--interval 1m --out FILE)")
+		fmt.Println("  record-traffic  按间隔记录每个子系统的实际流量为 JSONL (--interval 1m --out FILE [--concurrency N])")
+		fmt.Println("  schema --validate  自检请求/响应类型的 json 标签及往返序列化")
+		fmt.Println("  version      显示版本、提交及构建时间 (同 --version)")
 		fmt.Println("\n示例:")
 		fmt.Println("  ./weapm_cli dashboard")
 		fmt.Println("  ./weapm_cli clusters")
 		fmt.Println("  ./weapm_cli clusters --detail --cluster-name LOG001")
+		fmt.Println("  ./weapm_cli clusters --cluster-name LOG001 --subsystems --sort traffic --limit 50")
 		fmt.Println("  ./weapm_cli subsystems")
 		fmt.Println("  ./weapm_cli subsystems --search --subsys-id SYS001")
+		fmt.Println("  ./weapm_cli subsystems --search --dept 交易研发部 --owner zhangsan --state enabled")
+		fmt.Println("  ./weapm_cli subsystems --cluster LOG001")
+		fmt.Println("  ./weapm_cli subsystems --subsys-detail SYS001")
+		fmt.Println("  ./weapm_cli subsystems --update SYS001 --traffic 2048 --cluster LOG002")
+		fmt.Println("  ./weapm_cli subsystems --delete SYS001")
+		fmt.Println("  ./weapm_cli subsystems --disable SYS001")
+		fmt.Println("  ./weapm_cli subsystems --suggest-target SYS001")
+		fmt.Println("  ./weapm_cli subsystems --traffic-history SYS001 --from 168h --granularity hour")
 		fmt.Println("  ./weapm_cli add-node --cluster-name LOG008 --address 127.0.0.2 --role write")
+		fmt.Println("  ./weapm_cli reconcile --dir ./desired --interval 1m --dry-run")
 		fmt.Println("\n使用 --help 查看详细帮助")
 		os.Exit(0)
 	}
 
+	// 校验输出模板,避免请求完成后才发现模板错误
+	tmpl, err := parseOutputTemplate(args.Template, args.TemplateFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	args.tmpl = tmpl
+	if err := validateOutput(args.Output); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
 	// 配置日志
 	if args.Quiet {
 		log.SetOutput(os.NewFile(0, os.DevNull))
 	}
 
+	// 自检不需要连接服务端
+	if args.Command == "schema" {
+		if err := cmdSchema(args); err != nil {
+			log.Fatalf("❌ 错误: %v", err)
+		}
+		return
+	}
+
+	// 生成配置文件时配置尚不存在; doctor / lint 检查的正是配置文件本身,
+	// 须在加载之前执行,否则 active_env 指向不存在的环境等问题会先使加载失败
+	if run := configFileCommand(args); run != nil {
+		if err := run(); err != nil {
+			log.Fatalf("❌ 错误: %v", err)
+		}
+		return
+	}
+
 	// 加载配置
+	timer := newPhaseTimer(args.Timing)
+	configCacheEnabled = os.Getenv(EnvConfigCache) == "1" && !args.NoConfigCache
 	var config *Config
-	var err error
+	fromFile := true
 
-	if args.ConfigPath != "" || args.Env != "" {
-		config, err = LoadConfigFromYAML(args.ConfigPath, args.Env)
+	if args.Mock {
+		// 模拟服务端随进程退出,不读取配置文件
+		fromFile = false
+		srv := mockserver.NewMockServer()
+		defer srv.Close()
+		config = DefaultConfig(srv.URL)
+		logger.Printf("使用模拟服务端: %s", srv.URL)
+	} else if args.ConfigPath != "" || args.Env != "" || args.OverridePath != "" {
+		config, err = LoadConfigFromYAML(args.ConfigPath, args.Env, args.OverridePath)
 	} else if args.BaseURL != "" {
 		// 使用命令行参数创建配置
+		fromFile = false
 		config = DefaultConfig(args.BaseURL)
-		if args.Username != "" {
-			config.Username = args.Username
-		}
-		if args.Password != "" {
-			config.Password = args.Password
-		}
-		if args.Timeout != 30 {
-			config.Timeout = time.Duration(args.Timeout) * time.Second
-		}
 	} else {
 		// 默认使用配置文件
 		config, err = LoadConfigFromYAML("", "")
@@ -263,26 +1036,120 @@ func main() {
 		log.Fatalf("⚠️  %v\n请先创建配置文件 config.yaml,参考 config.yaml.example", err)
 	}
 
+	// --username/--password 对配置文件同样生效,环境变量优先于命令行参数
+	overrideCredentials(config, args.Username, args.Password)
+
+	// 凭据只允许来自环境变量 (避免出现在进程列表或文件中)
+	if args.CredsFromEnv {
+		if args.Username != "" || args.Password != "" {
+			log.Fatalf("❌ 错误: --creds-from-env 不能与 --username/--password 同时使用")
+		}
+		if err := credentialsFromEnv(config); err != nil {
+			log.Fatalf("❌ 错误: %v", err)
+		}
+	}
+
+	// 配置文件安全检查 (config 子命令由 config doctor 处理; 凭据来自环境变量时不检查文件中的密码)
+	if fromFile && args.Command != "config" && !args.CredsFromEnv {
+		configPath := args.ConfigPath
+		if configPath == "" {
+			configPath, _ = defaultConfigPath()
+		}
+		warnConfigSecurity(configPath)
+	}
+
+	timer.Mark(phaseConfig)
+
+	// --dry-run 对所有命令生效: 变更请求只输出请求计划,不发送
+	config.DryRun = args.DryRun
+
 	// 创建客户端
 	client := NewClient(config)
+	timer.Mark(phaseClient)
+
+	// 收到 SIGINT/SIGTERM 时取消命令的 context,进行中的请求和批量操作随之尽快返回
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	args.ctx = ctx
+
+	// 命令整体截止时间
+	if args.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(args.ctx, time.Duration(args.Timeout)*time.Second)
+		defer cancel()
+		args.ctx = ctx
+	}
 
 	// 执行命令
 	var cmdErr error
 	switch args.Command {
 	case "dashboard":
-		cmdErr = cmdDashboard(client)
+		cmdErr = cmdDashboard(client, args)
+	case "status":
+		cmdErr = cmdStatus(client, args)
 	case "clusters":
 		cmdErr = cmdClusters(client, args)
 	case "subsystems":
 		cmdErr = cmdSubsystems(client, args)
 	case "add-node":
 		cmdErr = cmdAddNode(client, args)
+	case "update-node":
+		cmdErr = cmdUpdateNode(client, args)
 	case "delete-node":
 		cmdErr = cmdDeleteNode(client, args)
+	case "delete-nodes":
+		cmdErr = cmdDeleteNodes(client, args)
+	case "add-nodes":
+		cmdErr = cmdAddNodes(client, args)
+	case "details":
+		cmdErr = cmdDetails(client, args)
+	case "nodes":
+		cmdErr = cmdNodes(client, args)
+	case "reconcile":
+		cmdErr = cmdReconcile(client, args)
+	case "integrity-check":
+		cmdErr = cmdIntegrityCheck(client, args)
+	case "check-owners":
+		cmdErr = cmdCheckOwners(client, args)
+	case "report":
+		cmdErr = cmdReport(client, args)
+	case "cost-report":
+		cmdErr = cmdCostReport(client, args)
+	case "check-node-limits":
+		cmdErr = cmdCheckNodeLimits(client, args)
+	case "check-default-cluster":
+		cmdErr = cmdCheckDefaultCluster(client, args)
+	case "check-backlog":
+		cmdErr = cmdCheckBacklog(client, args)
+	case "config":
+		cmdErr = cmdConfig(client, args)
+	case "export-subsystems":
+		cmdErr = cmdExportSubsystems(client, args)
+	case "bulk-adjust-cluster":
+		cmdErr = cmdBulkAdjustCluster(client, args)
+	case "serve":
+		cmdErr = cmdServe(client, args)
+	case "record":
+		cmdErr = cmdRecord(client, args)
+	case "record-traffic":
+		cmdErr = cmdRecordTraffic(client, args)
 	default:
 		log.Fatalf("❌ 未知命令: %s", args.Command)
 	}
 
+	timer.recordCommand(client)
+	timer.Report(os.Stderr)
+
+	if errors.Is(cmdErr, context.Canceled) && ctx.Err() != nil {
+		stop()
+		fmt.Fprintln(os.Stderr, "⏹  已中断")
+		os.Exit(exitInterrupted)
+	}
+
+	var maintenance *MaintenanceError
+	if errors.As(cmdErr, &maintenance) {
+		fmt.Fprintf(os.Stderr, "⏸  %v,请稍后再试\n", maintenance)
+		os.Exit(exitMaintenance)
+	}
 	if cmdErr != nil {
 		log.Fatalf("❌ 错误: %v", cmdErr)
 	}
@@ -0,0 +1,844 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Dreamshe-92/skill/script/weapm/mockserver"
+)
+
+// ==================== 节点过滤 ====================
+
+func TestFilterNodes(t *testing.T) {
+	nodes := []LogStoreInstance{
+		{ClusterName: "LOG001", Address: "10.0.0.1", Role: "master"},
+		{ClusterName: "LOG001", Address: "10.0.0.2", Role: "write"},
+		{ClusterName: "LOG002", Address: "10.0.1.1", Role: "write"},
+	}
+	tests := []struct {
+		cluster, role string
+		want          []string
+	}{
+		{"", "", []string{"10.0.0.1", "10.0.0.2", "10.0.1.1"}},
+		{"LOG001", "", []string{"10.0.0.1", "10.0.0.2"}},
+		{"", "write", []string{"10.0.0.2", "10.0.1.1"}},
+		{"LOG002", "master", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		if filtered := filterNodes(nodes, tt.cluster, tt.role); filtered == nil {
+			t.Errorf("filterNodes(%q, %q) = nil, want an empty slice so JSON output is []", tt.cluster, tt.role)
+		}
+		for _, node := range filterNodes(nodes, tt.cluster, tt.role) {
+			got = append(got, node.Address)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterNodes(%q, %q) = %v, want %v", tt.cluster, tt.role, got, tt.want)
+		}
+	}
+}
+
+// ==================== 新增子系统监听 ====================
+
+// captureStdout 在 fn 执行期间捕获标准输出
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestNewSubsystems(t *testing.T) {
+	seen := map[string]bool{"SYS001": true}
+	added := newSubsystems(seen, []SubSystem{{SubsysID: "SYS001"}, {SubsysID: "SYS002"}, {SubsysID: "SYS003"}})
+	if len(added) != 2 || added[0].SubsysID != "SYS002" || added[1].SubsysID != "SYS003" {
+		t.Errorf("added = %+v, want SYS002 and SYS003", added)
+	}
+	if !seen["SYS002"] || !seen["SYS003"] {
+		t.Errorf("seen = %v, want the new IDs recorded", seen)
+	}
+	if again := newSubsystems(seen, []SubSystem{{SubsysID: "SYS002"}}); len(again) != 0 {
+		t.Errorf("second call returned %+v, want nothing new", again)
+	}
+}
+
+func TestFollowSubsystemsPrintsOnlyNew(t *testing.T) {
+	var polls atomic.Int32
+	api := newFakeAPI()
+	api.handle("GET /operation/subsystems", func(w http.ResponseWriter, r *http.Request) {
+		subsystems := []SubSystem{{SubsysID: "SYS001", SubsysName: "payment"}}
+		if polls.Add(1) > 1 {
+			subsystems = append(subsystems, SubSystem{SubsysID: "SYS002", SubsysName: "order"})
+		}
+		respondResult(w, subsystems)
+	})
+	client := newTestClient(t, api)
+	clock := NewFakeClock(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	client.SetClock(clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	args := &CommandLineArgs{Interval: time.Minute, ctx: ctx}
+
+	var err error
+	output := captureStdout(t, func() {
+		done := make(chan struct{})
+		go func() {
+			err = followSubsystems(client, args)
+			close(done)
+		}()
+		// 基线轮询后推进一个间隔,第二次轮询完成后停止
+		for clock.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(time.Minute)
+		for polls.Load() < 2 || clock.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		<-done
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output, "SYS001") {
+		t.Errorf("output = %q, baseline subsystems should not be printed", output)
+	}
+	if strings.Count(output, "SYS002\torder") != 1 {
+		t.Errorf("output = %q, want SYS002 printed once", output)
+	}
+}
+
+// ==================== 子系统操作选择 ====================
+
+func TestResolveSubsystemsAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    CommandLineArgs
+		want    string
+		wantErr bool
+	}{
+		{"default lists", CommandLineArgs{}, subsystemsList, false},
+		{"search", CommandLineArgs{Search: true}, subsystemsSearch, false},
+		{"check", CommandLineArgs{Check: "SYS001"}, subsystemsCheck, false},
+		{"detail", CommandLineArgs{DetailID: "SYS001"}, subsystemsDetail, false},
+		{"follow", CommandLineArgs{Follow: true}, subsystemsFollow, false},
+		{"traffic history", CommandLineArgs{TrafficHistory: "SYS001"}, subsystemsTraffic, false},
+		// 单独使用 --cluster 时列出该集群的子系统,配合其他操作时不改变操作
+		{"cluster", CommandLineArgs{ClusterName: "LOG001"}, subsystemsCluster, false},
+		{"search in cluster", CommandLineArgs{Search: true, ClusterName: "LOG001"}, subsystemsSearch, false},
+		// --detail 是集群列表的布尔参数,不影响子系统操作
+		{"bool detail ignored", CommandLineArgs{Detail: true}, subsystemsList, false},
+		{"search and detail", CommandLineArgs{Search: true, DetailID: "SYS001"}, "", true},
+		{"check and follow", CommandLineArgs{Check: "SYS001", Follow: true}, "", true},
+		{"detail and traffic history", CommandLineArgs{DetailID: "SYS001", TrafficHistory: "SYS001"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSubsystemsAction(&tt.args)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("resolveSubsystemsAction() = %q, %v, want %q (error: %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCmdSubsystemsDetail(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/subsystem/SYS001", resultHandler(`{"subsystemInfo":{"subsys_id":"SYS001"},"clusterName":"LOG001"}`))
+	client := newTestClient(t, api)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = cmdSubsystems(client, &CommandLineArgs{DetailID: "SYS001", Output: "json"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "LOG001") {
+		t.Errorf("output = %q, want the subsystem detail", output)
+	}
+	if got := api.requests(); len(got) != 1 || got[0] != "GET /operation/subsystem/SYS001" {
+		t.Errorf("requests = %v, want only the detail request", got)
+	}
+}
+
+func TestNewUpdateSubsystemRequest(t *testing.T) {
+	req := newUpdateSubsystemRequest(&CommandLineArgs{Traffic: -1, Keywords: " error, ,fatal ", Whitelist: ""})
+	if req.Traffic != nil {
+		t.Errorf("Traffic = %d, want nil for a negative flag value", *req.Traffic)
+	}
+	if !reflect.DeepEqual(req.KeywordFilters, []string{"error", "fatal"}) || req.ScanFileWhitelist != nil {
+		t.Errorf("req = %+v, want trimmed keywords and no whitelist", req)
+	}
+
+	req = newUpdateSubsystemRequest(&CommandLineArgs{Traffic: 0})
+	if req.Traffic == nil || *req.Traffic != 0 || req.Empty() {
+		t.Errorf("req = %+v, want traffic 0 set", req)
+	}
+	if !newUpdateSubsystemRequest(&CommandLineArgs{Traffic: -1}).Empty() {
+		t.Error("request without flags is not Empty")
+	}
+}
+
+func TestCmdSubsystemsDisable(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("PUT /operation/subsystem/SYS001/disable", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+	client := newTestClient(t, api)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = cmdSubsystems(client, &CommandLineArgs{DisableID: "SYS001", Traffic: -1})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "子系统停用成功") {
+		t.Errorf("output = %q, want the success message", output)
+	}
+	if got := api.requests(); len(got) != 1 || got[0] != "PUT /operation/subsystem/SYS001/disable" {
+		t.Errorf("requests = %v, want one disable request", got)
+	}
+
+	// 与其他操作互斥,不发送请求
+	if err := cmdSubsystems(client, &CommandLineArgs{DisableID: "SYS001", DeleteID: "SYS001"}); err == nil {
+		t.Error("--disable with --delete: error = nil, want a conflict error")
+	}
+	if n := len(api.requests()); n != 1 {
+		t.Errorf("requests = %d, want no request for conflicting flags", n)
+	}
+}
+
+func TestCommandLineArgsContext(t *testing.T) {
+	if ctx := (&CommandLineArgs{}).Context(); ctx != context.Background() {
+		t.Errorf("Context() = %v, want context.Background() when unset", ctx)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if got := (&CommandLineArgs{ctx: ctx}).Context(); got != ctx {
+		t.Errorf("Context() = %v, want the command context", got)
+	}
+}
+
+// ==================== 默认集群 ====================
+
+func TestCmdClustersDefault(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{{ClusterName: "LOG001"}, {ClusterName: "LOG002", IsDefault: 1}})
+	})
+	api.handle("GET /operation/clusters/LOG002", clusterWithNodes("LOG002"))
+	client := newTestClient(t, api)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = cmdClusters(client, &CommandLineArgs{Default: true, Output: "csv"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "CLUSTER,DEFAULT,TOPIC,BACKEND_DOMAIN,STORAGE_DOMAIN\nLOG002,yes,,,\n"; output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+
+	// 与 --detail 同时使用时显示默认集群详情
+	captureStdout(t, func() {
+		err = cmdClusters(client, &CommandLineArgs{Default: true, Detail: true, Output: "json"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := api.count("GET /operation/clusters/LOG002"); got != 1 {
+		t.Errorf("requests = %v, want the LOG002 detail", api.requests())
+	}
+}
+
+func TestCmdClustersDefaultWithClusterName(t *testing.T) {
+	api := newFakeAPI()
+	err := cmdClusters(newTestClient(t, api), &CommandLineArgs{Default: true, ClusterName: "LOG001"})
+	if err == nil || !strings.Contains(err.Error(), "不能同时使用") {
+		t.Errorf("error = %v, want a conflict error", err)
+	}
+	if len(api.requests()) != 0 {
+		t.Errorf("requests = %v, want none", api.requests())
+	}
+}
+
+// ==================== 修改节点 ====================
+
+func TestCmdUpdateNode(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("PUT /operation/clusters/LOG001/nodes/10.0.0.1", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+	client := newTestClient(t, api)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = cmdUpdateNode(client, &CommandLineArgs{ClusterName: "LOG001", Address: "10.0.0.1", Role: "master"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "节点修改成功") {
+		t.Errorf("output = %q, want the success message", output)
+	}
+
+	// 参数不完整时不发送请求
+	for _, args := range []*CommandLineArgs{
+		{Address: "10.0.0.1", Role: "master"},
+		{ClusterName: "LOG001", Role: "master"},
+		{ClusterName: "LOG001", Address: "10.0.0.1"},
+	} {
+		if err := cmdUpdateNode(client, args); err == nil {
+			t.Errorf("cmdUpdateNode(%+v) error = nil, want an error", args)
+		}
+	}
+	if n := len(api.requests()); n != 1 {
+		t.Errorf("requests = %v, want only the valid update", api.requests())
+	}
+}
+
+// ==================== 流量时间序列 ====================
+
+func TestParseTimeArg(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", now, false},
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"2026-01-14T08:00:00+08:00", time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeArg(tt.value, now)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("parseTimeArg(%q) = %v, %v, want %v (error: %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCmdSubsystemsTrafficHistory(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/subsystem/SYS001/traffic", resultHandler(`[{"timestamp":"2026-01-15T00:00:00Z","traffic":100}]`))
+	client := newTestClient(t, api)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = cmdSubsystems(client, &CommandLineArgs{TrafficHistory: "SYS001", From: "2026-01-15T00:00:00Z", To: "2026-01-15T01:00:00Z", Traffic: -1, Output: "csv"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "TIMESTAMP,TRAFFIC\n2026-01-15T00:00:00Z,100\n"; output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+
+	if err := cmdSubsystems(client, &CommandLineArgs{TrafficHistory: "SYS001", From: "soon", Traffic: -1}); err == nil || !strings.Contains(err.Error(), "--from") {
+		t.Errorf("error = %v, want a --from parse error", err)
+	}
+}
+
+func TestNewSearchSubsystemsRequest(t *testing.T) {
+	req := newSearchSubsystemsRequest(&CommandLineArgs{ClusterName: "LOG001", Limit: 10})
+	if req.SubsysID != nil || req.DevDept != nil || req.BusinessOwner != nil || req.State != nil {
+		t.Errorf("req = %+v, want no filters when flags are omitted", req)
+	}
+	if req.Cluster != "LOG001" || req.Limit != 10 {
+		t.Errorf("req = %+v, want cluster LOG001 and limit 10", req)
+	}
+
+	req = newSearchSubsystemsRequest(&CommandLineArgs{SubsysID: "SYS001", Dept: "交易研发部", Owner: "zhangsan", State: "enabled"})
+	if req.SubsysID == nil || *req.SubsysID != "SYS001" || req.DevDept == nil || *req.DevDept != "交易研发部" ||
+		req.BusinessOwner == nil || *req.BusinessOwner != "zhangsan" || req.State == nil || *req.State != "enabled" {
+		t.Errorf("req = %+v, want every flag set as a filter", req)
+	}
+}
+
+func TestCmdSubsystemsSearchFilters(t *testing.T) {
+	client := newTestClient(t, mockserver.Handler())
+
+	tests := []struct {
+		name string
+		args CommandLineArgs
+		want []string
+	}{
+		{"dept", CommandLineArgs{Dept: "交易研发部"}, []string{"SYS001", "SYS002"}},
+		{"owner and state", CommandLineArgs{Owner: "zhouba", State: "disabled"}, []string{"SYS004"}},
+		{"no match", CommandLineArgs{Dept: "数据部", State: "enabled"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args.Search = true
+			tt.args.Traffic = -1
+			tt.args.Output = "json"
+			var err error
+			output := captureStdout(t, func() {
+				err = cmdSubsystems(client, &tt.args)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []SubSystem
+			if err := json.Unmarshal([]byte(output), &got); err != nil {
+				t.Fatalf("output %q is not a JSON list: %v", output, err)
+			}
+			var ids []string
+			for _, s := range got {
+				ids = append(ids, s.SubsysID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("subsystems = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+// ==================== 从标准输入添加节点 ====================
+
+// withStdin 在测试期间以 content 替换标准输入
+func withStdin(t *testing.T, content string) {
+	t.Helper()
+	path := writeConfig(t, "stdin.json", content)
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		f.Close()
+	})
+}
+
+func TestReadNodeSpec(t *testing.T) {
+	node, err := readNodeSpec(strings.NewReader(`{"clustername":"LOG001","address":"10.0.0.1","role":"write","cpulimit":"8"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.ClusterName != "LOG001" || node.Address != "10.0.0.1" || node.CpuLimit != "8" {
+		t.Errorf("node = %+v, want the decoded spec", node)
+	}
+
+	// 拼写错误的字段不会被静默忽略
+	for _, input := range []string{`{"address":"10.0.0.1","cpu_limit":"8"}`, `not json`, ``} {
+		if _, err := readNodeSpec(strings.NewReader(input)); err == nil {
+			t.Errorf("readNodeSpec(%q) error = nil, want an error", input)
+		}
+	}
+}
+
+func TestValidateNodeSpec(t *testing.T) {
+	if err := validateNodeSpec(&AddClusterNodeRequest{ClusterName: "LOG001", Address: "10.0.0.1", Role: "write"}); err != nil {
+		t.Errorf("complete spec: error = %v", err)
+	}
+	err := validateNodeSpec(&AddClusterNodeRequest{Address: "10.0.0.1"})
+	if err == nil || !strings.Contains(err.Error(), "clustername") || !strings.Contains(err.Error(), "role") || strings.Contains(err.Error(), "address") {
+		t.Errorf("error = %v, want clustername and role reported missing", err)
+	}
+}
+
+func TestCmdAddNodeStdin(t *testing.T) {
+	tests := []struct {
+		name string
+		args CommandLineArgs
+		want AddClusterNodeRequest
+	}{
+		{"stdin flag", CommandLineArgs{Stdin: true}, AddClusterNodeRequest{ClusterName: "LOG001", Address: "10.0.0.1", Role: "write", CpuLimit: "8"}},
+		// 命令行参数覆盖 JSON 中的字段
+		{"dash with overrides", CommandLineArgs{Positional: []string{"-"}, ClusterName: "LOG002", CpuLimit: "16"}, AddClusterNodeRequest{ClusterName: "LOG002", Address: "10.0.0.1", Role: "write", CpuLimit: "16"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got AddClusterNodeRequest
+			api := newFakeAPI()
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&got)
+				respondResult(w, nil)
+			}
+			api.handle("POST /operation/clusters/LOG001/nodes", handler)
+			api.handle("POST /operation/clusters/LOG002/nodes", handler)
+			withStdin(t, `{"clustername":"LOG001","address":"10.0.0.1","role":"write","cpulimit":"8"}`)
+
+			var err error
+			captureStdout(t, func() { err = cmdAddNode(newTestClient(t, api), &tt.args) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Address != tt.want.Address || got.Role != tt.want.Role || got.CpuLimit != tt.want.CpuLimit {
+				t.Errorf("request = %+v, want %+v", got, tt.want)
+			}
+			if n := api.count("POST /operation/clusters/" + tt.want.ClusterName + "/nodes"); n != 1 {
+				t.Errorf("requests = %v, want one for %s", api.requests(), tt.want.ClusterName)
+			}
+		})
+	}
+}
+
+func TestCmdAddNodeMissingFields(t *testing.T) {
+	api := newFakeAPI()
+	withStdin(t, `{"address":"10.0.0.1"}`)
+	err := cmdAddNode(newTestClient(t, api), &CommandLineArgs{Stdin: true, ClusterName: "LOG001"})
+	if err == nil || !strings.Contains(err.Error(), "role") {
+		t.Errorf("error = %v, want role reported missing", err)
+	}
+	if len(api.requests()) != 0 {
+		t.Errorf("requests = %v, want none", api.requests())
+	}
+}
+
+// ==================== 删除节点确认 ====================
+
+func TestConfirmPrompt(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{" YES \n", true},
+		{"yes", true}, // 无换行直接 EOF
+		{"n\n", false},
+		{"\n", false},
+		{"", false}, // 非交互环境
+		{"yess\n", false},
+	}
+	for _, tt := range tests {
+		var prompt bytes.Buffer
+		got, err := confirmPrompt(strings.NewReader(tt.input), &prompt, "确认? [y/N] ")
+		if err != nil || got != tt.want {
+			t.Errorf("confirmPrompt(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+		if prompt.String() != "确认? [y/N] " {
+			t.Errorf("prompt = %q, want the prompt written", prompt.String())
+		}
+	}
+}
+
+func TestLookupClusterNode(t *testing.T) {
+	api := moveFixture()
+	client := newTestClient(t, api)
+
+	node, err := client.LookupClusterNode(context.Background(), "", "10.0.0.1")
+	if err != nil || node.Role != "write" {
+		t.Errorf("LookupClusterNode(all) = %+v, %v, want the write node", node, err)
+	}
+	// 指定集群时只在该集群中查找
+	if _, err := client.LookupClusterNode(context.Background(), "LOG002", "10.0.0.1"); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("LookupClusterNode(LOG002) error = %v, want ErrNodeNotFound", err)
+	}
+	if _, err := client.LookupClusterNode(context.Background(), "", "10.9.9.9"); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("LookupClusterNode(unknown) error = %v, want ErrNodeNotFound", err)
+	}
+}
+
+func TestCmdDeleteNodeConfirmation(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       CommandLineArgs
+		stdin      string
+		wantErr    error
+		wantLookup bool
+		wantDelete bool
+	}{
+		{"confirmed", CommandLineArgs{ClusterName: "LOG001"}, "y\n", nil, true, true},
+		{"declined", CommandLineArgs{ClusterName: "LOG001"}, "n\n", nil, true, false},
+		{"yes flag", CommandLineArgs{ClusterName: "LOG001", Yes: true}, "", nil, true, true},
+		{"not found", CommandLineArgs{ClusterName: "LOG002", Yes: true}, "", ErrNodeNotFound, true, false},
+		// --force 跳过存在性检查
+		{"force", CommandLineArgs{ClusterName: "LOG002", Yes: true, Force: true}, "", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := moveFixture()
+			api.handle("GET /operation/clusters/LOG002", clusterWithNodes("LOG002"))
+			api.handle("DELETE /operation/clusters/nodes/10.0.0.1", func(w http.ResponseWriter, r *http.Request) {
+				respondResult(w, nil)
+			})
+			withStdin(t, tt.stdin)
+			tt.args.Positional = []string{"10.0.0.1"}
+
+			var err error
+			captureStdout(t, func() { err = cmdDeleteNode(newTestClient(t, api), &tt.args) })
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			case !tt.wantDelete:
+				if err == nil || !strings.Contains(err.Error(), "已取消") {
+					t.Errorf("error = %v, want a cancellation error", err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+			if got := api.count("GET /operation/clusters/") > 0; got != tt.wantLookup {
+				t.Errorf("lookup = %v, want %v (requests: %v)", got, tt.wantLookup, api.requests())
+			}
+			if got := api.count("DELETE ") > 0; got != tt.wantDelete {
+				t.Errorf("delete = %v, want %v (requests: %v)", got, tt.wantDelete, api.requests())
+			}
+		})
+	}
+}
+
+// ==================== 中断信号 ====================
+
+// 收到 SIGINT/SIGTERM 时 main 取消命令的 context,命令应尽快返回 context.Canceled
+func TestCommandCanceled(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(calls *atomic.Int32) http.HandlerFunc
+	}{
+		{"in-flight request", func(calls *atomic.Int32) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				<-r.Context().Done()
+			}
+		}},
+		{"retry backoff", func(calls *atomic.Int32) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				respondError(w, http.StatusServiceUnavailable, 503, "busy")
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			api := newFakeAPI()
+			api.handle("GET /operation/clusters", tt.handler(&calls))
+			client := newTestClient(t, api, func(c *Config) {
+				c.MaxRetries = 3
+				c.RetryBackoff = time.Hour
+				c.RetryBackoffMax = time.Hour
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- cmdClusters(client, &CommandLineArgs{ctx: ctx}) }()
+			for calls.Load() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("error = %v, want context.Canceled", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("command did not return after the context was canceled")
+			}
+			if n := calls.Load(); n != 1 {
+				t.Errorf("calls = %d, want no retry after cancellation", n)
+			}
+		})
+	}
+}
+
+// ==================== 集群子系统分页 ====================
+
+func TestCmdClustersSubsystems(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/cluster/LOG001/subsystems", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, map[string]interface{}{
+			"items": []LogSubClusterSubSystem{{SubsystemID: "SYS011", SubsysName: "payment", SubsystemOwner: "alice", Traffic: 2048, Status: "active"}},
+			"total": 11,
+		})
+	})
+	client := newTestClient(t, api)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = cmdClusters(client, &CommandLineArgs{ClusterName: "LOG001", ClusterSubsystems: true, Limit: 10, Offset: 10, Sort: "traffic", Output: "csv"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ID,NAME,OWNER,TRAFFIC,STATUS\nSYS011,payment,alice,2048,active\n"; output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+	if n := api.count("GET /operation/cluster/LOG001/subsystems?limit=10&offset=10&sortBy=traffic"); n != 1 {
+		t.Errorf("requests = %v, want one page request", api.requests())
+	}
+
+	for _, args := range []*CommandLineArgs{
+		{ClusterSubsystems: true, Limit: 10},
+		{ClusterName: "LOG001", ClusterSubsystems: true, Limit: 0},
+		{ClusterName: "LOG001", ClusterSubsystems: true, Limit: 10, Offset: -1},
+	} {
+		if err := cmdClusters(client, args); err == nil {
+			t.Errorf("cmdClusters(%+v) error = nil, want an error", args)
+		}
+	}
+	if n := api.count("GET /operation/cluster/"); n != 1 {
+		t.Errorf("requests = %v, want none for invalid arguments", api.requests())
+	}
+}
+
+// ==================== 模拟服务端 ====================
+
+// 模拟服务端的固定数据应能被客户端按响应模型完整解析
+func TestClientAgainstMockServer(t *testing.T) {
+	srv := mockserver.NewMockServer()
+	t.Cleanup(srv.Close)
+	client := NewClient(DefaultConfig(srv.URL))
+	ctx := context.Background()
+
+	dashboard, err := client.GetDashboard(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.ClusterNum != 2 || dashboard.SubsystemCount != 4 || len(dashboard.FailedSections) != 0 || dashboard.ClusterLogCounts[0].CapacityBytes == 0 {
+		t.Errorf("dashboard = %+v, want 2 clusters and 4 subsystems fully parsed", dashboard)
+	}
+
+	detail, err := client.GetClusterDetail(ctx, "LOG001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.ClusterInfo.IsDefault != 1 || len(detail.NodeGroups) != 3 || len(detail.ManagedSubSystems) != 2 {
+		t.Errorf("detail = %+v, want the default cluster with 3 node groups and 2 subsystems", detail)
+	}
+
+	page, total, err := client.GetClusterSubsystemsPage(ctx, "LOG002", &GetClusterSubsystemsRequest{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].SubsystemID != "SYS004" || total != 2 {
+		t.Errorf("page = %+v, total = %d, want [SYS004] of 2", page, total)
+	}
+
+	byCluster, err := client.GetSubsystemsByCluster(ctx, "LOG001")
+	if err != nil || len(byCluster) != 2 {
+		t.Errorf("GetSubsystemsByCluster() = %+v, %v, want 2 subsystems", byCluster, err)
+	}
+
+	subsystem, err := client.GetSubsystemDetail(ctx, "SYS003")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subsystem.SubsystemInfo.SubsysName != "account" || subsystem.ClusterName != "LOG002" || len(subsystem.Instances) != 1 {
+		t.Errorf("subsystem = %+v, want SYS003 in LOG002 with one instance", subsystem)
+	}
+
+	exists, err := client.CheckSubsystemExists(ctx, "SYS999")
+	if err != nil || exists.Exists {
+		t.Errorf("CheckSubsystemExists(SYS999) = %+v, %v, want not found", exists, err)
+	}
+}
+
+// runCLIEnv 设置为 1 时测试二进制直接执行 main (见 TestMain)
+const runCLIEnv = "WEAPM_TEST_RUN_CLI"
+
+// runCLI 以子进程运行 weapm 命令行,返回 stdout; 进程失败时连同 stderr 报告
+func runCLI(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runCLIEnv+"=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("weapm %s: %v\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestMockDashboardCommand(t *testing.T) {
+	var dashboard DashboardResult
+	if err := json.Unmarshal([]byte(runCLI(t, "dashboard", "--mock", "-o", "json")), &dashboard); err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.ClusterNum != 2 || dashboard.SubsystemCount != 4 {
+		t.Errorf("dashboard = %+v, want 2 clusters and 4 subsystems", dashboard)
+	}
+}
+
+func TestMockClustersCommand(t *testing.T) {
+	out := runCLI(t, "clusters", "--mock")
+	for _, want := range []string{"LOG001", "LOG002", "log-topic-001"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("clusters output missing %q:\n%s", want, out)
+		}
+	}
+
+	var detail ClusterDetailResult
+	if err := json.Unmarshal([]byte(runCLI(t, "clusters", "--mock", "--detail", "--cluster-name", "LOG001", "-o", "json")), &detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.ClusterInfo.ClusterName != "LOG001" || len(detail.NodeGroups) != 3 {
+		t.Errorf("detail = %+v, want LOG001 with 3 node groups", detail)
+	}
+}
+
+func TestCmdNodesOutput(t *testing.T) {
+	client := newTestClient(t, mockserver.Handler())
+
+	tests := []struct {
+		name string
+		args CommandLineArgs
+		want string
+	}{
+		{"default table", CommandLineArgs{ClusterName: "LOG002"}, "" +
+			"CLUSTER  ADDRESS    ROLE    CPU  MEM  STATUS\n" +
+			"LOG002   10.0.2.11  master  8    16   running\n" +
+			"LOG002   10.0.2.12  write   8    16   stopped\n"},
+		{"csv", CommandLineArgs{ClusterName: "LOG002", Role: "master", Output: "csv"}, "" +
+			"CLUSTER,ADDRESS,ROLE,CPU,MEM,STATUS\n" +
+			"LOG002,10.0.2.11,master,8,16,running\n"},
+		{"empty json", CommandLineArgs{ClusterName: "LOG002", Role: "read", Output: "json"}, "[]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			out := captureStdout(t, func() { err = cmdNodes(client, &tt.args) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.want {
+				t.Errorf("output:\n%s\nwant:\n%s", out, tt.want)
+			}
+		})
+	}
+
+	tmpl, err := parseOutputTemplate(`{{ range . }}{{ .Address }} {{ end }}`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := captureStdout(t, func() { err = cmdNodes(client, &CommandLineArgs{ClusterName: "LOG001", Role: "write", tmpl: tmpl}) })
+	if err != nil || out != "10.0.1.12 \n" {
+		t.Errorf("template output = %q, %v, want %q", out, err, "10.0.1.12 \n")
+	}
+}
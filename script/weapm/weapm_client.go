@@ -2,18 +2,29 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/time/rate"
 	"gopkg.in/yaml.v3"
 )
 
@@ -24,46 +35,161 @@ var logger = log.New(os.Stdout, "WEAPM: ", log.LstdFlags|log.Lshortfile)
 
 // EnvConfig 环境配置
 type EnvConfig struct {
-	BaseURL           string  `yaml:"base_url"`
-	Username          string  `yaml:"username"`
-	Password          string  `yaml:"password"`
-	Timeout           int     `yaml:"timeout"`
-	MaxRetries        int     `yaml:"max_retries"`
-	RetryBackoff      float64 `yaml:"retry_backoff_factor"`
-	PoolConnections   int     `yaml:"pool_connections"`
-	EnableLogging     bool    `yaml:"enable_logging"`
-	Description       string  `yaml:"description"`
+	BaseURL              string            `yaml:"base_url"`
+	Username             string            `yaml:"username"`
+	Password             string            `yaml:"password"`
+	AuthMode             string            `yaml:"auth_mode"`
+	Token                string            `yaml:"token"`
+	APIKey               string            `yaml:"api_key"`
+	Timeout              int               `yaml:"timeout"`
+	MaxRetries           int               `yaml:"max_retries"`
+	MaxStatusRetries     int               `yaml:"max_status_retries"`
+	MaxConnRetries       int               `yaml:"max_connection_retries"`
+	RetryBackoff         float64           `yaml:"retry_backoff_factor"`
+	RetryBackoffMax      float64           `yaml:"retry_backoff_max"`
+	RetryJitter          bool              `yaml:"retry_jitter"`
+	RetryAfterMax        float64           `yaml:"retry_after_max"`
+	PoolConnections      int               `yaml:"pool_connections"`
+	EnableLogging        bool              `yaml:"enable_logging"`
+	CacheTTL             int               `yaml:"cache_ttl"`
+	MaxLimit             int               `yaml:"max_limit"`
+	EnvelopeKey          string            `yaml:"envelope_key"`
+	MaxResponseBytes     int64             `yaml:"max_response_bytes"`
+	MaxURLLength         int               `yaml:"max_url_length"`
+	BasePath             string            `yaml:"base_path"`
+	SuccessCodes         []int             `yaml:"success_codes"`
+	InsecureSkipVerify   bool              `yaml:"insecure_skip_verify"`
+	TLSCACertFile        string            `yaml:"tls_ca_cert_file"`
+	TLSClientCertFile    string            `yaml:"tls_client_cert_file"`
+	TLSClientKeyFile     string            `yaml:"tls_client_key_file"`
+	Proxy                string            `yaml:"proxy"`
+	SigningSecret        string            `yaml:"signing_secret"`
+	ClusterNamePattern   string            `yaml:"cluster_name_pattern"`
+	SkipClusterNameCheck bool              `yaml:"skip_cluster_name_check"`
+	LegacyAdjustQuery    bool              `yaml:"legacy_adjust_query"`
+	UserAgent            string            `yaml:"user_agent"`
+	RateLimit            float64           `yaml:"rate_limit"`
+	RateBurst            int               `yaml:"rate_burst"`
+	DefaultHeaders       map[string]string `yaml:"default_headers"`
+	AuditSyslog          string            `yaml:"audit_syslog"`
+	AuditSyslogFacility  string            `yaml:"audit_syslog_facility"`
+	AuditSyslogTag       string            `yaml:"audit_syslog_tag"`
+	SensitiveParams      []string          `yaml:"sensitive_params"`
+	Description          string            `yaml:"description"`
 }
 
 // ConfigFile 配置文件结构
+// 顶层的 base_path / success_codes 对所有环境生效,环境中的同名字段优先
+//
+// 环境名称不限于 dev/prod,既可写在 environments 下,也可直接写在顶层 (兼容原有写法):
+//
+//	environments:
+//	  staging:
+//	    base_url: "https://staging.example.com"
+//	dev:
+//	  base_url: "http://localhost:8080"
 type ConfigFile struct {
-	Dev       EnvConfig `yaml:"dev"`
-	Prod      EnvConfig `yaml:"prod"`
-	ActiveEnv string    `yaml:"active_env"`
+	Environments map[string]EnvConfig `yaml:"environments"`
+	ActiveEnv    string               `yaml:"active_env"`
+	BasePath     string               `yaml:"base_path"`
+	SuccessCodes []int                `yaml:"success_codes"`
+}
+
+// UnmarshalYAML 解析 environments 及顶层的环境配置,同一环境在两处同时出现时报错
+func (f *ConfigFile) UnmarshalYAML(value *yaml.Node) error {
+	var raw struct {
+		Environments map[string]EnvConfig `yaml:"environments"`
+		ActiveEnv    string               `yaml:"active_env"`
+		BasePath     string               `yaml:"base_path"`
+		SuccessCodes []int                `yaml:"success_codes"`
+		TopLevel     map[string]EnvConfig `yaml:",inline"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	f.ActiveEnv, f.BasePath, f.SuccessCodes = raw.ActiveEnv, raw.BasePath, raw.SuccessCodes
+	f.Environments = make(map[string]EnvConfig, len(raw.Environments)+len(raw.TopLevel))
+	for name, env := range raw.Environments {
+		f.Environments[name] = env
+	}
+	for name, env := range raw.TopLevel {
+		if _, ok := f.Environments[name]; ok {
+			return fmt.Errorf("环境 %s 同时定义在顶层和 environments 中", name)
+		}
+		f.Environments[name] = env
+	}
+	return nil
+}
+
+// EnvNames 返回配置文件中的环境名称 (已排序)
+func (f *ConfigFile) EnvNames() []string {
+	names := make([]string, 0, len(f.Environments))
+	for name := range f.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // Config WEAPM API 配置
 type Config struct {
-	BaseURL       string
-	Timeout       time.Duration
-	Username      string
-	Password      string
-	MaxRetries    int
-	RetryBackoff  time.Duration
-	EnableLogging bool
+	BaseURL              string
+	Timeout              time.Duration
+	Username             string
+	Password             string
+	AuthMode             string // 认证方式: basic (默认) / bearer / apikey
+	Token                string // bearer 模式使用的令牌
+	APIKey               string // apikey 模式使用的密钥
+	MaxRetries           int
+	MaxStatusRetries     int           // 可重试状态码 (见 RetryableStatus) 的最大重试次数,0 时使用 MaxRetries
+	MaxConnRetries       int           // 连接/读取失败的最大重试次数,0 时使用 MaxRetries
+	RetryBackoff         time.Duration // 退避基数,第 n 次重试等待 RetryBackoff * 2^(n-1)
+	RetryBackoffMax      time.Duration // 单次退避上限,0 时使用默认值
+	RetryJitter          bool          // 在 [0, 退避时间] 内随机等待,避免多个客户端同时重试
+	RetryAfterMax        time.Duration // 服务端 Retry-After 的等待上限,0 时使用默认值
+	EnableLogging        bool
+	CacheTTL             time.Duration     // GET 响应缓存时长,0 表示不缓存
+	MaxLimit             int               // 分页/搜索 limit 上限,超出时截断
+	EnvelopeKey          string            // 响应中数据所在的字段名,默认 result
+	MaxResponseBytes     int64             // 单个响应 (解压后) 的最大字节数
+	MaxURLLength         int               // 完整 URL 的最大长度,超出时改用 POST 表单发送参数,0 时使用默认值
+	BasePath             string            // 接口路径前缀,替换默认的 /operation (如 /api/operation)
+	SuccessCodes         []int             // 视为成功的业务码,默认只有 0
+	InsecureSkipVerify   bool              // 跳过 TLS 证书校验,仅用于自签名证书的测试环境
+	TLSCACertFile        string            // 额外信任的 CA 证书 (PEM),用于私有 CA 签发的服务端证书
+	TLSClientCertFile    string            // mTLS 客户端证书 (PEM),需与 TLSClientKeyFile 同时配置
+	TLSClientKeyFile     string            // mTLS 客户端私钥 (PEM)
+	Proxy                string            // 代理 URL (http / https / socks5),为空时使用 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量
+	PoolConnections      int               // 每个主机的连接池大小,0 时使用默认值
+	SigningSecret        string            // 非空时使用 HMAC-SHA256 对请求签名
+	RetryableStatus      func(int) bool    // 判断状态码是否重试,nil 时使用 DefaultRetryableStatus
+	ClusterNamePattern   string            // 集群名称格式 (正则),为空时使用 DefaultClusterNamePattern
+	SkipClusterNameCheck bool              // 关闭集群名称格式校验
+	LegacyAdjustQuery    bool              // 调整子系统归属集群时按旧接口以查询参数发送,用于尚未支持 JSON 请求体的服务端
+	AuditSyslog          string            // 变更请求审计事件写入的 syslog: local / udp://host:port / tcp://host:port,为空时不审计
+	AuditSyslogFacility  string            // 审计 syslog facility,默认 local0
+	AuditSyslogTag       string            // 审计 syslog tag,默认 weapm
+	SensitiveParams      []string          // 请求日志中值替换为 *** 的查询参数 (不区分大小写),nil 时使用 DefaultSensitiveParams
+	DryRun               bool              // 演练模式: 变更请求 (非 GET/HEAD) 不发送,只输出请求计划,查询请求照常发送
+	UserAgent            string            // 请求的 User-Agent,为空时使用 weapm-client/<版本>
+	RateLimit            float64           // 每秒最多发送的请求数 (含重试),0 表示不限制
+	Burst                int               // 限流允许的突发请求数,0 时为 1
+	DefaultHeaders       map[string]string // 每个请求都附带的请求头 (如租户、链路追踪),可被 WithHeaders 按次覆盖
+	Env                  string            // 从配置文件加载时选中的环境名称,非空时附加在默认 User-Agent 中
 }
 
-// LoadConfigFromYAML 从 YAML 文件加载配置
-func LoadConfigFromYAML(configPath string, env string) (*Config, error) {
-	// 默认配置文件路径
-	if configPath == "" {
-		execDir, err := os.Executable()
-		if err != nil {
-			return nil, fmt.Errorf("获取可执行文件路径失败: %w", err)
-		}
-		configPath = filepath.Join(filepath.Dir(execDir), "config.yaml")
+// defaultConfigPath 默认配置文件路径 (可执行文件同目录下的 config.yaml)
+func defaultConfigPath() (string, error) {
+	execDir, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("获取可执行文件路径失败: %w", err)
 	}
+	return filepath.Join(filepath.Dir(execDir), "config.yaml"), nil
+}
 
+// readConfigFile 读取并解析单个配置文件
+func readConfigFile(configPath string) (*ConfigFile, error) {
 	// 检查配置文件是否存在
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("配置文件不存在: %s", configPath)
@@ -81,6 +207,88 @@ func LoadConfigFromYAML(configPath string, env string) (*Config, error) {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
+	return &configFile, nil
+}
+
+// mergeEnvConfig 用 overlay 中的非零值字段覆盖 base
+// 注意: 布尔字段只能由 false 覆盖为 true,无法通过覆盖文件关闭
+func mergeEnvConfig(base, overlay EnvConfig) EnvConfig {
+	merged := base
+	dst := reflect.ValueOf(&merged).Elem()
+	src := reflect.ValueOf(overlay)
+	for i := 0; i < src.NumField(); i++ {
+		if !src.Field(i).IsZero() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	return merged
+}
+
+// mergeConfigFile 将覆盖文件按环境合并到基础配置
+func mergeConfigFile(base, overlay *ConfigFile) {
+	if base.Environments == nil {
+		base.Environments = make(map[string]EnvConfig, len(overlay.Environments))
+	}
+	for name, env := range overlay.Environments {
+		base.Environments[name] = mergeEnvConfig(base.Environments[name], env)
+	}
+	if overlay.ActiveEnv != "" {
+		base.ActiveEnv = overlay.ActiveEnv
+	}
+	if overlay.BasePath != "" {
+		base.BasePath = overlay.BasePath
+	}
+	if len(overlay.SuccessCodes) > 0 {
+		base.SuccessCodes = overlay.SuccessCodes
+	}
+}
+
+// loadMergedConfigFile 读取基础配置文件并依次合并覆盖文件
+func loadMergedConfigFile(configPath string, overlayPaths []string) (*ConfigFile, error) {
+	configFile, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, overlayPath := range overlayPaths {
+		if overlayPath == "" {
+			continue
+		}
+		overlay, err := readConfigFile(overlayPath)
+		if err != nil {
+			return nil, fmt.Errorf("加载覆盖配置失败: %w", err)
+		}
+		mergeConfigFile(configFile, overlay)
+	}
+	return configFile, nil
+}
+
+// LoadConfigFromYAML 从 YAML 文件加载配置
+//
+// overlayPaths 为可选的覆盖文件 (如 config.override.yaml,用于存放个人密码等不入库的配置),
+// 按环境逐字段合并: 覆盖文件中的非空字段优先于基础文件,多个覆盖文件时后者优先,
+// 两者都未设置的字段再使用默认值; 开启配置缓存 (WEAPM_CONFIG_CACHE=1) 时复用未变化文件的解析结果
+func LoadConfigFromYAML(configPath string, env string, overlayPaths ...string) (*Config, error) {
+	// 默认配置文件路径
+	if configPath == "" {
+		defaultPath, err := defaultConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		configPath = defaultPath
+	}
+
+	var configFile *ConfigFile
+	var err error
+	if configCacheEnabled {
+		configFile, err = loadConfigFileCached(configPath, overlayPaths)
+	} else {
+		configFile, err = loadMergedConfigFile(configPath, overlayPaths)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	// 确定使用的环境
 	if env == "" {
 		env = configFile.ActiveEnv
@@ -90,27 +298,30 @@ func LoadConfigFromYAML(configPath string, env string) (*Config, error) {
 	}
 
 	// 获取环境配置
-	var envConfig EnvConfig
-	switch env {
-	case "dev":
-		envConfig = configFile.Dev
-	case "prod":
-		envConfig = configFile.Prod
-	default:
-		return nil, fmt.Errorf("不支持的环境: %s, 可用环境: dev, prod", env)
+	envConfig, ok := configFile.Environments[env]
+	if !ok {
+		return nil, fmt.Errorf("不支持的环境: %s, 可用环境: %s", env, strings.Join(configFile.EnvNames(), ", "))
+	}
+
+	// 展开 ${VAR} 引用并应用环境变量中的凭据
+	if err := resolveCredentials(&envConfig); err != nil {
+		return nil, fmt.Errorf("环境 %s 凭据配置错误: %w", env, err)
 	}
 
 	// 验证必要字段
 	if envConfig.BaseURL == "" {
 		return nil, fmt.Errorf("环境 %s 缺少必要字段: base_url", env)
 	}
+	if err := validateAuth(envConfig.AuthMode, envConfig.Token, envConfig.APIKey); err != nil {
+		return nil, fmt.Errorf("环境 %s 认证配置错误: %w", env, err)
+	}
 
 	// 设置默认值
 	if envConfig.Username == "" {
 		envConfig.Username = "weapmUser"
 	}
 	if envConfig.Password == "" {
-		envConfig.Password = "Weapm@123admin"
+		envConfig.Password = defaultPassword
 	}
 	if envConfig.Timeout == 0 {
 		envConfig.Timeout = 30
@@ -121,6 +332,29 @@ func LoadConfigFromYAML(configPath string, env string) (*Config, error) {
 	if envConfig.RetryBackoff == 0 {
 		envConfig.RetryBackoff = 0.5
 	}
+	if envConfig.MaxLimit == 0 {
+		envConfig.MaxLimit = defaultMaxLimit
+	}
+	if envConfig.MaxResponseBytes == 0 {
+		envConfig.MaxResponseBytes = defaultMaxResponseBytes
+	}
+	// 环境未设置时使用顶层配置
+	if envConfig.BasePath == "" {
+		envConfig.BasePath = configFile.BasePath
+	}
+	if len(envConfig.SuccessCodes) == 0 {
+		envConfig.SuccessCodes = configFile.SuccessCodes
+	}
+	if envConfig.ClusterNamePattern != "" {
+		if _, err := regexp.Compile(envConfig.ClusterNamePattern); err != nil {
+			return nil, fmt.Errorf("环境 %s 的 cluster_name_pattern 无效: %w", env, err)
+		}
+	}
+	if envConfig.AuditSyslog != "" {
+		if _, _, err := parseAuditSyslog(envConfig.AuditSyslog); err != nil {
+			return nil, fmt.Errorf("环境 %s: %w", env, err)
+		}
+	}
 
 	desc := envConfig.Description
 	if desc == "" {
@@ -128,83 +362,551 @@ func LoadConfigFromYAML(configPath string, env string) (*Config, error) {
 	}
 	fmt.Printf("✅ 加载配置: %s (%s)\n", desc, env)
 
-	return &Config{
-		BaseURL:       envConfig.BaseURL,
-		Timeout:       time.Duration(envConfig.Timeout) * time.Second,
-		Username:      envConfig.Username,
-		Password:      envConfig.Password,
-		MaxRetries:    envConfig.MaxRetries,
-		RetryBackoff:  time.Duration(envConfig.RetryBackoff * float64(time.Second)),
-		EnableLogging: envConfig.EnableLogging,
-	}, nil
+	config := &Config{
+		BaseURL:              envConfig.BaseURL,
+		Timeout:              time.Duration(envConfig.Timeout) * time.Second,
+		Username:             envConfig.Username,
+		Password:             envConfig.Password,
+		AuthMode:             envConfig.AuthMode,
+		Token:                envConfig.Token,
+		APIKey:               envConfig.APIKey,
+		MaxRetries:           envConfig.MaxRetries,
+		MaxStatusRetries:     envConfig.MaxStatusRetries,
+		MaxConnRetries:       envConfig.MaxConnRetries,
+		RetryBackoff:         time.Duration(envConfig.RetryBackoff * float64(time.Second)),
+		RetryBackoffMax:      time.Duration(envConfig.RetryBackoffMax * float64(time.Second)),
+		RetryJitter:          envConfig.RetryJitter,
+		RetryAfterMax:        time.Duration(envConfig.RetryAfterMax * float64(time.Second)),
+		EnableLogging:        envConfig.EnableLogging,
+		CacheTTL:             time.Duration(envConfig.CacheTTL) * time.Second,
+		MaxLimit:             envConfig.MaxLimit,
+		EnvelopeKey:          envConfig.EnvelopeKey,
+		MaxResponseBytes:     envConfig.MaxResponseBytes,
+		MaxURLLength:         envConfig.MaxURLLength,
+		BasePath:             envConfig.BasePath,
+		SuccessCodes:         envConfig.SuccessCodes,
+		InsecureSkipVerify:   envConfig.InsecureSkipVerify,
+		TLSCACertFile:        envConfig.TLSCACertFile,
+		TLSClientCertFile:    envConfig.TLSClientCertFile,
+		TLSClientKeyFile:     envConfig.TLSClientKeyFile,
+		Proxy:                envConfig.Proxy,
+		PoolConnections:      envConfig.PoolConnections,
+		SigningSecret:        envConfig.SigningSecret,
+		ClusterNamePattern:   envConfig.ClusterNamePattern,
+		SkipClusterNameCheck: envConfig.SkipClusterNameCheck,
+		LegacyAdjustQuery:    envConfig.LegacyAdjustQuery,
+		UserAgent:            envConfig.UserAgent,
+		RateLimit:            envConfig.RateLimit,
+		Burst:                envConfig.RateBurst,
+		DefaultHeaders:       envConfig.DefaultHeaders,
+		Env:                  env,
+		AuditSyslog:          envConfig.AuditSyslog,
+		AuditSyslogFacility:  envConfig.AuditSyslogFacility,
+		AuditSyslogTag:       envConfig.AuditSyslogTag,
+		SensitiveParams:      envConfig.SensitiveParams,
+	}
+
+	// 证书文件缺失或无效时在加载配置阶段报错,而不是等到第一次请求
+	if _, err := newTLSConfig(config); err != nil {
+		return nil, fmt.Errorf("环境 %s TLS 配置错误: %w", env, err)
+	}
+	if _, err := parseProxyURL(config.Proxy); err != nil {
+		return nil, fmt.Errorf("环境 %s: %w", env, err)
+	}
+	return config, nil
+}
+
+// defaultMaxLimit 默认的 limit 上限
+const defaultMaxLimit = 1000
+
+// clampLimit 将 limit 限制在 MaxLimit 以内,超出时记录警告
+func (c *Client) clampLimit(limit int) int {
+	maxLimit := c.config.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = defaultMaxLimit
+	}
+	if limit > maxLimit {
+		logger.Printf("⚠️  limit %d 超过上限 %d,已截断为 %d", limit, maxLimit, maxLimit)
+		return maxLimit
+	}
+	return limit
+}
+
+// defaultPassword 未配置密码时使用的内置默认密码
+const defaultPassword = "Weapm@123admin"
+
+// 凭据环境变量,设置后覆盖配置文件及命令行参数
+const (
+	EnvUsername = "WEAPM_USERNAME"
+	EnvPassword = "WEAPM_PASSWORD"
+	EnvToken    = "WEAPM_TOKEN"
+)
+
+// envRefPattern 匹配 ${VAR} 形式的环境变量引用 (不处理 $VAR,避免误展开密码中的 $)
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnvRefs 展开 value 中的 ${VAR} 引用,引用的环境变量未设置时返回错误
+func expandEnvRefs(value string) (string, error) {
+	var missing []string
+	expanded := envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRefPattern.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("环境变量未设置: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// overrideCredentialsFromEnv 用 WEAPM_USERNAME / WEAPM_PASSWORD / WEAPM_TOKEN 中非空的值覆盖凭据
+func overrideCredentialsFromEnv(username, password, token *string) {
+	for _, o := range []struct {
+		name  string
+		field *string
+	}{{EnvUsername, username}, {EnvPassword, password}, {EnvToken, token}} {
+		if v := os.Getenv(o.name); v != "" {
+			*o.field = v
+		}
+	}
+}
+
+// overrideCredentials 用命令行传入的非空用户名、密码覆盖配置,再应用凭据环境变量
+// 优先级: 环境变量 > 命令行参数 > 配置文件
+func overrideCredentials(config *Config, username, password string) {
+	if username != "" {
+		config.Username = username
+	}
+	if password != "" {
+		config.Password = password
+	}
+	overrideCredentialsFromEnv(&config.Username, &config.Password, &config.Token)
+}
+
+// ErrMissingCredentialEnv 显式要求从环境变量读取凭据时缺少必要的环境变量
+var ErrMissingCredentialEnv = errors.New("缺少凭据环境变量")
+
+// credentialsFromEnv 按认证方式从环境变量读取凭据,缺少任一必要变量时返回 ErrMissingCredentialEnv,
+// 不回退到配置文件、命令行参数或内置默认密码: basic 需要 WEAPM_USERNAME 和 WEAPM_PASSWORD,bearer 需要 WEAPM_TOKEN
+func credentialsFromEnv(config *Config) error {
+	var required []string
+	switch config.AuthMode {
+	case "", AuthModeBasic:
+		required = []string{EnvUsername, EnvPassword}
+	case AuthModeBearer:
+		required = []string{EnvToken}
+	default:
+		return fmt.Errorf("认证方式 %s 不支持从环境变量读取凭据", config.AuthMode)
+	}
+
+	var missing []string
+	for _, name := range required {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingCredentialEnv, strings.Join(missing, ", "))
+	}
+	overrideCredentialsFromEnv(&config.Username, &config.Password, &config.Token)
+	return nil
+}
+
+// resolveCredentials 展开凭据字段中的 ${VAR} 引用,再应用凭据环境变量覆盖
+// 优先级: 环境变量 > 命令行参数 > 配置文件
+func resolveCredentials(envConfig *EnvConfig) error {
+	for _, field := range []*string{&envConfig.Username, &envConfig.Password, &envConfig.Token, &envConfig.APIKey} {
+		expanded, err := expandEnvRefs(*field)
+		if err != nil {
+			return err
+		}
+		*field = expanded
+	}
+	overrideCredentialsFromEnv(&envConfig.Username, &envConfig.Password, &envConfig.Token)
+	return nil
 }
 
 // DefaultConfig 返回默认配置 (备用方案)
 func DefaultConfig(baseURL string) *Config {
 	return &Config{
-		BaseURL:       baseURL,
-		Timeout:       30 * time.Second,
-		Username:      "weapmUser",
-		Password:      "Weapm@123admin",
-		MaxRetries:    3,
-		RetryBackoff:  500 * time.Millisecond,
-		EnableLogging: true,
+		BaseURL:          baseURL,
+		Timeout:          30 * time.Second,
+		Username:         "weapmUser",
+		Password:         defaultPassword,
+		MaxRetries:       3,
+		RetryBackoff:     500 * time.Millisecond,
+		EnableLogging:    true,
+		MaxLimit:         defaultMaxLimit,
+		MaxResponseBytes: defaultMaxResponseBytes,
+		PoolConnections:  defaultPoolConnections,
+	}
+}
+
+// 认证方式
+const (
+	AuthModeBasic  = "basic"  // HTTP Basic Auth (Username/Password)
+	AuthModeBearer = "bearer" // Authorization: Bearer <Token>
+	AuthModeAPIKey = "apikey" // X-API-Key: <APIKey>
+)
+
+// validateAuth 校验认证方式及对应的凭据,mode 为空表示 basic
+func validateAuth(mode, token, apiKey string) error {
+	switch mode {
+	case "", AuthModeBasic:
+		return nil
+	case AuthModeBearer:
+		if token == "" {
+			return fmt.Errorf("auth_mode 为 bearer 时必须配置 token")
+		}
+		return nil
+	case AuthModeAPIKey:
+		if apiKey == "" {
+			return fmt.Errorf("auth_mode 为 apikey 时必须配置 api_key")
+		}
+		return nil
+	default:
+		return fmt.Errorf("不支持的认证方式: %s (可用: basic, bearer, apikey)", mode)
+	}
+}
+
+// setAuth 按认证方式设置请求头,同时设置 User-Agent
+func (c *Client) setAuth(req *http.Request) {
+	req.Header.Set("User-Agent", c.userAgent)
+	switch c.config.AuthMode {
+	case AuthModeBearer:
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	case AuthModeAPIKey:
+		req.Header.Set("X-API-Key", c.config.APIKey)
+	default:
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+}
+
+// signRequest 配置了签名器时对请求签名
+func (c *Client) signRequest(req *http.Request, body []byte) error {
+	if c.signer == nil {
+		return nil
+	}
+	if err := c.signer.Sign(req, body); err != nil {
+		return fmt.Errorf("请求签名失败: %w", err)
+	}
+	return nil
+}
+
+// redactedValue 脱敏后的占位符
+const redactedValue = "***"
+
+// redactSecret 对敏感值脱敏,空值保持为空以便区分"未设置"
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redacted 返回敏感字段已脱敏的配置副本
+func (c Config) redacted() Config {
+	c.Password = redactSecret(c.Password)
+	c.Token = redactSecret(c.Token)
+	c.APIKey = redactSecret(c.APIKey)
+	c.SigningSecret = redactSecret(c.SigningSecret)
+	if u, err := url.Parse(c.Proxy); err == nil && c.Proxy != "" {
+		c.Proxy = u.Redacted()
+	}
+	// 自定义请求头可能携带凭据,只保留名称
+	if len(c.DefaultHeaders) > 0 {
+		headers := make(map[string]string, len(c.DefaultHeaders))
+		for name, value := range c.DefaultHeaders {
+			headers[name] = redactSecret(value)
+		}
+		c.DefaultHeaders = headers
 	}
+	return c
 }
 
 // Client WEAPM-LOGSERVER API 客户端
 type Client struct {
 	config     *Config
 	httpClient *http.Client
+	cache      *responseCache
+	retries    retryTracker
+	clock      Clock
+	decoder    JSONDecoder
+	signer     RequestSigner
+	network    networkStats
+	metrics    MetricsObserver
+	audit      auditWriter   // 变更请求审计,nil 表示不审计
+	initErr    error         // 创建客户端时的配置错误,非 nil 时所有请求直接返回该错误
+	dryRunOut  io.Writer     // 演练模式下请求计划的输出位置,nil 时为 os.Stderr
+	userAgent  string        // 每个请求的 User-Agent
+	limiter    *rate.Limiter // 客户端限流,nil 表示不限制
+
+	batchConcurrency int // AddClusterNodes 的并发数,0 时使用 defaultConcurrency
+
+	clusterNameRE *regexp.Regexp // 集群名称格式,nil 表示不校验
+
+	capsMu    sync.Mutex
+	caps      *Capabilities // 服务端能力缓存
+	capsErr   error         // 最近一次获取能力失败的错误,capsFailureTTL 内直接返回
+	capsErrAt time.Time
+}
+
+// defaultPoolConnections 未配置 PoolConnections 时每个主机的连接池大小
+const defaultPoolConnections = 10
+
+// newTLSConfig 根据 CA、客户端证书及 InsecureSkipVerify 构建 TLS 配置,均未配置时返回 nil (使用默认配置)
+// CA 证书追加到系统证书池,系统证书池不可用时只信任该 CA
+func newTLSConfig(config *Config) (*tls.Config, error) {
+	if config.TLSCACertFile == "" && config.TLSClientCertFile == "" && config.TLSClientKeyFile == "" && !config.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if config.TLSCACertFile != "" {
+		pem, err := os.ReadFile(config.TLSCACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书文件 %s 中没有有效的 PEM 证书", config.TLSCACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (config.TLSClientCertFile == "") != (config.TLSClientKeyFile == "") {
+		return nil, fmt.Errorf("客户端证书与私钥需同时配置 (tls_client_cert_file / tls_client_key_file)")
+	}
+	if config.TLSClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSClientCertFile, config.TLSClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// parseProxyURL 解析代理 URL,仅支持 http / https / socks5; raw 为空时返回 nil
+func parseProxyURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("代理地址无效: %q", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return u, nil
+	default:
+		return nil, fmt.Errorf("不支持的代理协议: %q (可用: http, https, socks5)", u.Scheme)
+	}
+}
+
+// newTransport 创建客户端独享的 Transport,连接池大小取自 PoolConnections
+// 配置了 Proxy 时所有请求经该代理,否则按环境变量 (HTTP_PROXY 等) 决定
+func newTransport(config *Config) (*http.Transport, error) {
+	pool := config.PoolConnections
+	if pool <= 0 {
+		pool = defaultPoolConnections
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = pool
+	transport.MaxIdleConnsPerHost = pool
+	transport.MaxConnsPerHost = pool
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	proxyURL, err := parseProxyURL(config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	} else {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+	if config.InsecureSkipVerify {
+		logger.Printf("⚠️  已关闭 TLS 证书校验")
+	}
+	return transport, nil
+}
+
+// clientOptions NewClient 的可选参数
+type clientOptions struct {
+	httpClient *http.Client
+	dryRunOut  io.Writer
+	userAgent  string
+}
+
+// ClientOption 创建客户端的选项
+type ClientOption func(*clientOptions)
+
+// WithHTTPClient 使用调用方提供的 http.Client (如测试用的 mock Transport、带 mTLS/代理的 Transport),
+// 其 Transport 外仍包一层请求日志 (由 Config.EnableLogging 控制),Transport 为 nil 时使用 http.DefaultTransport
+// 此时 Config 中的连接池及 TLS 相关配置不生效; 传入的 http.Client 不会被修改
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithUserAgent 指定请求的 User-Agent,优先于 Config.UserAgent,适用于嵌入其他服务时标识调用方
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// defaultUserAgent 默认的 User-Agent: weapm-client/<版本>,已知环境时附加环境名称,
+// 便于服务端日志区分本工具与 curl、浏览器等其他调用方
+func defaultUserAgent(env string) string {
+	v, _, _ := buildInfo()
+	if env == "" {
+		return "weapm-client/" + v
+	}
+	return fmt.Sprintf("weapm-client/%s (env=%s)", v, env)
 }
 
 // NewClient 创建新的客户端实例
-func NewClient(config *Config) *Client {
+func NewClient(config *Config, opts ...ClientOption) *Client {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// 超时由 attemptContext 按每次尝试设置
+	httpClient := &http.Client{}
+	var transport http.RoundTripper
+	var initErr error
+	if options.httpClient != nil {
+		*httpClient = *options.httpClient
+		transport = httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+	} else {
+		var err error
+		if transport, err = newTransport(config); err != nil {
+			// NewClient 不返回错误,TLS/代理配置无效时所有请求返回该错误,避免退回到默认的连接方式
+			initErr = fmt.Errorf("传输层配置错误: %w", err)
+			logger.Printf("❌ %v", initErr)
+			transport = http.DefaultTransport
+		}
+	}
+	httpClient.Transport = &loggingRoundTripper{
+		clock:     realClock{},
+		logger:    logger,
+		next:      transport,
+		enable:    config.EnableLogging,
+		baseURL:   config.BaseURL,
+		sensitive: config.SensitiveParams,
+	}
+
 	client := &Client{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-			Transport: &loggingRoundTripper{
-				logger:   logger,
-				next:     http.DefaultTransport,
-				enable:   config.EnableLogging,
-				baseURL:  config.BaseURL,
-			},
-		},
+		config:     config,
+		clock:      realClock{},
+		decoder:    stdJSONDecoder,
+		httpClient: httpClient,
+		metrics:    noopMetricsObserver{},
+		initErr:    initErr,
+		dryRunOut:  options.dryRunOut,
+		userAgent:  options.userAgent,
+	}
+	if client.userAgent == "" {
+		client.userAgent = config.UserAgent
+	}
+	if client.userAgent == "" {
+		client.userAgent = defaultUserAgent(config.Env)
+	}
+	if config.CacheTTL > 0 {
+		client.cache = newResponseCache(config.CacheTTL, client.clock)
+	}
+	if config.RateLimit > 0 {
+		client.limiter = newRateLimiter(config.RateLimit, config.Burst)
+	}
+	if config.SigningSecret != "" {
+		client.signer = NewHMACSigner(config.SigningSecret)
+	}
+	if !config.SkipClusterNameCheck {
+		client.clusterNameRE = compileClusterNamePattern(config.ClusterNamePattern)
+	}
+	if config.AuditSyslog != "" {
+		client.audit = newAuditWriter(config)
 	}
 	logger.Printf("WEAPM 客户端初始化成功: %s", config.BaseURL)
 	return client
 }
 
+// SetClock 替换客户端的时间来源 (重试退避、缓存过期、限流、请求耗时、就绪等待及轮询间隔),主要用于测试
+func (c *Client) SetClock(clock Clock) {
+	c.clock = clock
+	if c.cache != nil {
+		c.cache.clock = clock
+	}
+	if t, ok := c.httpClient.Transport.(*loggingRoundTripper); ok {
+		t.clock = clock
+	}
+	if c.limiter != nil {
+		// Limiter 记录上次补充令牌的时间,换用新时钟后重新开始计算
+		c.limiter = newRateLimiter(float64(c.limiter.Limit()), c.limiter.Burst())
+	}
+}
+
+// EffectiveConfig 返回客户端实际生效的配置副本 (已完成文件加载、覆盖合并、参数覆盖和默认值填充),
+// 敏感字段已脱敏,可直接用于问题排查
+func (c *Client) EffectiveConfig() Config {
+	return c.config.redacted()
+}
+
 // loggingRoundTripper 日志记录的 HTTP Transport
+// 记录的 URL 已脱敏 (见 redactURL),请求头 (含 Authorization) 不写入日志
 type loggingRoundTripper struct {
-	logger  *log.Logger
-	next    http.RoundTripper
-	enable  bool
-	baseURL string
+	clock     Clock
+	logger    *log.Logger
+	next      http.RoundTripper
+	enable    bool
+	baseURL   string
+	sensitive []string // 需脱敏的查询参数,nil 时使用 DefaultSensitiveParams
 }
 
 func (t *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	start := time.Now()
+	start := t.clock.Now()
 
+	var logURL string
 	if t.enable {
-		t.logger.Printf("发送请求: %s %s", req.Method, req.URL.String())
+		logURL = redactURL(req.URL, t.sensitive)
+		t.logger.Printf("发送请求: %s %s", req.Method, logURL)
 	}
 
 	resp, err := t.next.RoundTrip(req)
 	if err != nil {
 		if t.enable {
-			t.logger.Printf("请求失败: %s %s - 错误: %v", req.Method, req.URL.String(), err)
+			t.logger.Printf("请求失败: %s %s - 错误: %v", req.Method, logURL, redactURLError(err, t.sensitive))
 		}
 		return nil, err
 	}
 
 	if t.enable {
-		duration := time.Since(start)
+		duration := t.clock.Now().Sub(start)
 		t.logger.Printf(
 			"收到响应: %s %s - 状态码: %d, 耗时: %.2fs",
 			req.Method,
-			req.URL.String(),
+			logURL,
 			resp.StatusCode,
 			duration.Seconds(),
 		)
@@ -222,6 +924,7 @@ type DashboardResult struct {
 	ClusterTrafficData  []ClusterTrafficData `json:"clusterTrafficData"`
 	TopSubsystems       []SubsystemLogDetail `json:"topSubsystems"`
 	ClusterLogCounts    []ClusterLogCount   `json:"clusterLogCounts"`
+	FailedSections      []string            `json:"failedSections,omitempty"` // 解析失败的字段
 }
 
 // ClusterTrafficData 集群流量数据
@@ -243,10 +946,103 @@ type SubsystemLogDetail struct {
 }
 
 // ClusterLogCount 集群日志统计
+// 部分服务端版本以带单位的字符串 (如 "10TB") 返回容量和用量,
+// 解析时统一换算为字节存入 TotalLogBytes/CapacityBytes,TotalLogGb/Capacity 保留为 GB
 type ClusterLogCount struct {
-	ClusterName string `json:"clustername"`
-	TotalLogGb  int    `json:"total_log_gb"`
-	Capacity    int    `json:"capacity"`
+	ClusterName   string `json:"clustername"`
+	TotalLogGb    int64  `json:"total_log_gb"`
+	Capacity      int64  `json:"capacity"`
+	TotalLogBytes int64  `json:"totalLogBytes,omitempty"` // 归一化后的用量 (字节)
+	CapacityBytes int64  `json:"capacityBytes,omitempty"` // 归一化后的容量 (字节)
+}
+
+// UnmarshalJSON 兼容数字 (按 GB) 与带单位字符串两种容量格式
+func (c *ClusterLogCount) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ClusterName string          `json:"clustername"`
+		TotalLogGb  json.RawMessage `json:"total_log_gb"`
+		Capacity    json.RawMessage `json:"capacity"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	totalBytes, err := parseSizeJSON(raw.TotalLogGb, gigabyte)
+	if err != nil {
+		return fmt.Errorf("total_log_gb: %w", err)
+	}
+	capacityBytes, err := parseSizeJSON(raw.Capacity, gigabyte)
+	if err != nil {
+		return fmt.Errorf("capacity: %w", err)
+	}
+
+	*c = ClusterLogCount{
+		ClusterName:   raw.ClusterName,
+		TotalLogGb:    totalBytes / gigabyte,
+		Capacity:      capacityBytes / gigabyte,
+		TotalLogBytes: totalBytes,
+		CapacityBytes: capacityBytes,
+	}
+	return nil
+}
+
+// 容量单位 (按 1024 进制)
+const (
+	kilobyte int64 = 1 << (10 * (iota + 1))
+	megabyte
+	gigabyte
+	terabyte
+	petabyte
+)
+
+var sizeUnits = map[string]int64{
+	"":  1,
+	"B": 1,
+	"K": kilobyte, "KB": kilobyte, "KI": kilobyte, "KIB": kilobyte,
+	"M": megabyte, "MB": megabyte, "MI": megabyte, "MIB": megabyte,
+	"G": gigabyte, "GB": gigabyte, "GI": gigabyte, "GIB": gigabyte,
+	"T": terabyte, "TB": terabyte, "TI": terabyte, "TIB": terabyte,
+	"P": petabyte, "PB": petabyte, "PI": petabyte, "PIB": petabyte,
+}
+
+// parseSize 解析带单位的容量字符串 (如 "10TB"、"500 GB"、"1.5T"),返回字节数
+// 不带单位时按 defaultUnit 计算
+func parseSize(s string, defaultUnit int64) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	number, unit := s[:i], strings.ToUpper(strings.TrimSpace(s[i:]))
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析容量 %q", s)
+	}
+	multiplier := defaultUnit
+	if unit != "" {
+		m, ok := sizeUnits[unit]
+		if !ok {
+			return 0, fmt.Errorf("未知的容量单位 %q", unit)
+		}
+		multiplier = m
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// parseSizeJSON 解析数字或字符串形式的容量,数字及不带单位的字符串按 defaultUnit 计算
+func parseSizeJSON(raw json.RawMessage, defaultUnit int64) (int64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if text == "" {
+			return 0, nil
+		}
+		return parseSize(text, defaultUnit)
+	}
+	return parseSize(string(raw), defaultUnit)
 }
 
 // LogClusterInfo 集群信息
@@ -341,204 +1137,1726 @@ type SubsystemDetailResult struct {
 	SubsystemInfo    SubSystem `json:"subsystemInfo"`
 	Collected        bool      `json:"collected"`
 	ScanFileWhitelist []string `json:"scanFileWhitelist"`
-	ExpectedTraffic  int       `json:"expectedTraffic"`
-	ActualTraffic    int       `json:"actualTraffic"`
+	ExpectedTraffic  int64     `json:"expectedTraffic"`
+	ActualTraffic    int64     `json:"actualTraffic"`
 	KeywordFilters   []string  `json:"keywordFilters"`
 	ClusterName      string    `json:"clusterName"`
-	Instances        []map[string][]string `json:"instances"`
+	Instances        []SubsystemInstance `json:"instances"`
+	RawInstances     json.RawMessage     `json:"-"` // 服务端返回的原始 instances,便于排查格式差异
 }
 
-// APIResponse 通用API响应
-type APIResponse struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Result  interface{} `json:"result,omitempty"`
+// SubsystemInstance 子系统采集实例
+type SubsystemInstance struct {
+	Address string   `json:"address"`
+	Files   []string `json:"files"`
 }
 
-// ==================== HTTP 请求方法 ====================
-
-// doRequest 执行HTTP请求 (带重试机制)
-func (c *Client) doRequest(ctx context.Context, method, endpoint string, body []byte) (*APIResponse, error) {
-	var lastErr error
-
-	// 重试逻辑
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// 计算退避时间
-			backoff := time.Duration(float64(attempt) * c.config.RetryBackoff.Seconds() * float64(time.Second))
-			logger.Printf("第 %d 次重试,退避时间: %.2fs", attempt, backoff.Seconds())
-			time.Sleep(backoff)
-		}
+// UnmarshalJSON 兼容两种 instances 格式,统一解析为 []SubsystemInstance,并保留原始数据:
+//   - 旧版本: [{"<地址>": ["<文件>", ...]}]
+//   - 新版本: [{"address": "<地址>", "files": ["<文件>", ...]}]
+func (r *SubsystemDetailResult) UnmarshalJSON(data []byte) error {
+	type plain SubsystemDetailResult
+	var raw struct {
+		plain
+		Instances json.RawMessage `json:"instances"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
 
-		// 构建完整URL
-		fullURL := c.config.BaseURL + endpoint
+	instances, err := decodeSubsystemInstances(raw.Instances)
+	if err != nil {
+		return fmt.Errorf("instances: %w", err)
+	}
+	*r = SubsystemDetailResult(raw.plain)
+	r.Instances = instances
+	r.RawInstances = raw.Instances
+	return nil
+}
 
-		// 创建请求
-		var req *http.Request
-		var err error
+// decodeSubsystemInstances 逐个解析实例: 含 address 字段的按对象格式解析,
+// 否则按 地址 -> 文件列表 的映射解析 (一个映射含多个地址时按地址排序展开)
+func decodeSubsystemInstances(data json.RawMessage) ([]SubsystemInstance, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
 
-		if body != nil {
-			req, err = http.NewRequestWithContext(ctx, method, fullURL, bytes.NewReader(body))
-			if err != nil {
-				return nil, fmt.Errorf("创建请求失败: %w", err)
+	instances := make([]SubsystemInstance, 0, len(items))
+	for i, item := range items {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(item, &fields); err != nil {
+			return nil, fmt.Errorf("第 %d 个实例: %w", i, err)
+		}
+		if _, ok := fields["address"]; ok {
+			var instance SubsystemInstance
+			if err := json.Unmarshal(item, &instance); err != nil {
+				return nil, fmt.Errorf("第 %d 个实例: %w", i, err)
 			}
-			req.Header.Set("Content-Type", "application/json")
-		} else {
-			req, err = http.NewRequestWithContext(ctx, method, fullURL, nil)
-			if err != nil {
-				return nil, fmt.Errorf("创建请求失败: %w", err)
+			instances = append(instances, instance)
+			continue
+		}
+
+		var byAddress map[string][]string
+		if err := json.Unmarshal(item, &byAddress); err != nil {
+			return nil, fmt.Errorf("第 %d 个实例: %w", i, err)
+		}
+		addresses := make([]string, 0, len(byAddress))
+		for address := range byAddress {
+			addresses = append(addresses, address)
+		}
+		sort.Strings(addresses)
+		for _, address := range addresses {
+			instances = append(instances, SubsystemInstance{Address: address, Files: byAddress[address]})
+		}
+	}
+	return instances, nil
+}
+
+// APIResponse 通用API响应
+type APIResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result,omitempty"` // 原始数据,由各方法解析为具体类型
+}
+
+// ==================== HTTP 请求方法 ====================
+
+// defaultEnvelopeKey 响应中数据所在的默认字段名
+const defaultEnvelopeKey = "result"
+
+// extractEnvelope 按 key 从响应中取出数据填入 Result
+// 部分接口使用 data 等其他字段包装数据,key 为空或 result 时沿用默认解析结果;
+// 配置的字段不存在而存在其他常见字段时记录警告,避免静默解析出空结果
+func extractEnvelope(body []byte, key string, apiResp *APIResponse) error {
+	if key == "" || key == defaultEnvelopeKey {
+		if len(apiResp.Result) == 0 {
+			warnEnvelopeMismatch(body, defaultEnvelopeKey)
+		}
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	payload, ok := fields[key]
+	if !ok {
+		apiResp.Result = nil
+		warnEnvelopeMismatch(body, key)
+		return nil
+	}
+	apiResp.Result = payload
+	return nil
+}
+
+// warnEnvelopeMismatch 数据字段缺失但存在其他常见包装字段时记录警告
+func warnEnvelopeMismatch(body []byte, key string) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return
+	}
+	for _, other := range []string{"result", "data"} {
+		if _, ok := fields[other]; ok && other != key {
+			logger.Printf("⚠️  响应中没有字段 %q,但存在 %q,请检查 envelope_key 配置", key, other)
+			return
+		}
+	}
+}
+
+// APIError 服务端返回的错误: HTTP 4xx/5xx 或业务错误码
+// HTTP 错误时 Code 为 0; 业务错误时 HTTPStatus 为实际状态码 (通常为 200)
+type APIError struct {
+	HTTPStatus int
+	Code       int
+	Message    string
+	Body       string
+	Attempts   int // 得到该响应时的尝试次数,大于 1 表示之前的尝试失败后重试过
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.HTTPStatus >= 500:
+		return fmt.Sprintf("服务器错误: %d - %s", e.HTTPStatus, e.Body)
+	case e.HTTPStatus >= 400:
+		return fmt.Sprintf("客户端错误: %d - %s", e.HTTPStatus, e.Body)
+	default:
+		return fmt.Sprintf("API错误 (code %d): %s", e.Code, e.Message)
+	}
+}
+
+// AsAPIError 从错误链中取出 *APIError
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound 错误是否为 HTTP 404
+func IsNotFound(err error) bool {
+	return statusCodeOf(err) == http.StatusNotFound
+}
+
+// statusCodeOf 返回错误对应的 HTTP 错误状态码,非 HTTP 错误 (包括业务错误码) 返回 0
+func statusCodeOf(err error) int {
+	if apiErr, ok := AsAPIError(err); ok && apiErr.HTTPStatus >= 400 {
+		return apiErr.HTTPStatus
+	}
+	return 0
+}
+
+// ErrMaintenance 服务端处于维护窗口,可用 errors.Is 判断,
+// 用 errors.As 取出 *MaintenanceError 获取预计结束时间
+var ErrMaintenance = errors.New("服务端维护中")
+
+// MaintenanceError 服务端维护错误 (HTTP 503 且响应体 maintenance 为 true)
+type MaintenanceError struct {
+	Until   time.Time // 预计结束时间,服务端未提供或无法解析时为零值
+	Message string
+}
+
+func (e *MaintenanceError) Error() string {
+	msg := "服务端维护中"
+	if !e.Until.IsZero() {
+		msg += ",预计 " + e.Until.Local().Format("2006-01-02 15:04:05") + " 结束"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *MaintenanceError) Is(target error) bool {
+	return target == ErrMaintenance
+}
+
+// parseMaintenance 判断 503 响应是否为维护窗口,维护期间重试没有意义
+// 结束时间兼容 until / endTime / estimatedEndTime 字段,支持 RFC3339 及 "2006-01-02 15:04:05" 格式
+func parseMaintenance(statusCode int, body []byte) (*MaintenanceError, bool) {
+	if statusCode != http.StatusServiceUnavailable {
+		return nil, false
+	}
+	var payload struct {
+		Maintenance      bool   `json:"maintenance"`
+		Message          string `json:"message"`
+		Until            string `json:"until"`
+		EndTime          string `json:"endTime"`
+		EstimatedEndTime string `json:"estimatedEndTime"`
+	}
+	if json.Unmarshal(body, &payload) != nil || !payload.Maintenance {
+		return nil, false
+	}
+
+	m := &MaintenanceError{Message: payload.Message}
+	for _, value := range []string{payload.Until, payload.EndTime, payload.EstimatedEndTime} {
+		if value == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			m.Until = t
+		} else if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+			m.Until = t
+		} else if m.Message == "" {
+			m.Message = "预计结束时间: " + value
+		}
+		break
+	}
+	return m, true
+}
+
+// parseRetryAfter 解析 Retry-After 头,支持秒数与 HTTP 日期两种格式
+// 日期早于 now 时返回 0; 头不存在或无法解析时 ok 为 false
+func parseRetryAfter(value string, now time.Time) (d time.Duration, ok bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d = t.Sub(now); d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// 常用请求体类型
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeForm = "application/x-www-form-urlencoded"
+)
+
+// requestOptions 单次请求的可选参数
+type requestOptions struct {
+	contentType  string
+	envelopeKey  string
+	pollInterval time.Duration // > 0 时对 202 Accepted 响应轮询 Location 直到任务结束
+	noCache      bool
+	noRetry      bool
+	header       *http.Header // 非 nil 时写入成功响应的响应头
+	read         bool         // 查询请求 (与 method 无关),演练模式下照常发送
+}
+
+// RequestOption 单次请求选项
+type RequestOption func(*requestOptions)
+
+// WithContentType 指定请求体的 Content-Type (默认 application/json)
+func WithContentType(contentType string) RequestOption {
+	return func(o *requestOptions) {
+		o.contentType = contentType
+	}
+}
+
+// WithEnvelopeKey 指定本次请求响应中数据所在的字段名,覆盖 Config.EnvelopeKey
+func WithEnvelopeKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.envelopeKey = key
+	}
+}
+
+// WithAsyncPoll 服务端返回 202 Accepted 并带有 Location 时,每隔 interval 轮询该任务资源,
+// 直到任务完成 (返回任务结果) 或失败 (返回 *AsyncJobError);轮询总时长由 ctx 控制
+// 未指定时 202 与 200 一样立即返回
+func WithAsyncPoll(interval time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.pollInterval = interval
+	}
+}
+
+// withoutCache 本次请求不读写响应缓存
+func withoutCache() RequestOption {
+	return func(o *requestOptions) {
+		o.noCache = true
+	}
+}
+
+// withoutRetry 本次请求失败时不重试
+func withoutRetry() RequestOption {
+	return func(o *requestOptions) {
+		o.noRetry = true
+	}
+}
+
+// withResponseHeader 成功时将响应头写入 header,需配合 withoutCache 使用 (缓存命中时没有响应头)
+func withResponseHeader(header *http.Header) RequestOption {
+	return func(o *requestOptions) {
+		o.header = header
+	}
+}
+
+// Do 执行任意接口请求,返回原始响应,用于客户端尚未封装的接口
+//
+//	form := url.Values{"status": {"enable"}}
+//	resp, err := client.Do(ctx, "POST", "/operation/legacy", []byte(form.Encode()), WithContentType(ContentTypeForm))
+func (c *Client) Do(ctx context.Context, method, endpoint string, body []byte, opts ...RequestOption) (*APIResponse, error) {
+	return c.doRequest(ctx, method, endpoint, body, opts...)
+}
+
+// newRequest 创建 HTTP 请求
+//
+// 请求体均为内存中的 []byte,这里显式设置 Content-Length 并提供 GetBody,
+// 保证请求不会退化为 chunked 编码 (部分网关会拒绝 chunked 请求),重定向时也可重放请求体。
+// 如需发送流式请求体 (长度未知的 io.Reader),应先读入内存,或在请求上显式设置 ContentLength,
+// 否则 net/http 会使用 chunked 编码发送。
+// 响应压缩由 net/http 的 Transport 自动协商 (Accept-Encoding: gzip) 并透明解压。
+func newRequest(ctx context.Context, method, fullURL string, body []byte, contentType string) (*http.Request, error) {
+	if body == nil {
+		return http.NewRequestWithContext(ctx, method, fullURL, nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
+
+// defaultMaxResponseBytes 默认的单个响应大小上限
+const defaultMaxResponseBytes = 64 << 20
+
+// ErrResponseTooLarge 响应 (解压后) 超过 MaxResponseBytes
+var ErrResponseTooLarge = errors.New("响应超过大小上限")
+
+// readResponseBody 读取响应体,最多读取 limit 字节 (<= 0 时使用默认上限)
+// 服务端返回 gzip 压缩内容且未被 Transport 自动解压时在此解压,
+// 上限作用于解压后的数据,防止压缩炸弹耗尽内存
+func readResponseBody(resp *http.Response, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		limit = defaultMaxResponseBytes
+	}
+
+	var body io.Reader = resp.Body
+	if !resp.Uncompressed && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("解压响应失败: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	// 多读 1 字节以区分恰好等于上限和超出上限
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: 超过 %d 字节", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
+
+// defaultBasePath 接口路径中的默认前缀
+const defaultBasePath = "/operation"
+
+// endpointURL 构建完整 URL,配置了 BasePath 时替换接口路径的默认前缀
+func (c *Client) endpointURL(endpoint string) string {
+	basePath := strings.TrimSuffix(c.config.BasePath, "/")
+	if basePath != "" && basePath != defaultBasePath && strings.HasPrefix(endpoint, defaultBasePath+"/") {
+		endpoint = basePath + strings.TrimPrefix(endpoint, defaultBasePath)
+	}
+	return c.config.BaseURL + endpoint
+}
+
+// defaultMaxURLLength 未配置 MaxURLLength 时的 URL 长度上限,常见服务端/代理的限制为 8KB
+const defaultMaxURLLength = 8192
+
+// withParams 将 params 附加到请求: 完整 URL 不超过 MaxURLLength 时放在查询串中,
+// 否则改为 POST 并以表单发送 (接口需支持 POST 表单参数),返回实际使用的方法、接口、请求体及选项
+func (c *Client) withParams(method, endpoint string, params url.Values) (string, string, []byte, []RequestOption) {
+	query := params.Encode()
+	if query == "" {
+		return method, endpoint, nil, nil
+	}
+
+	maxLength := c.config.MaxURLLength
+	if maxLength <= 0 {
+		maxLength = defaultMaxURLLength
+	}
+	if length := len(c.endpointURL(endpoint)) + 1 + len(query); length > maxLength {
+		logger.Printf("URL 长度 %d 超过上限 %d,改用 POST 表单发送参数: %s %s", length, maxLength, method, endpoint)
+		opts := []RequestOption{WithContentType(ContentTypeForm)}
+		if method == "GET" {
+			opts = append(opts, asRead())
+		}
+		return "POST", endpoint, []byte(query), opts
+	}
+	return method, endpoint + "?" + query, nil, nil
+}
+
+// isSuccessCode 业务码是否表示成功,未配置 SuccessCodes 时只有 0 表示成功
+func (c *Client) isSuccessCode(code int) bool {
+	if len(c.config.SuccessCodes) == 0 {
+		return code == 0
+	}
+	for _, success := range c.config.SuccessCodes {
+		if code == success {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRetryableStatus 默认重试 5xx、408 (请求超时) 及 429 (限流)
+func DefaultRetryableStatus(statusCode int) bool {
+	return statusCode >= 500 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+}
+
+// retryableStatus 状态码是否需要重试
+func (c *Config) retryableStatus(statusCode int) bool {
+	if c.RetryableStatus != nil {
+		return c.RetryableStatus(statusCode)
+	}
+	return DefaultRetryableStatus(statusCode)
+}
+
+// statusRetries 可重试状态码的最大重试次数
+func (c *Config) statusRetries() int {
+	if c.MaxStatusRetries > 0 {
+		return c.MaxStatusRetries
+	}
+	return c.MaxRetries
+}
+
+// connRetries 连接/读取失败的最大重试次数
+func (c *Config) connRetries() int {
+	if c.MaxConnRetries > 0 {
+		return c.MaxConnRetries
+	}
+	return c.MaxRetries
+}
+
+// defaultRetryBackoffMax 未配置 RetryBackoffMax 时的单次退避上限
+const defaultRetryBackoffMax = 30 * time.Second
+
+// retryBackoff 第 attempt 次重试前的等待时间: RetryBackoff * 2^(attempt-1),不超过 RetryBackoffMax
+// 开启 RetryJitter 时在 [0, 该值] 内均匀随机 (full jitter)
+func (c *Client) retryBackoff(attempt int) time.Duration {
+	maxBackoff := c.config.RetryBackoffMax
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryBackoffMax
+	}
+
+	backoff := c.config.RetryBackoff
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	if c.config.RetryJitter && backoff > 0 {
+		backoff = time.Duration(rand.Int63n(int64(backoff) + 1))
+	}
+	return backoff
+}
+
+// defaultRetryAfterMax 未配置 RetryAfterMax 时 Retry-After 的等待上限
+const defaultRetryAfterMax = 2 * time.Minute
+
+// retryAfterWait 服务端 Retry-After 指定的等待时间,不超过 RetryAfterMax
+func (c *Client) retryAfterWait(d time.Duration) time.Duration {
+	maxWait := c.config.RetryAfterMax
+	if maxWait <= 0 {
+		maxWait = defaultRetryAfterMax
+	}
+	if d > maxWait {
+		logger.Printf("Retry-After %s 超过上限,按 %s 等待", d, maxWait)
+		return maxWait
+	}
+	return d
+}
+
+// attemptContext 为单次请求尝试派生带 Config.Timeout 超时的 context
+// 整体截止时间 (包括所有重试) 由调用方传入的 ctx 控制
+func (c *Client) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.config.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.config.Timeout)
+}
+
+// doRequest 执行HTTP请求 (带重试机制)
+// 每次尝试的超时为 Config.Timeout,ctx 结束后不再重试
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, body []byte, opts ...RequestOption) (result *APIResponse, err error) {
+	options := requestOptions{contentType: ContentTypeJSON, envelopeKey: c.config.EnvelopeKey}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	// 演练模式下变更请求不发送,也不记录审计
+	if c.skipForDryRun(method, &options) {
+		c.printDryRun(method, endpoint, body)
+		return &APIResponse{Message: "dry-run"}, nil
+	}
+	defer func() { c.auditRequest(method, endpoint, err) }()
+	if c.initErr != nil {
+		return nil, c.initErr
+	}
+
+	// 仅缓存 GET 请求
+	cacheable := c.cache != nil && method == "GET" && body == nil && !options.noCache
+	if cacheable {
+		if cached, ok := c.cache.get(endpoint); ok {
+			return cached, nil
+		}
+	}
+
+	// 同一次调用的所有重试使用相同的 X-Request-ID
+	ctx = c.withRequestID(ctx)
+
+	var lastErr error
+	var reasons []string // 每次失败的原因分类
+
+	// 重试逻辑: 连接失败与可重试状态码分别计数,任一超过各自上限即停止
+	maxConn, maxStatus := c.config.connRetries(), c.config.statusRetries()
+	if options.noRetry {
+		maxConn, maxStatus = 0, 0
+	}
+	var connFailures, statusFailures int
+	var retryAfter time.Duration // 服务端通过 Retry-After 指定的等待时间
+	var hasRetryAfter bool
+	for attempt := 0; connFailures <= maxConn && statusFailures <= maxStatus; attempt++ {
+		if attempt > 0 {
+			// 计算退避时间,服务端指定了 Retry-After 时以其为准
+			backoff := c.retryBackoff(attempt)
+			if hasRetryAfter {
+				backoff, hasRetryAfter = c.retryAfterWait(retryAfter), false
+				// 等待超过 ctx 剩余时间时直接返回,不空等到截止时间
+				if deadline, ok := ctx.Deadline(); ok && backoff > time.Until(deadline) {
+					return nil, fmt.Errorf("服务端要求 %s 后重试,超过剩余时间: %w", backoff, lastErr)
+				}
+			}
+			logger.Printf("第 %d 次重试,退避时间: %.2fs", attempt, backoff.Seconds())
+			c.retries.record(c.clock.Now())
+			c.metrics.ObserveRetry(method, metricsEndpoint(endpoint), reasons[len(reasons)-1])
+			if err := sleepContext(ctx, c.clock, backoff); err != nil {
+				return nil, fmt.Errorf("等待重试时取消: %w", err)
 			}
 		}
 
-		// 设置Basic Auth
-		req.SetBasicAuth(c.config.Username, c.config.Password)
+		// 客户端限流,每次尝试 (含重试) 都计入
+		if c.limiter != nil {
+			if err := waitRateLimit(ctx, c.clock, c.limiter); err != nil {
+				return nil, fmt.Errorf("等待限流时取消: %w", err)
+			}
+		}
+
+		// 构建完整URL
+		fullURL := c.endpointURL(endpoint)
+
+		// 创建请求,每次尝试单独计算超时
+		attemptCtx, cancel := c.attemptContext(ctx)
+		req, err := newRequest(attemptCtx, method, fullURL, body, options.contentType)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("创建请求失败: %w", err)
+		}
+
+		// 设置认证信息及自定义请求头,签名在每次尝试时重新计算 (时间戳不同)
+		c.setAuth(req)
+		c.setHeaders(req)
+		if err := c.signRequest(req, body); err != nil {
+			cancel()
+			return nil, err
+		}
 
 		// 发送请求
+		sent := c.clock.Now()
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			err = redactURLError(err, c.config.SensitiveParams)
+			elapsed := c.clock.Now().Sub(sent)
+			c.network.record(elapsed)
+			c.metrics.ObserveRequest(method, metricsEndpoint(endpoint), 0, elapsed)
+			cancel()
+			if ctx.Err() != nil {
+				// 调用方已取消或超过整体截止时间,不再重试
+				return nil, fmt.Errorf("请求失败: %w", err)
+			}
 			lastErr = fmt.Errorf("请求失败: %w", err)
-			logger.Printf("请求失败 (尝试 %d/%d): %v", attempt+1, c.config.MaxRetries+1, err)
+			reasons = append(reasons, classifyRetryError(err))
+			connFailures++
+			logger.Printf("请求失败 (连接失败 %d/%d): %v", connFailures, maxConn+1, err)
 			continue
 		}
 
 		// 读取响应
-		respBody, err := io.ReadAll(resp.Body)
+		respBody, err := readResponseBody(resp, c.config.MaxResponseBytes)
 		resp.Body.Close()
-
+		cancel()
+		elapsed := c.clock.Now().Sub(sent)
+		c.network.record(elapsed)
+		c.metrics.ObserveRequest(method, metricsEndpoint(endpoint), resp.StatusCode, elapsed)
+
+		if errors.Is(err, ErrResponseTooLarge) {
+			// 重试也会得到同样大小的响应
+			return nil, err
+		}
+		if err != nil && ctx.Err() != nil {
+			return nil, fmt.Errorf("读取响应失败: %w", err)
+		}
 		if err != nil {
 			lastErr = fmt.Errorf("读取响应失败: %w", err)
-			logger.Printf("读取响应失败 (尝试 %d/%d): %v", attempt+1, c.config.MaxRetries+1, err)
+			reasons = append(reasons, classifyRetryError(err))
+			connFailures++
+			logger.Printf("读取响应失败 (连接失败 %d/%d): %v", connFailures, maxConn+1, err)
+			continue
+		}
+
+		// 维护窗口内不重试
+		if m, ok := parseMaintenance(resp.StatusCode, respBody); ok {
+			return nil, m
+		}
+
+		// 可重试的状态码 (默认 5xx/408/429),429/503 按 Retry-After 等待
+		if c.config.retryableStatus(resp.StatusCode) {
+			lastErr = &APIError{HTTPStatus: resp.StatusCode, Body: string(respBody), Attempts: attempt + 1}
+			reasons = append(reasons, classifyRetryStatus(resp.StatusCode))
+			statusFailures++
+			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+				retryAfter, hasRetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), c.clock.Now())
+			}
+			logger.Printf("请求失败,可重试 (状态码重试 %d/%d): %d", statusFailures, maxStatus+1, resp.StatusCode)
 			continue
 		}
 
-		// 检查HTTP状态码
-		if resp.StatusCode >= 500 {
-			lastErr = fmt.Errorf("服务器错误: %d - %s", resp.StatusCode, string(respBody))
-			logger.Printf("服务器错误 (尝试 %d/%d): %d", attempt+1, c.config.MaxRetries+1, resp.StatusCode)
-			continue // 服务器错误,重试
-		}
+		if resp.StatusCode >= 400 {
+			// 不可重试的错误
+			return nil, &APIError{HTTPStatus: resp.StatusCode, Body: string(respBody), Attempts: attempt + 1}
+		}
+
+		// 异步操作: 按需轮询任务资源
+		if resp.StatusCode == http.StatusAccepted && options.pollInterval > 0 {
+			if location := resp.Header.Get("Location"); location != "" {
+				return c.pollAsyncJob(ctx, location, options)
+			}
+		}
+
+		// 解析响应
+		var apiResp APIResponse
+		if err := c.decoder.Unmarshal(respBody, &apiResp); err != nil {
+			return nil, fmt.Errorf("解析响应失败: %w (响应: %s)", err, truncateCell(string(respBody), 200))
+		}
+		if err := extractEnvelope(respBody, options.envelopeKey, &apiResp); err != nil {
+			return nil, err
+		}
+
+		// 检查业务错误码
+		if !c.isSuccessCode(apiResp.Code) {
+			return &apiResp, &APIError{
+				HTTPStatus: resp.StatusCode,
+				Code:       apiResp.Code,
+				Message:    apiResp.Message,
+				Body:       string(respBody),
+				Attempts:   attempt + 1,
+			}
+		}
+
+		// 成功
+		if attempt > 0 {
+			logger.Printf("请求成功 (重试 %d 次后)", attempt)
+		}
+		if cacheable {
+			c.cache.put(endpoint, &apiResp)
+		}
+		if options.header != nil {
+			*options.header = resp.Header
+		}
+		return &apiResp, nil
+	}
+
+	return nil, newRetryExhaustedError(reasons, lastErr)
+}
+
+// ==================== 通用解析 ====================
+
+// JSONDecoder 响应 JSON 解析器,默认使用 encoding/json
+// 子系统清单等大响应解析较慢时,可通过 SetJSONDecoder 替换为更快的实现,例如 json-iterator:
+//
+//	var jsoniterDecoder = JSONDecoderFunc(jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal)
+//	client.SetJSONDecoder(jsoniterDecoder)
+//
+// 替换的实现需与 encoding/json 兼容 (json 标签、json.RawMessage 及 UnmarshalJSON)
+type JSONDecoder interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONDecoderFunc 将函数适配为 JSONDecoder
+type JSONDecoderFunc func(data []byte, v interface{}) error
+
+func (f JSONDecoderFunc) Unmarshal(data []byte, v interface{}) error {
+	return f(data, v)
+}
+
+// stdJSONDecoder 默认解析器
+var stdJSONDecoder JSONDecoder = JSONDecoderFunc(json.Unmarshal)
+
+// SetJSONDecoder 替换响应解析器,nil 表示恢复为 encoding/json
+func (c *Client) SetJSONDecoder(decoder JSONDecoder) {
+	if decoder == nil {
+		decoder = stdJSONDecoder
+	}
+	c.decoder = decoder
+}
+
+// decodeResult 将响应中的 Result 解析到 v,支持对象、数组以及数字、字符串等基本类型
+// 响应中没有数据字段时 v 保持不变
+func (c *Client) decodeResult(resp *APIResponse, v interface{}) error {
+	if len(resp.Result) == 0 {
+		return nil
+	}
+	if err := c.decoder.Unmarshal(resp.Result, v); err != nil {
+		return fmt.Errorf("解析响应结果失败: %w (result: %s)", err, resp.Result)
+	}
+	return nil
+}
+
+// GetInto 请求 GET 接口并将 Result 解析为类型 T,适用于客户端尚未封装的接口,
+// 包括直接返回基本类型的接口 (如 "result": 42 或 "result": "ok")
+//
+//	count, err := GetInto[int](ctx, client, "/operation/subsystems/count")
+func GetInto[T any](ctx context.Context, c *Client, endpoint string, opts ...RequestOption) (T, error) {
+	var result T
+	resp, err := c.doRequest(ctx, "GET", endpoint, nil, opts...)
+	if err != nil {
+		return result, err
+	}
+	err = c.decodeResult(resp, &result)
+	return result, err
+}
+
+// GetCount 请求返回计数的接口,兼容数字及数字字符串 (如 42 或 "42")
+func (c *Client) GetCount(ctx context.Context, endpoint string) (int64, error) {
+	value, err := GetInto[json.Number](ctx, c, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	count, err := value.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("计数结果不是整数: %q", value)
+	}
+	return count, nil
+}
+
+// ==================== 异步任务 ====================
+
+// 异步任务状态
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// AsyncJob 202 Accepted 响应的 Location 指向的任务资源
+type AsyncJob struct {
+	ID      string          `json:"id,omitempty"`
+	Status  string          `json:"status"`
+	Message string          `json:"message,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"` // 任务完成后的结果
+}
+
+// AsyncJobError 异步任务执行失败
+type AsyncJobError struct {
+	Location string
+	Job      AsyncJob
+}
+
+func (e *AsyncJobError) Error() string {
+	return fmt.Sprintf("异步任务失败 (%s): %s", e.Location, e.Job.Message)
+}
+
+// jobEndpoint 将 Location 转换为相对 BaseURL 的接口路径
+// 带主机的地址须与 BaseURL 的协议、主机 (含端口) 完全一致且路径位于 BaseURL 路径之下,
+// 否则拒绝,避免把认证信息发往其他服务器 (按字符串前缀比较会放过 https://weapm.example.com.evil.com 之类的地址)
+func (c *Client) jobEndpoint(location string) (string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("解析任务地址失败: %w", err)
+	}
+	if !u.IsAbs() && u.Host == "" {
+		return u.RequestURI(), nil
+	}
+
+	base, err := url.Parse(c.config.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("解析 BaseURL 失败: %w", err)
+	}
+	basePath := strings.TrimSuffix(base.Path, "/")
+	if !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) ||
+		(basePath != "" && u.Path != basePath && !strings.HasPrefix(u.Path, basePath+"/")) {
+		return "", fmt.Errorf("任务地址 %s 不在 %s 下", location, c.config.BaseURL)
+	}
+	u.Path = strings.TrimPrefix(u.Path, basePath)
+	u.RawPath = ""
+	return u.RequestURI(), nil
+}
+
+// pollAsyncJob 轮询任务资源直到任务完成或失败,完成时返回以任务结果为 Result 的响应
+func (c *Client) pollAsyncJob(ctx context.Context, location string, options requestOptions) (*APIResponse, error) {
+	endpoint, err := c.jobEndpoint(location)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		resp, err := c.doRequest(ctx, "GET", endpoint, nil, WithEnvelopeKey(options.envelopeKey), withoutCache())
+		if err != nil {
+			return nil, fmt.Errorf("查询异步任务失败: %w", err)
+		}
+
+		var job AsyncJob
+		if err := c.decodeResult(resp, &job); err != nil {
+			return nil, fmt.Errorf("解析异步任务失败: %w", err)
+		}
+		switch job.Status {
+		case JobDone:
+			return &APIResponse{Code: resp.Code, Message: resp.Message, Result: job.Result}, nil
+		case JobFailed:
+			return nil, &AsyncJobError{Location: location, Job: job}
+		case JobPending, JobRunning:
+		default:
+			// 未知状态继续轮询可能永远等不到结束
+			return nil, fmt.Errorf("异步任务 %s 返回未知状态 %q", location, job.Status)
+		}
+
+		logger.Printf("异步任务 %s 状态: %s, %.1fs 后重新查询", location, job.Status, options.pollInterval.Seconds())
+		if err := sleepContext(ctx, c.clock, options.pollInterval); err != nil {
+			return nil, fmt.Errorf("等待异步任务时取消: %w", err)
+		}
+	}
+}
+
+// ==================== 连通性检查 ====================
+
+// pingEndpoint 连通性检查使用的轻量接口
+const pingEndpoint = "/operation/clusters"
+
+// Ping 检查服务端是否可达 (单次请求,不重试)
+// 服务端返回非 5xx 状态码即视为可达
+func (c *Client) Ping(ctx context.Context) error {
+	ctx, cancel := c.attemptContext(c.withRequestID(ctx))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpointURL(pingEndpoint), nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	c.setAuth(req)
+	c.setHeaders(req)
+	if err := c.signRequest(req, nil); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("服务不可达: %w", err)
+	}
+	// 只关心状态码,丢弃响应体时同样受 MaxResponseBytes 限制,避免异常服务端无限输出
+	limit := c.config.MaxResponseBytes
+	if limit <= 0 {
+		limit = defaultMaxResponseBytes
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, limit))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("服务不可用: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WaitReady 阻塞直到服务端可达或超时
+// 以指数退避轮询 Ping,适用于嵌入长期运行的服务时在启动阶段确认连通性
+func (c *Client) WaitReady(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := c.config.RetryBackoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	const maxBackoff = 5 * time.Second
+
+	for {
+		err := c.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		logger.Printf("服务尚未就绪,%.2fs 后重试: %v", backoff.Seconds(), err)
+
+		if sleepContext(ctx, c.clock, backoff) != nil {
+			return fmt.Errorf("等待服务就绪超时 (%s): %w", timeout, err)
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// ==================== 服务端能力 ====================
+
+// Capabilities 服务端能力声明 (/operation/capabilities)
+type Capabilities struct {
+	ServerVersion    string `json:"serverVersion"`
+	Pagination       bool   `json:"pagination"`       // 列表接口支持分页
+	BatchNodes       bool   `json:"batchNodes"`       // 支持批量添加节点
+	ProblemJSON      bool   `json:"problemJson"`      // 错误响应使用 application/problem+json
+	NodeMove         bool   `json:"nodeMove"`         // 支持节点迁移接口
+	ScopedNodeDelete bool   `json:"scopedNodeDelete"` // 删除节点接口支持 clustername 参数限定集群
+
+	// Detected 为 false 表示服务端未提供能力接口,以上字段均为保守的默认值
+	Detected bool `json:"-"`
+}
+
+// minimalCapabilities 服务端不提供能力接口时假定的最小能力集
+var minimalCapabilities = Capabilities{}
+
+// capsFailureTTL 获取能力声明失败后缓存该错误的时长,期间不再请求能力接口
+const capsFailureTTL = 30 * time.Second
+
+// GetCapabilities 获取服务端能力声明,成功后缓存在客户端中
+// 能力接口只是探测,请求不重试; 服务端不支持该接口 (404) 时返回最小能力集并缓存,
+// 其他错误缓存 capsFailureTTL,避免每个依赖能力声明的调用都重复等待失败的请求
+func (c *Client) GetCapabilities(ctx context.Context) (*Capabilities, error) {
+	c.capsMu.Lock()
+	if c.caps != nil {
+		caps := *c.caps
+		c.capsMu.Unlock()
+		return &caps, nil
+	}
+	if c.capsErr != nil && c.clock.Now().Sub(c.capsErrAt) < capsFailureTTL {
+		err := c.capsErr
+		c.capsMu.Unlock()
+		return nil, err
+	}
+	c.capsMu.Unlock()
+
+	// 请求期间不持有锁,并发调用可能各自请求一次
+	caps, err := GetInto[Capabilities](ctx, c, "/operation/capabilities", withoutRetry())
+	switch {
+	case err == nil:
+		caps.Detected = true
+	case IsNotFound(err):
+		logger.Printf("服务端未提供能力接口,按最小能力集处理")
+		caps = minimalCapabilities
+	default:
+		// 调用方取消或超时不代表服务端异常,不缓存
+		if ctx.Err() == nil {
+			c.capsMu.Lock()
+			c.capsErr, c.capsErrAt = err, c.clock.Now()
+			c.capsMu.Unlock()
+		}
+		return nil, err
+	}
+
+	c.capsMu.Lock()
+	c.caps, c.capsErr = &caps, nil
+	c.capsMu.Unlock()
+	result := caps
+	return &result, nil
+}
+
+// capabilities 同 GetCapabilities,但获取失败 (超时、5xx 等) 时记录日志并按最小能力集 (Detected=false) 处理,
+// 使依赖能力声明的方法回退到原有行为
+func (c *Client) capabilities(ctx context.Context) *Capabilities {
+	caps, err := c.GetCapabilities(ctx)
+	if err != nil {
+		logger.Printf("⚠️  获取服务端能力失败,按未知能力处理: %v", err)
+		caps := minimalCapabilities
+		return &caps
+	}
+	return caps
+}
+
+// paginationUnsupported 服务端是否明确声明列表接口不支持分页,能力未知时按支持处理
+func (c *Client) paginationUnsupported(ctx context.Context) bool {
+	caps := c.capabilities(ctx)
+	return caps.Detected && !caps.Pagination
+}
+
+// pageOf 在客户端按 offset/limit 截取 items,limit <= 0 表示 offset 之后的全部
+func pageOf[T any](items []T, offset, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return items[offset:end]
+}
+
+// ==================== 数据大盘 API ====================
+
+// GetDashboardRaw 获取数据大盘原始 JSON,不依赖结构体定义
+func (c *Client) GetDashboardRaw(ctx context.Context) (json.RawMessage, error) {
+	resp, err := c.doRequest(ctx, "GET", "/operation/dashboard", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw json.RawMessage
+	if err := c.decodeResult(resp, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// GetDashboard 获取数据大盘信息
+// 各字段独立解析,某一部分格式异常时不影响其余数据,失败的部分记录在 FailedSections 中
+func (c *Client) GetDashboard(ctx context.Context) (*DashboardResult, error) {
+	raw, err := c.GetDashboardRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDashboard(raw)
+}
+
+// decodeDashboard 逐字段解析数据大盘,仅当整体不是 JSON 对象时返回错误
+func decodeDashboard(raw json.RawMessage) (*DashboardResult, error) {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &sections); err != nil {
+		return nil, fmt.Errorf("解析数据大盘失败: %w", err)
+	}
+
+	var result DashboardResult
+	targets := []struct {
+		key string
+		dst interface{}
+	}{
+		{"subsystemCount", &result.SubsystemCount},
+		{"clusterNum", &result.ClusterNum},
+		{"clusterTrafficData", &result.ClusterTrafficData},
+		{"topSubsystems", &result.TopSubsystems},
+		{"clusterLogCounts", &result.ClusterLogCounts},
+	}
+	for _, t := range targets {
+		data, ok := sections[t.key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(data, t.dst); err != nil {
+			logger.Printf("数据大盘字段 %s 解析失败: %v", t.key, err)
+			result.FailedSections = append(result.FailedSections, t.key)
+		}
+	}
+
+	return &result, nil
+}
+
+// ==================== 集群管理 API ====================
+
+// GetClusters 获取所有集群信息
+func (c *Client) GetClusters(ctx context.Context) ([]LogClusterInfo, error) {
+	resp, err := c.doRequest(ctx, "GET", "/operation/clusters", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []LogClusterInfo
+	if err := c.decoder.Unmarshal(resp.Result, &clusters); err != nil {
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+// 默认集群相关错误
+var (
+	ErrNoDefaultCluster        = errors.New("没有集群被标记为默认集群")
+	ErrMultipleDefaultClusters = errors.New("多个集群被标记为默认集群")
+)
+
+// GetDefaultCluster 返回 IsDefault == 1 的集群
+// 没有或存在多个默认集群时分别返回 ErrNoDefaultCluster / ErrMultipleDefaultClusters
+func (c *Client) GetDefaultCluster(ctx context.Context) (*LogClusterInfo, error) {
+	clusters, err := c.GetClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaults []LogClusterInfo
+	for _, cluster := range clusters {
+		if cluster.IsDefault == 1 {
+			defaults = append(defaults, cluster)
+		}
+	}
+	switch len(defaults) {
+	case 0:
+		return nil, ErrNoDefaultCluster
+	case 1:
+		return &defaults[0], nil
+	default:
+		names := make([]string, len(defaults))
+		for i, cluster := range defaults {
+			names[i] = cluster.ClusterName
+		}
+		return nil, fmt.Errorf("%w: %s", ErrMultipleDefaultClusters, strings.Join(names, ", "))
+	}
+}
+
+// DefaultClusterNamePattern 默认的集群名称格式: LOG + 数字 (如 LOG001)
+const DefaultClusterNamePattern = `^LOG\d+$`
+
+// ErrInvalidClusterName 集群名称不符合配置的格式
+var ErrInvalidClusterName = errors.New("集群名称格式无效")
+
+// compileClusterNamePattern 编译集群名称格式,pattern 为空时使用默认格式
+// 格式无效时记录警告并关闭校验
+func compileClusterNamePattern(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		pattern = DefaultClusterNamePattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		logger.Printf("⚠️  集群名称格式 %q 无效,已关闭校验: %v", pattern, err)
+		return nil
+	}
+	return re
+}
+
+// validateClusterName 在发送请求前校验集群名称格式,避免拼写错误的名称请求到服务端后才返回 404
+// 关闭校验 (SkipClusterNameCheck) 时不做检查
+func (c *Client) validateClusterName(name string) error {
+	if c.clusterNameRE == nil || c.clusterNameRE.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q 不匹配 %s", ErrInvalidClusterName, name, c.clusterNameRE)
+}
+
+// GetClusterDetail 获取指定集群的详细信息
+// 集群名称不符合格式时返回 ErrInvalidClusterName
+func (c *Client) GetClusterDetail(ctx context.Context, clusterName string) (*ClusterDetailResult, error) {
+	if err := c.validateClusterName(clusterName); err != nil {
+		return nil, err
+	}
+	resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/operation/clusters/%s", clusterName), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ClusterDetailResult
+	if err := c.decoder.Unmarshal(resp.Result, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetAllClusterDetails 并发获取所有集群的详细信息,按集群列表顺序返回
+// ctx 带截止时间时各请求共享剩余预算,超时未完成的集群对应位置为 nil,
+// 此时仍返回已获取的部分结果及汇总错误
+func (c *Client) GetAllClusterDetails(ctx context.Context) ([]*ClusterDetailResult, error) {
+	clusters, err := c.GetClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]*ClusterDetailResult, len(clusters))
+	errs := forEachWithBudget(ctx, c.clock, len(clusters), defaultConcurrency, func(ctx context.Context, i int) error {
+		detail, err := c.GetClusterDetail(ctx, clusters[i].ClusterName)
+		details[i] = detail
+		return err
+	})
+
+	var failed int
+	var firstErr error
+	for i, err := range errs {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("获取集群 %s 详情失败: %w", clusters[i].ClusterName, err)
+			}
+			failed++
+		}
+	}
+	if firstErr != nil {
+		return details, fmt.Errorf("%d/%d 个集群详情获取失败,首个错误: %w", failed, len(clusters), firstErr)
+	}
+	return details, nil
+}
+
+// AddClusterNodeRequest 向集群添加节点请求参数
+type AddClusterNodeRequest struct {
+	Address        string `json:"address"`         // 必填: 节点IP地址
+	ClusterName    string `json:"clustername"`     // 必填: 集群名称
+	Role           string `json:"role"`            // 必填: 节点角色
+	CpuLimit       string `json:"cpulimit,omitempty"`        // 可选: CPU限制
+	MemLimit       string `json:"memlimit,omitempty"`        // 可选: 内存限制
+	Topic          string `json:"topic,omitempty"`           // 可选: Topic
+	BucketNames    string `json:"bucketnames,omitempty"`     // 可选: 存储桶名称
+	BackendDomain  string `json:"backenddomain,omitempty"`   // 可选: 后端域
+	StorageDomain  string `json:"storagedomain,omitempty"`   // 可选: 存储域
+	IsDefault      bool   `json:"isdefault,omitempty"`       // 可选: 是否默认
+	Status         string `json:"status,omitempty"`          // 可选: 状态
+	CreateTime     string `json:"createtime,omitempty"`      // 可选: 创建时间
+	UpdateTime     string `json:"updateime,omitempty"`       // 可选: 更新时间
+}
+
+// codeNodeExists 节点已存在的业务错误码
+const codeNodeExists = 40901
+
+// ErrNodeExists 节点已存在于集群中
+var ErrNodeExists = errors.New("节点已存在")
+
+// AddClusterNode 向集群添加节点 (简化版,支持部分参数)
+// 节点已存在时 (HTTP 409 或业务码 40901) 返回 ErrNodeExists,集群名称不符合格式时返回 ErrInvalidClusterName
+func (c *Client) AddClusterNode(ctx context.Context, clusterName string, req *AddClusterNodeRequest) error {
+	if err := c.validateClusterName(clusterName); err != nil {
+		return err
+	}
+
+	// 设置集群名称
+	req.ClusterName = clusterName
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("序列化节点数据失败: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", fmt.Sprintf("/operation/clusters/%s/nodes", clusterName), body)
+	if err != nil {
+		if statusCodeOf(err) == http.StatusConflict || (resp != nil && resp.Code == codeNodeExists) {
+			return fmt.Errorf("%w: %s (%s)", ErrNodeExists, req.Address, clusterName)
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateClusterNodeRequest 修改节点请求,未设置的字段不修改
+type UpdateClusterNodeRequest struct {
+	Role          string `json:"role,omitempty"`
+	CpuLimit      string `json:"cpulimit,omitempty"`
+	MemLimit      string `json:"memlimit,omitempty"`
+	Topic         string `json:"topic,omitempty"`
+	BucketNames   string `json:"bucketnames,omitempty"`
+	BackendDomain string `json:"backenddomain,omitempty"`
+	StorageDomain string `json:"storagedomain,omitempty"`
+	Status        string `json:"status,omitempty"`
+}
+
+// Empty 请求中没有任何需要修改的字段
+func (r *UpdateClusterNodeRequest) Empty() bool {
+	return *r == UpdateClusterNodeRequest{}
+}
+
+// UpdateClusterNode 原地修改节点的角色、资源限制等字段,无需删除后重新添加
+func (c *Client) UpdateClusterNode(ctx context.Context, clusterName, address string, req *UpdateClusterNodeRequest) error {
+	if err := c.validateClusterName(clusterName); err != nil {
+		return err
+	}
+	if req.Empty() {
+		return fmt.Errorf("修改节点 %s 需要至少指定一个字段", address)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("序列化节点数据失败: %w", err)
+	}
+
+	_, err = c.doRequest(ctx, "PUT", fmt.Sprintf("/operation/clusters/%s/nodes/%s", clusterName, address), body)
+	return err
+}
+
+// ErrNodeNotFound 集群中不存在指定 IP 的节点
+var ErrNodeNotFound = errors.New("未找到节点")
+
+// LookupClusterNode 通过集群详情的节点组查找节点,用于删除前确认节点存在
+// clusterName 为空时在所有集群中查找,未找到时返回 ErrNodeNotFound
+func (c *Client) LookupClusterNode(ctx context.Context, clusterName, ip string) (*LogStoreInstance, error) {
+	if clusterName == "" {
+		return c.findClusterNode(ctx, ip)
+	}
+
+	nodes, err := c.GetClusterNodes(ctx, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("获取集群 %s 节点失败: %w", clusterName, err)
+	}
+	for i := range nodes {
+		if nodes[i].Address == ip {
+			return &nodes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s (%s)", ErrNodeNotFound, ip, clusterName)
+}
+
+// DeleteClusterNode 从集群删除节点
+func (c *Client) DeleteClusterNode(ctx context.Context, ip string) error {
+	_, err := c.doRequest(ctx, "DELETE", fmt.Sprintf("/operation/clusters/nodes/%s", ip), nil)
+	return ignoreNotFoundAfterRetry(err)
+}
+
+// ignoreNotFoundAfterRetry 删除请求重试后返回 404 时视为成功:
+// 之前的尝试可能已在服务端删除成功,只是响应丢失; 首次尝试即 404 仍返回错误
+func ignoreNotFoundAfterRetry(err error) error {
+	if apiErr, ok := AsAPIError(err); ok && apiErr.HTTPStatus == http.StatusNotFound && apiErr.Attempts > 1 {
+		logger.Printf("删除请求重试后返回 404,视为之前的尝试已删除成功")
+		return nil
+	}
+	return err
+}
+
+// GetClusterNodes 获取集群下所有节点 (展开各节点组)
+func (c *Client) GetClusterNodes(ctx context.Context, clusterName string) ([]LogStoreInstance, error) {
+	detail, err := c.GetClusterDetail(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return flattenNodeGroups(detail, clusterName), nil
+}
+
+// flattenNodeGroups 展开集群详情中的节点组,节点未填写角色/集群时使用所在节点组的角色及 clusterName
+func flattenNodeGroups(detail *ClusterDetailResult, clusterName string) []LogStoreInstance {
+	var nodes []LogStoreInstance
+	for _, group := range detail.NodeGroups {
+		for _, node := range group.Nodes {
+			if node.Role == "" {
+				node.Role = group.Role
+			}
+			if node.ClusterName == "" {
+				node.ClusterName = clusterName
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// healthyNodeStatuses 视为健康的节点状态 (不区分大小写)
+var healthyNodeStatuses = map[string]bool{
+	"running": true,
+	"normal":  true,
+	"online":  true,
+	"active":  true,
+	"up":      true,
+	"ok":      true,
+}
+
+// nodeHealthy 判断节点状态是否健康
+func nodeHealthy(node LogStoreInstance) bool {
+	return healthyNodeStatuses[strings.ToLower(strings.TrimSpace(node.Status))]
+}
+
+// ClusterHealth 集群节点健康状况
+type ClusterHealth struct {
+	ClusterName string             `json:"clusterName"`
+	NodeCount   int                `json:"nodeCount"`
+	Unhealthy   []LogStoreInstance `json:"unhealthy"`
+}
+
+// Healthy 集群至少有一个节点且所有节点状态健康
+func (h *ClusterHealth) Healthy() bool {
+	return h.NodeCount > 0 && len(h.Unhealthy) == 0
+}
+
+// GetClusterHealth 获取集群节点并检查健康状况
+func (c *Client) GetClusterHealth(ctx context.Context, clusterName string) (*ClusterHealth, error) {
+	nodes, err := c.GetClusterNodes(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &ClusterHealth{ClusterName: clusterName, NodeCount: len(nodes)}
+	for _, node := range nodes {
+		if !nodeHealthy(node) {
+			health.Unhealthy = append(health.Unhealthy, node)
+		}
+	}
+	return health, nil
+}
+
+// ListAllNodes 并发获取所有集群的节点,按集群顺序合并; ctx 带截止时间时各请求共享剩余预算
+func (c *Client) ListAllNodes(ctx context.Context) ([]LogStoreInstance, error) {
+	clusters, err := c.GetClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]LogStoreInstance, len(clusters))
+	errs := forEachWithBudget(ctx, c.clock, len(clusters), defaultConcurrency, func(ctx context.Context, i int) error {
+		var err error
+		results[i], err = c.GetClusterNodes(ctx, clusters[i].ClusterName)
+		return err
+	})
+
+	var nodes []LogStoreInstance
+	for i := range clusters {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("获取集群 %s 节点失败: %w", clusters[i].ClusterName, errs[i])
+		}
+		nodes = append(nodes, results[i]...)
+	}
+	return nodes, nil
+}
+
+// findClusterNode 在所有集群中查找指定 IP 的节点
+func (c *Client) findClusterNode(ctx context.Context, ip string) (*LogStoreInstance, error) {
+	clusters, err := c.GetClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cluster := range clusters {
+		nodes, err := c.GetClusterNodes(ctx, cluster.ClusterName)
+		if err != nil {
+			return nil, fmt.Errorf("获取集群 %s 节点失败: %w", cluster.ClusterName, err)
+		}
+		for i := range nodes {
+			if nodes[i].Address == ip {
+				return &nodes[i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, ip)
+}
+
+// deleteClusterNodeFrom 从指定集群删除节点
+// 删除接口本身按 IP 删除,不区分集群; 仅当服务端声明支持 (Capabilities.ScopedNodeDelete) 时
+// 才通过 clustername 参数限定删除范围
+func (c *Client) deleteClusterNodeFrom(ctx context.Context, clusterName, ip string) error {
+	if err := c.validateClusterName(clusterName); err != nil {
+		return err
+	}
+	if !c.capabilities(ctx).ScopedNodeDelete {
+		return c.DeleteClusterNode(ctx, ip)
+	}
+
+	params := url.Values{}
+	params.Set("clustername", clusterName)
+
+	_, err := c.doRequest(ctx, "DELETE", fmt.Sprintf("/operation/clusters/nodes/%s?%s", ip, params.Encode()), nil)
+	return ignoreNotFoundAfterRetry(err)
+}
+
+// ErrNodeMoveUnsupported 服务端既未声明节点迁移接口,也未声明按集群删除节点,无法安全地迁移节点
+var ErrNodeMoveUnsupported = errors.New("服务端不支持节点迁移")
+
+// MoveClusterNode 将节点迁移到目标集群
+// 服务端声明支持迁移接口 (Capabilities.NodeMove) 时直接调用; 否则在服务端声明支持按集群删除节点
+// (Capabilities.ScopedNodeDelete) 时先添加到目标集群再从原集群删除,避免节点出现不属于任何集群的窗口期,
+// 删除失败时回滚目标集群中新增的节点; 两者都未声明时返回 ErrNodeMoveUnsupported,不做任何变更
+// 迁移完成后重新读取两个集群,确认节点已在目标集群且不再属于原集群
+func (c *Client) MoveClusterNode(ctx context.Context, ip, targetCluster string) error {
+	if err := c.validateClusterName(targetCluster); err != nil {
+		return err
+	}
 
-		if resp.StatusCode >= 400 {
-			// 客户端错误,不重试
-			return nil, fmt.Errorf("客户端错误: %d - %s", resp.StatusCode, string(respBody))
-		}
+	node, err := c.findClusterNode(ctx, ip)
+	if err != nil {
+		return err
+	}
+	sourceCluster := node.ClusterName
+	if sourceCluster == targetCluster {
+		return nil
+	}
 
-		// 解析响应
-		var apiResp APIResponse
-		if err := json.Unmarshal(respBody, &apiResp); err != nil {
-			return nil, fmt.Errorf("解析响应失败: %w", err, string(respBody))
-		}
+	caps := c.capabilities(ctx)
+	switch {
+	case caps.NodeMove:
+		params := url.Values{}
+		params.Set("targetClusterName", targetCluster)
 
-		// 检查业务错误码
-		if apiResp.Code != 0 {
-			return &apiResp, fmt.Errorf("API错误 (code %d): %s", apiResp.Code, apiResp.Message)
+		if _, err := c.doRequest(ctx, "PUT", fmt.Sprintf("/operation/clusters/nodes/%s/move?%s", ip, params.Encode()), nil); err != nil {
+			return fmt.Errorf("迁移节点 %s 到集群 %s 失败: %w", ip, targetCluster, err)
 		}
+		return c.verifyNodeMoved(ctx, ip, sourceCluster, targetCluster)
+	case !caps.ScopedNodeDelete:
+		// 删除接口不区分集群,先添加后删除会把目标集群中新增的节点一并删掉
+		return fmt.Errorf("%w: 未声明迁移接口 (nodeMove) 或按集群删除节点 (scopedNodeDelete),请手动迁移 %s: %s -> %s",
+			ErrNodeMoveUnsupported, ip, sourceCluster, targetCluster)
+	}
+	logger.Printf("服务端未声明节点迁移接口,改为先添加后删除: %s -> %s", ip, targetCluster)
+
+	// 先添加到目标集群
+	err = c.AddClusterNode(ctx, targetCluster, &AddClusterNodeRequest{
+		Address:       node.Address,
+		Role:          node.Role,
+		CpuLimit:      node.CpuLimit,
+		MemLimit:      node.MemLimit,
+		Topic:         node.Topic,
+		BucketNames:   node.BucketNames,
+		BackendDomain: node.BackendDomain,
+		StorageDomain: node.StorageDomain,
+		IsDefault:     node.IsDefault,
+		Status:        node.Status,
+	})
+	if err != nil {
+		return fmt.Errorf("添加节点到目标集群 %s 失败: %w", targetCluster, err)
+	}
 
-		// 成功
-		if attempt > 0 {
-			logger.Printf("请求成功 (重试 %d 次后)", attempt)
+	// 再从原集群删除,失败则回滚
+	if err := c.deleteClusterNodeFrom(ctx, sourceCluster, ip); err != nil {
+		if rbErr := c.deleteClusterNodeFrom(ctx, targetCluster, ip); rbErr != nil {
+			return fmt.Errorf("从原集群 %s 删除节点失败: %v; 回滚失败,节点同时存在于 %s 和 %s: %w",
+				sourceCluster, err, sourceCluster, targetCluster, rbErr)
 		}
-		return &apiResp, nil
+		return fmt.Errorf("从原集群 %s 删除节点失败,已回滚: %w", sourceCluster, err)
 	}
 
-	return nil, fmt.Errorf("请求失败,已重试 %d 次: %w", c.config.MaxRetries, lastErr)
+	return c.verifyNodeMoved(ctx, ip, sourceCluster, targetCluster)
 }
 
-// ==================== 数据大盘 API ====================
+// verifyNodeMoved 绕过响应缓存重新读取原集群和目标集群,确认节点只出现在目标集群中
+func (c *Client) verifyNodeMoved(ctx context.Context, ip, sourceCluster, targetCluster string) error {
+	inCluster := func(clusterName string) (bool, error) {
+		resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/operation/clusters/%s", clusterName), nil, withoutCache())
+		if err != nil {
+			return false, fmt.Errorf("迁移后读取集群 %s 失败: %w", clusterName, err)
+		}
+		var detail ClusterDetailResult
+		if err := c.decoder.Unmarshal(resp.Result, &detail); err != nil {
+			return false, fmt.Errorf("迁移后解析集群 %s 失败: %w", clusterName, err)
+		}
+		for _, node := range flattenNodeGroups(&detail, clusterName) {
+			if node.Address == ip {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
 
-// GetDashboard 获取数据大盘信息
-func (c *Client) GetDashboard(ctx context.Context) (*DashboardResult, error) {
-	resp, err := c.doRequest(ctx, "GET", "/operation/dashboard", nil)
+	found, err := inCluster(targetCluster)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	var result DashboardResult
-	if err := json.Unmarshal(resp.Result.(*json.RawMessage), &result); err != nil {
-		return nil, err
+	if !found {
+		return fmt.Errorf("迁移后目标集群 %s 中没有节点 %s", targetCluster, ip)
 	}
+	found, err = inCluster(sourceCluster)
+	if err != nil {
+		return err
+	}
+	if found {
+		return fmt.Errorf("迁移后节点 %s 仍在原集群 %s 中", ip, sourceCluster)
+	}
+	return nil
+}
 
-	return &result, nil
+// NodeDiff 集群节点期望状态与实际状态的差异
+type NodeDiff struct {
+	ClusterName string
+	Current     []LogStoreInstance      // 集群当前的全部节点
+	ToAdd       []AddClusterNodeRequest // 期望存在但实际不存在的节点
+	ToRemove    []LogStoreInstance      // 实际存在但不在期望状态中的节点
+	Changed     []AddClusterNodeRequest // 地址相同但角色或资源限制不一致的节点
 }
 
-// ==================== 集群管理 API ====================
+// Empty 是否无差异
+func (d *NodeDiff) Empty() bool {
+	return len(d.ToAdd) == 0 && len(d.ToRemove) == 0 && len(d.Changed) == 0
+}
 
-// GetClusters 获取所有集群信息
-func (c *Client) GetClusters(ctx context.Context) ([]LogClusterInfo, error) {
-	resp, err := c.doRequest(ctx, "GET", "/operation/clusters", nil)
+// DiffClusterNodes 对比集群节点的期望状态与实际状态 (以节点地址为标识)
+func (c *Client) DiffClusterNodes(ctx context.Context, clusterName string, desired []AddClusterNodeRequest) (*NodeDiff, error) {
+	actual, err := c.GetClusterNodes(ctx, clusterName)
 	if err != nil {
 		return nil, err
 	}
 
-	var clusters []LogClusterInfo
-	if err := json.Unmarshal(resp.Result.(*json.RawMessage), &clusters); err != nil {
-		return nil, err
+	actualByAddr := make(map[string]LogStoreInstance, len(actual))
+	for _, node := range actual {
+		actualByAddr[node.Address] = node
 	}
 
-	return clusters, nil
-}
-
-// GetClusterDetail 获取指定集群的详细信息
-func (c *Client) GetClusterDetail(ctx context.Context, clusterName string) (*ClusterDetailResult, error) {
-	resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/operation/clusters/%s", clusterName), nil)
-	if err != nil {
-		return nil, err
+	diff := &NodeDiff{ClusterName: clusterName, Current: actual}
+	desiredAddrs := make(map[string]bool, len(desired))
+	for _, want := range desired {
+		desiredAddrs[want.Address] = true
+		have, ok := actualByAddr[want.Address]
+		if !ok {
+			diff.ToAdd = append(diff.ToAdd, want)
+			continue
+		}
+		if (want.Role != "" && want.Role != have.Role) ||
+			(want.CpuLimit != "" && want.CpuLimit != have.CpuLimit) ||
+			(want.MemLimit != "" && want.MemLimit != have.MemLimit) {
+			diff.Changed = append(diff.Changed, want)
+		}
 	}
 
-	var result ClusterDetailResult
-	if err := json.Unmarshal(resp.Result.(*json.RawMessage), &result); err != nil {
-		return nil, err
+	for _, node := range actual {
+		if !desiredAddrs[node.Address] {
+			diff.ToRemove = append(diff.ToRemove, node)
+		}
 	}
 
-	return &result, nil
+	return diff, nil
 }
 
-// AddClusterNodeRequest 向集群添加节点请求参数
-type AddClusterNodeRequest struct {
-	Address        string `json:"address"`         // 必填: 节点IP地址
-	ClusterName    string `json:"clustername"`     // 必填: 集群名称
-	Role           string `json:"role"`            // 必填: 节点角色
-	CpuLimit       string `json:"cpulimit,omitempty"`        // 可选: CPU限制
-	MemLimit       string `json:"memlimit,omitempty"`        // 可选: 内存限制
-	Topic          string `json:"topic,omitempty"`           // 可选: Topic
-	BucketNames    string `json:"bucketnames,omitempty"`     // 可选: 存储桶名称
-	BackendDomain  string `json:"backenddomain,omitempty"`   // 可选: 后端域
-	StorageDomain  string `json:"storagedomain,omitempty"`   // 可选: 存储域
-	IsDefault      bool   `json:"isdefault,omitempty"`       // 可选: 是否默认
-	Status         string `json:"status,omitempty"`          // 可选: 状态
-	CreateTime     string `json:"createtime,omitempty"`      // 可选: 创建时间
-	UpdateTime     string `json:"updateime,omitempty"`       // 可选: 更新时间
+// clusterSubsystemSorts GetClusterSubsystemsPage 支持的排序字段
+var clusterSubsystemSorts = []string{"traffic", "subsys_name", "subsystemid", "createtime", "updatetime"}
+
+// GetClusterSubsystemsRequest 分页获取集群子系统的参数,零值字段不发送
+type GetClusterSubsystemsRequest struct {
+	Limit  int
+	Offset int
+	SortBy string // 排序字段,见 clusterSubsystemSorts
 }
 
-// AddClusterNode 向集群添加节点 (简化版,支持部分参数)
-func (c *Client) AddClusterNode(ctx context.Context, clusterName string, req *AddClusterNodeRequest) error {
-	// 设置集群名称
-	req.ClusterName = clusterName
+// GetClusterSubsystemsPage 按 limit/offset/sortBy 获取集群纳管子系统的一页,返回该页及总数
+// 总数的来源与 GetSubsystemsPage 相同 (total 字段或 X-Total-Count 响应头),都没有时为 -1;
+// 服务端声明不支持分页时获取全部后在客户端截取
+func (c *Client) GetClusterSubsystemsPage(ctx context.Context, clusterName string, req *GetClusterSubsystemsRequest) ([]LogSubClusterSubSystem, int, error) {
+	if err := c.validateClusterName(clusterName); err != nil {
+		return nil, 0, err
+	}
 
-	body, err := json.Marshal(req)
+	if (req.Limit > 0 || req.Offset > 0) && c.paginationUnsupported(ctx) {
+		all, _, err := c.GetClusterSubsystemsPage(ctx, clusterName, &GetClusterSubsystemsRequest{SortBy: req.SortBy})
+		if err != nil {
+			return nil, 0, err
+		}
+		limit := 0
+		if req.Limit > 0 {
+			limit = c.clampLimit(req.Limit)
+		}
+		return pageOf(all, req.Offset, limit), len(all), nil
+	}
+
+	params := url.Values{}
+	if req.Limit > 0 {
+		params.Set("limit", strconv.Itoa(c.clampLimit(req.Limit)))
+	}
+	if req.Offset > 0 {
+		params.Set("offset", strconv.Itoa(req.Offset))
+	}
+	if req.SortBy != "" {
+		if !containsFold(clusterSubsystemSorts, req.SortBy) {
+			return nil, 0, fmt.Errorf("不支持的排序字段: %s (可用: %s)", req.SortBy, strings.Join(clusterSubsystemSorts, ", "))
+		}
+		params.Set("sortBy", strings.ToLower(req.SortBy))
+	}
+
+	endpoint := fmt.Sprintf("/operation/cluster/%s/subsystems", clusterName)
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	var header http.Header
+	resp, err := c.doRequest(ctx, "GET", endpoint, nil, withoutCache(), withResponseHeader(&header))
 	if err != nil {
-		return fmt.Errorf("序列化节点数据失败: %w", err)
+		return nil, 0, err
 	}
 
-	_, err = c.doRequest(ctx, "POST", fmt.Sprintf("/operation/clusters/%s/nodes", clusterName), body)
-	return err
-}
+	if trimmed := bytes.TrimSpace(resp.Result); len(trimmed) > 0 && trimmed[0] == '[' {
+		var subsystems []LogSubClusterSubSystem
+		if err := c.decodeResult(resp, &subsystems); err != nil {
+			return nil, 0, err
+		}
+		total := -1
+		if n, err := strconv.Atoi(header.Get(headerTotalCount)); err == nil {
+			total = n
+		}
+		return subsystems, total, nil
+	}
 
-// DeleteClusterNode 从集群删除节点
-func (c *Client) DeleteClusterNode(ctx context.Context, ip string) error {
-	_, err := c.doRequest(ctx, "DELETE", fmt.Sprintf("/operation/clusters/nodes/%s", ip), nil)
-	return err
+	var page struct {
+		Items []LogSubClusterSubSystem `json:"items"`
+		Total int64                    `json:"total"`
+	}
+	if err := c.decodeResult(resp, &page); err != nil {
+		return nil, 0, err
+	}
+	return page.Items, int(page.Total), nil
 }
 
 // GetClusterSubsystems 获取集群纳管的子系统信息
 func (c *Client) GetClusterSubsystems(ctx context.Context, clusterName string) ([]LogSubClusterSubSystem, error) {
+	if err := c.validateClusterName(clusterName); err != nil {
+		return nil, err
+	}
+
 	resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/operation/cluster/%s/subsystems", clusterName), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var subsystems []LogSubClusterSubSystem
-	if err := json.Unmarshal(resp.Result.(*json.RawMessage), &subsystems); err != nil {
+	if err := c.decoder.Unmarshal(resp.Result, &subsystems); err != nil {
 		return nil, err
 	}
 
@@ -555,7 +2873,7 @@ func (c *Client) CheckSubsystemExists(ctx context.Context, subsystemID string) (
 	}
 
 	var result SubsystemExistsResult
-	if err := json.Unmarshal(resp.Result.(*json.RawMessage), &result); err != nil {
+	if err := c.decoder.Unmarshal(resp.Result, &result); err != nil {
 		return nil, err
 	}
 
@@ -567,7 +2885,7 @@ type AddSubsystemRequest struct {
 	SubSystemID    string `json:"subSystemId"`
 	LogImportValue string `json:"logImportValue"`
 	LogImportFiles string `json:"logImportFiles"`
-	Traffic        int    `json:"traffic"`
+	Traffic        int64  `json:"traffic"`
 	Cluster        string `json:"cluster"`
 }
 
@@ -582,16 +2900,40 @@ func (c *Client) AddSubsystem(ctx context.Context, req *AddSubsystemRequest) err
 	return err
 }
 
+// AdjustClusterRequest 调整子系统归属集群请求
+type AdjustClusterRequest struct {
+	TargetClusterName string `json:"targetClusterName"`
+	LogImportValue    string `json:"logImportValue"`
+	LogImportFiles    string `json:"logImportFiles"` // 原样发送,可包含逗号等特殊字符
+	Traffic           int64  `json:"traffic"`
+}
+
 // AdjustSubsystemCluster 调整子系统归属集群
-func (c *Client) AdjustSubsystemCluster(ctx context.Context, subsystemID, targetClusterName, logImportValue, logImportFiles string, traffic int) error {
-	params := url.Values{}
-	params.Set("targetClusterName", targetClusterName)
-	params.Set("logImportValue", logImportValue)
-	params.Set("logImportFiles", logImportFiles)
-	params.Set("traffic", strconv.Itoa(traffic))
+// 参数以 JSON 请求体发送,避免文件列表中的特殊字符被截断或出现在访问日志中;
+// 配置 LegacyAdjustQuery 时按旧接口以查询参数发送
+func (c *Client) AdjustSubsystemCluster(ctx context.Context, subsystemID string, req *AdjustClusterRequest) error {
+	if err := c.validateClusterName(req.TargetClusterName); err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("/operation/subsystem/%s", subsystemID)
+
+	if c.config.LegacyAdjustQuery {
+		params := url.Values{}
+		params.Set("targetClusterName", req.TargetClusterName)
+		params.Set("logImportValue", req.LogImportValue)
+		params.Set("logImportFiles", req.LogImportFiles)
+		params.Set("traffic", strconv.FormatInt(req.Traffic, 10))
+
+		method, endpoint, body, opts := c.withParams("POST", endpoint, params)
+		_, err := c.doRequest(ctx, method, endpoint, body, opts...)
+		return err
+	}
 
-	endpoint := fmt.Sprintf("/operation/subsystem/%s?%s", subsystemID, params.Encode())
-	_, err := c.doRequest(ctx, "POST", endpoint, nil)
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("序列化调整请求失败: %w", err)
+	}
+	_, err = c.doRequest(ctx, "POST", endpoint, body)
 	return err
 }
 
@@ -607,6 +2949,46 @@ func (c *Client) EnableSubsystem(ctx context.Context, subsystemID string) error
 	return err
 }
 
+// DisableSubsystem 停用子系统
+func (c *Client) DisableSubsystem(ctx context.Context, subsystemID string) error {
+	_, err := c.doRequest(ctx, "PUT", fmt.Sprintf("/operation/subsystem/%s/disable", subsystemID), nil)
+	return err
+}
+
+// DeleteSubsystem 删除子系统
+func (c *Client) DeleteSubsystem(ctx context.Context, subsystemID string) error {
+	_, err := c.doRequest(ctx, "DELETE", fmt.Sprintf("/operation/subsystem/%s", subsystemID), nil)
+	return err
+}
+
+// UpdateSubsystemRequest 修改子系统请求,未设置的字段不修改
+type UpdateSubsystemRequest struct {
+	Traffic           *int64   `json:"traffic,omitempty"`
+	Cluster           string   `json:"cluster,omitempty"`
+	KeywordFilters    []string `json:"keywordFilters,omitempty"`
+	ScanFileWhitelist []string `json:"scanFileWhitelist,omitempty"`
+}
+
+// Empty 请求中没有任何需要修改的字段
+func (r *UpdateSubsystemRequest) Empty() bool {
+	return r.Traffic == nil && r.Cluster == "" && len(r.KeywordFilters) == 0 && len(r.ScanFileWhitelist) == 0
+}
+
+// UpdateSubsystem 修改子系统配置
+func (c *Client) UpdateSubsystem(ctx context.Context, subsystemID string, req *UpdateSubsystemRequest) error {
+	if req.Empty() {
+		return fmt.Errorf("修改子系统 %s 需要至少指定一个字段", subsystemID)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("序列化请求数据失败: %w", err)
+	}
+
+	_, err = c.doRequest(ctx, "PUT", fmt.Sprintf("/operation/subsystem/%s", subsystemID), body)
+	return err
+}
+
 // GetSubsystemDetail 获取子系统详情
 func (c *Client) GetSubsystemDetail(ctx context.Context, subsystemID string) (*SubsystemDetailResult, error) {
 	resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/operation/subsystem/%s", subsystemID), nil)
@@ -615,13 +2997,102 @@ func (c *Client) GetSubsystemDetail(ctx context.Context, subsystemID string) (*S
 	}
 
 	var result SubsystemDetailResult
-	if err := json.Unmarshal(resp.Result.(*json.RawMessage), &result); err != nil {
+	if err := c.decoder.Unmarshal(resp.Result, &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
 
+// TrafficPoint 子系统流量时间序列中的一个点
+type TrafficPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Traffic   int64     `json:"traffic"`
+}
+
+// 流量时间序列的粒度
+var trafficGranularities = []string{"minute", "hour", "day"}
+
+// GetSubsystemTraffic 获取子系统在 [from, to) 内的流量时间序列,时间以 UTC RFC3339 格式传给服务端
+// granularity 为 minute / hour / day,为空时使用服务端默认粒度; from 等于 to 时直接返回空序列
+func (c *Client) GetSubsystemTraffic(ctx context.Context, subsystemID string, from, to time.Time, granularity string) ([]TrafficPoint, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("结束时间 %s 早于开始时间 %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	if granularity != "" && !containsFold(trafficGranularities, granularity) {
+		return nil, fmt.Errorf("不支持的粒度: %q (可用: %s)", granularity, strings.Join(trafficGranularities, ", "))
+	}
+	if to.Equal(from) {
+		return []TrafficPoint{}, nil
+	}
+
+	params := url.Values{}
+	params.Set("from", from.UTC().Format(time.RFC3339))
+	params.Set("to", to.UTC().Format(time.RFC3339))
+	if granularity != "" {
+		params.Set("granularity", strings.ToLower(granularity))
+	}
+
+	resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/operation/subsystem/%s/traffic?%s", subsystemID, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []TrafficPoint{}
+	if err := c.decodeResult(resp, &points); err != nil {
+		return nil, err
+	}
+	if points == nil {
+		points = []TrafficPoint{}
+	}
+	return points, nil
+}
+
+// dedupIDs 去除空白、空项及重复项,保持首次出现的顺序
+func dedupIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// GetSubsystemDetails 以有限并发批量获取子系统详情 (并发数见 SetBatchConcurrency),输入的 ID 先去重
+// 返回 子系统ID -> 详情; 部分获取失败时仍返回成功的部分及汇总错误
+func (c *Client) GetSubsystemDetails(ctx context.Context, ids []string) (map[string]*SubsystemDetailResult, error) {
+	ids = dedupIDs(ids)
+	details := make([]*SubsystemDetailResult, len(ids))
+	errs := forEachWithBudget(ctx, c.clock, len(ids), c.batchConcurrency, func(ctx context.Context, i int) error {
+		detail, err := c.GetSubsystemDetail(ctx, ids[i])
+		details[i] = detail
+		return err
+	})
+
+	result := make(map[string]*SubsystemDetailResult, len(ids))
+	var failed int
+	var firstErr error
+	for i, err := range errs {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("获取子系统 %s 详情失败: %w", ids[i], err)
+			}
+			failed++
+			continue
+		}
+		result[ids[i]] = details[i]
+	}
+	if firstErr != nil {
+		return result, fmt.Errorf("%d/%d 个子系统详情获取失败,首个错误: %w", failed, len(ids), firstErr)
+	}
+	return result, nil
+}
+
 // GetSubsystems 获取所有子系统信息
 func (c *Client) GetSubsystems(ctx context.Context) ([]SubSystem, error) {
 	resp, err := c.doRequest(ctx, "GET", "/operation/subsystems", nil)
@@ -630,7 +3101,7 @@ func (c *Client) GetSubsystems(ctx context.Context) ([]SubSystem, error) {
 	}
 
 	var subsystems []SubSystem
-	if err := json.Unmarshal(resp.Result.(*json.RawMessage), &subsystems); err != nil {
+	if err := c.decoder.Unmarshal(resp.Result, &subsystems); err != nil {
 		return nil, err
 	}
 
@@ -639,8 +3110,13 @@ func (c *Client) GetSubsystems(ctx context.Context) ([]SubSystem, error) {
 
 // SearchSubsystemsRequest 搜索子系统请求参数
 type SearchSubsystemsRequest struct {
-	SubsysID *string
-	Limit    int
+	SubsysID      *string
+	DevDept       *string // 所属部门
+	BusinessOwner *string // 业务负责人
+	State         *string // 子系统状态
+	Cluster       string  // 非空时只返回归属该集群的子系统 (服务端过滤)
+	Limit         int
+	Offset        int // 跳过的条数,0 时不发送
 }
 
 // SearchSubsystems 根据条件搜索子系统
@@ -649,175 +3125,296 @@ func (c *Client) SearchSubsystems(ctx context.Context, req *SearchSubsystemsRequ
 	if req.SubsysID != nil {
 		params.Set("subsysId", *req.SubsysID)
 	}
+	if req.DevDept != nil {
+		params.Set("devDept", *req.DevDept)
+	}
+	if req.BusinessOwner != nil {
+		params.Set("businessOwner", *req.BusinessOwner)
+	}
+	if req.State != nil {
+		params.Set("state", *req.State)
+	}
+	if req.Cluster != "" {
+		params.Set("cluster", req.Cluster)
+	}
 	if req.Limit != 0 {
-		params.Set("limit", strconv.Itoa(req.Limit))
+		params.Set("limit", strconv.Itoa(c.clampLimit(req.Limit)))
 	} else {
 		params.Set("limit", "20")
 	}
-
-	endpoint := "/operation/subsystems/search"
-	if len(params) > 0 {
-		endpoint += "?" + params.Encode()
+	if req.Offset > 0 {
+		params.Set("offset", strconv.Itoa(req.Offset))
 	}
 
-	resp, err := c.doRequest(ctx, "GET", endpoint, nil)
+	method, endpoint, body, opts := c.withParams("GET", "/operation/subsystems/search", params)
+	resp, err := c.doRequest(ctx, method, endpoint, body, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	var subsystems []SubSystem
-	if err := json.Unmarshal(resp.Result.(*json.RawMessage), &subsystems); err != nil {
+	if err := c.decoder.Unmarshal(resp.Result, &subsystems); err != nil {
 		return nil, err
 	}
 
 	return subsystems, nil
 }
 
-// ==================== 主函数示例 ====================
+// ErrResultTruncated 服务端无法分页返回剩余结果,继续请求只会得到不完整的列表
+var ErrResultTruncated = errors.New("结果不完整")
 
-func main() {
-	// 方式 1: 从配置文件加载 (推荐)
-	config, err := LoadConfigFromYAML("", "")
-	if err != nil {
-		fmt.Printf("⚠️  %v\n", err)
-		fmt.Println("请先创建配置文件 config.yaml,参考 config.yaml.example")
-		return
+// GetSubsystemsByCluster 获取归属指定集群的完整子系统信息,由搜索接口按 cluster 在服务端过滤
+// 与 GetClusterSubsystems 不同,返回的是 SubSystem 而非 LogSubClusterSubSystem;
+// 每页 MaxLimit 条,按 offset 逐页获取直到返回不足一页; 服务端声明不支持分页或忽略 offset
+// (重复返回同一页) 而结果达到一页上限时返回 ErrResultTruncated
+func (c *Client) GetSubsystemsByCluster(ctx context.Context, clusterName string) ([]SubSystem, error) {
+	if err := c.validateClusterName(clusterName); err != nil {
+		return nil, err
 	}
 
-	// 或者指定环境
-	// config, err := LoadConfigFromYAML("", "dev")
-	// config, err := LoadConfigFromYAML("", "prod")
+	limit := c.config.MaxLimit
+	if limit <= 0 {
+		limit = defaultMaxLimit
+	}
 
-	// 或者指定配置文件路径
-	// config, err := LoadConfigFromYAML("/path/to/config.yaml", "prod")
-	// if err != nil {
-	//     fmt.Printf("加载配置失败: %v\n", err)
-	//     return
-	// }
+	var all []SubSystem
+	for {
+		page, err := c.SearchSubsystems(ctx, &SearchSubsystemsRequest{Cluster: clusterName, Limit: limit, Offset: len(all)})
+		if err != nil {
+			return nil, err
+		}
+		if len(all) > 0 && len(page) > 0 && page[0].SubsysID == all[0].SubsysID {
+			return nil, fmt.Errorf("%w: 集群 %s 的子系统超过 %d 个,服务端忽略了 offset 参数", ErrResultTruncated, clusterName, limit)
+		}
+		all = append(all, page...)
+		if len(page) < limit {
+			return all, nil
+		}
+		if c.paginationUnsupported(ctx) {
+			return nil, fmt.Errorf("%w: 集群 %s 的子系统超过 %d 个,服务端不支持分页", ErrResultTruncated, clusterName, limit)
+		}
+	}
+}
 
-	// 方式 2: 手动创建配置 (备用方案)
-	// config := DefaultConfig("http://localhost:8080")
+// SubsystemPage 子系统分页结果
+// 使用游标分页的接口通过 NextCursor 返回下一页的不透明游标,为空表示已是最后一页
+type SubsystemPage struct {
+	Items      []SubSystem `json:"items"`
+	Total      int64       `json:"total"`
+	NextCursor string      `json:"nextCursor"`
+}
 
-	// 创建客户端
-	client := NewClient(config)
+// GetSubsystemsCursor 按游标获取一页子系统,首次调用 cursor 传空
+func (c *Client) GetSubsystemsCursor(ctx context.Context, cursor string, limit int) (*SubsystemPage, error) {
+	params := url.Values{}
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+	if limit != 0 {
+		params.Set("limit", strconv.Itoa(c.clampLimit(limit)))
+	}
 
-	// 创建上下文
-	ctx := context.Background()
+	endpoint := "/operation/subsystems"
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
 
-	// 示例 1: 获取数据大盘信息
-	fmt.Println("========================================")
-	fmt.Println("1. 获取数据大盘信息")
-	dashboard, err := client.GetDashboard(ctx)
+	resp, err := c.doRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
-		fmt.Printf("获取数据大盘失败: %v\n", err)
-	} else {
-		fmt.Printf("子系统数量: %d\n", dashboard.SubsystemCount)
-		fmt.Printf("集群数量: %d\n", dashboard.ClusterNum)
+		return nil, err
 	}
 
-	// 示例 2: 获取所有集群
-	fmt.Println("\n========================================")
-	fmt.Println("2. 获取所有集群")
-	clusters, err := client.GetClusters(ctx)
-	if err != nil {
-		fmt.Printf("获取集群列表失败: %v\n", err)
-	} else {
-		for _, cluster := range clusters {
-			fmt.Printf("集群名称: %s, 默认: %d\n", cluster.ClusterName, cluster.IsDefault)
-		}
+	var page SubsystemPage
+	if err := c.decodeResult(resp, &page); err != nil {
+		return nil, err
 	}
+	return &page, nil
+}
+
+// headerTotalCount 分页接口返回总数的响应头
+const headerTotalCount = "X-Total-Count"
 
-	// 示例 3: 获取集群详情
-	if len(clusters) > 0 {
-		clusterName := clusters[0].ClusterName
-		fmt.Printf("\n========================================\n")
-		fmt.Printf("3. 获取集群详情: %s\n", clusterName)
-		clusterDetail, err := client.GetClusterDetail(ctx, clusterName)
+// defaultPageSize IterateSubsystems 未指定 pageSize 时的每页数量
+const defaultPageSize = 100
+
+// GetSubsystemsPage 按 offset/limit 获取一页子系统,返回该页及总数
+// 服务端返回 {"items": [...], "total": N} 时总数取自 total,返回数组时取自 X-Total-Count 响应头,
+// 两者都没有时总数为 -1; 服务端声明不支持分页时获取全部后在客户端截取
+func (c *Client) GetSubsystemsPage(ctx context.Context, offset, limit int) ([]SubSystem, int, error) {
+	if c.paginationUnsupported(ctx) {
+		all, err := c.GetSubsystems(ctx)
 		if err != nil {
-			fmt.Printf("获取集群详情失败: %v\n", err)
-		} else {
-			clusterJSON, _ := json.MarshalIndent(clusterDetail.ClusterInfo, "", "  ")
-			fmt.Printf("集群信息: %s\n", string(clusterJSON))
+			return nil, 0, err
+		}
+		if limit > 0 {
+			limit = c.clampLimit(limit)
 		}
+		return pageOf(all, offset, limit), len(all), nil
 	}
 
-	// 示例 4: 获取所有子系统
-	fmt.Println("\n========================================")
-	fmt.Println("4. 获取所有子系统")
-	subsystems, err := client.GetSubsystems(ctx)
+	params := url.Values{}
+	params.Set("offset", strconv.Itoa(offset))
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(c.clampLimit(limit)))
+	}
+
+	var header http.Header
+	resp, err := c.doRequest(ctx, "GET", "/operation/subsystems?"+params.Encode(), nil, withoutCache(), withResponseHeader(&header))
 	if err != nil {
-		fmt.Printf("获取子系统列表失败: %v\n", err)
-	} else {
-		fmt.Printf("子系统总数: %d\n", len(subsystems))
-		limit := 5
-		if len(subsystems) < limit {
-			limit = len(subsystems)
+		return nil, 0, err
+	}
+
+	if trimmed := bytes.TrimSpace(resp.Result); len(trimmed) > 0 && trimmed[0] == '[' {
+		var subsystems []SubSystem
+		if err := c.decodeResult(resp, &subsystems); err != nil {
+			return nil, 0, err
 		}
-		for i := 0; i < limit; i++ {
-			fmt.Printf("子系统ID: %s, 名称: %s\n", subsystems[i].SubsysID, subsystems[i].SubsysName)
+		total := -1
+		if n, err := strconv.Atoi(header.Get(headerTotalCount)); err == nil {
+			total = n
 		}
+		return subsystems, total, nil
 	}
 
-	// 示例 5: 搜索子系统
-	fmt.Println("\n========================================")
-	fmt.Println("5. 搜索子系统")
-	searchResults, err := client.SearchSubsystems(ctx, &SearchSubsystemsRequest{Limit: 10})
-	if err != nil {
-		fmt.Printf("搜索子系统失败: %v\n", err)
-	} else {
-		fmt.Printf("搜索到 %d 个子系统\n", len(searchResults))
+	var page SubsystemPage
+	if err := c.decodeResult(resp, &page); err != nil {
+		return nil, 0, err
 	}
+	return page.Items, int(page.Total), nil
+}
 
-	// 示例 6: 检查子系统是否存在
-	if len(subsystems) > 0 {
-		subsystemID := subsystems[0].SubsysID
-		fmt.Printf("\n========================================\n")
-		fmt.Printf("6. 检查子系统是否存在: %s\n", subsystemID)
-		existsResult, err := client.CheckSubsystemExists(ctx, subsystemID)
+// IterateSubsystems 按 pageSize 逐页遍历全部子系统,对每页调用 fn,fn 返回错误时停止遍历并返回该错误
+// 返回空页、不足一页或已达到总数时结束; pageSize <= 0 时使用 defaultPageSize;
+// 服务端声明不支持分页时只请求一次,对全部子系统调用一次 fn
+func (c *Client) IterateSubsystems(ctx context.Context, pageSize int, fn func([]SubSystem) error) error {
+	if c.paginationUnsupported(ctx) {
+		all, err := c.GetSubsystems(ctx)
 		if err != nil {
-			fmt.Printf("检查子系统存在性失败: %v\n", err)
-		} else {
-			fmt.Printf("存在: %v\n", existsResult.Exists)
+			return fmt.Errorf("获取子系统失败: %w", err)
+		}
+		if len(all) == 0 {
+			return nil
 		}
+		return fn(all)
 	}
 
-	// 示例 7: 向集群添加节点 (最小化参数)
-	fmt.Println("\n========================================")
-	fmt.Println("7. 向集群添加节点 (最小化参数)")
-	minimalNode := &AddClusterNodeRequest{
-		Address:  "127.0.0.2",
-		Role:     "write",
-		CpuLimit: "8",
-		MemLimit: "16",
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
 	}
-	err = client.AddClusterNode(ctx, "LOG008", minimalNode)
-	if err != nil {
-		fmt.Printf("添加节点失败: %v\n", err)
-	} else {
-		fmt.Printf("节点添加成功\n")
-	}
-
-	// 示例 8: 向集群添加节点 (完整参数)
-	fmt.Println("\n========================================")
-	fmt.Println("8. 向集群添加节点 (完整参数)")
-	fullNode := &AddClusterNodeRequest{
-		Address:       "127.0.0.3",
-		Role:          "master",
-		CpuLimit:      "16",
-		MemLimit:      "32",
-		Topic:         "log_topic_008",
-		BucketNames:   "log_bucket_008",
-		BackendDomain: "backend.example.com",
-		StorageDomain: "storage.example.com",
-		Status:        "active",
-	}
-	err = client.AddClusterNode(ctx, "LOG008", fullNode)
-	if err != nil {
-		fmt.Printf("添加节点失败: %v\n", err)
-	} else {
-		fmt.Printf("节点添加成功\n")
+	pageSize = c.clampLimit(pageSize)
+
+	for offset := 0; ; {
+		page, total, err := c.GetSubsystemsPage(ctx, offset, pageSize)
+		if err != nil {
+			return fmt.Errorf("获取子系统 (offset %d) 失败: %w", offset, err)
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+
+		offset += len(page)
+		if len(page) < pageSize || (total >= 0 && offset >= total) {
+			return nil
+		}
+	}
+}
+
+// EachSubsystemCursor 沿 NextCursor 逐页遍历全部子系统,对每个子系统调用 fn,
+// fn 返回错误时停止遍历并返回该错误; 服务端重复返回同一游标时报错,避免死循环
+func (c *Client) EachSubsystemCursor(ctx context.Context, limit int, fn func(SubSystem) error) error {
+	seen := make(map[string]bool)
+	cursor := ""
+	for {
+		page, err := c.GetSubsystemsCursor(ctx, cursor, limit)
+		if err != nil {
+			return err
+		}
+		for _, s := range page.Items {
+			if err := fn(s); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		if seen[page.NextCursor] {
+			return fmt.Errorf("服务端返回了重复的分页游标: %s", page.NextCursor)
+		}
+		seen[page.NextCursor] = true
+		cursor = page.NextCursor
+	}
+}
+
+// ==================== 并发工具 ====================
+
+// defaultConcurrency 批量请求的默认并发数
+const defaultConcurrency = 8
+
+// forEachConcurrent 以最多 limit 个并发执行 fn(0..n-1),等待全部完成
+func forEachConcurrent(n, limit int, fn func(i int)) {
+	if limit <= 0 {
+		limit = defaultConcurrency
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// ErrBatchDeadline 批量操作在截止时间前未能开始的条目返回此错误
+var ErrBatchDeadline = errors.New("批量操作已超出截止时间")
+
+// forEachWithBudget 与 forEachConcurrent 相同,但共享 ctx 的截止时间预算:
+// 每个条目开始时按剩余时间 / 剩余批次数 分配超时,保证整批在截止时间前完成或放弃;
+// 截止时间已过 (或 ctx 已取消) 时不再启动剩余条目,其错误为 ErrBatchDeadline。
+// ctx 无截止时间时不限制单个条目的耗时; 剩余时间按 clock 计算
+func forEachWithBudget(ctx context.Context, clock Clock, n, limit int, fn func(ctx context.Context, i int) error) []error {
+	if limit <= 0 {
+		limit = defaultConcurrency
 	}
 
-	fmt.Println("\n========================================")
-	fmt.Println("示例执行完成")
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < n; j++ {
+				errs[j] = fmt.Errorf("%w: %v", ErrBatchDeadline, err)
+			}
+			break
+		}
+
+		itemCtx, cancel := ctx, func() {}
+		if deadline, ok := ctx.Deadline(); ok {
+			waves := (n - i + limit - 1) / limit
+			itemCtx, cancel = context.WithTimeout(ctx, deadline.Sub(clock.Now())/time.Duration(waves))
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer cancel()
+			errs[i] = fn(itemCtx, i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// FakeClock 手动推进的时钟,只有调用 Advance 时时间才会前进
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock 创建从 start 开始的假时钟
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After 返回的通道在时钟被推进到 Now()+d 时触发
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeWaiter{at: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance 将时间推进 d,并触发所有到期的 After
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- f.now
+	}
+	f.waiters = pending
+}
+
+// Waiters 返回尚未触发的 After 数量,测试中可用于等待被测代码进入休眠后再推进时间
+func (f *FakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+func TestFakeClockAfter(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	short, long := clock.After(time.Second), clock.After(time.Minute)
+	if n := clock.Waiters(); n != 2 {
+		t.Fatalf("Waiters() = %d, want 2", n)
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-short:
+	default:
+		t.Error("1s timer did not fire after advancing 30s")
+	}
+	select {
+	case <-long:
+		t.Error("1m timer fired early")
+	default:
+	}
+	if n := clock.Waiters(); n != 1 {
+		t.Errorf("Waiters() = %d, want 1", n)
+	}
+
+	// 非正的时长立即触发
+	select {
+	case <-clock.After(0):
+	default:
+		t.Error("After(0) did not fire immediately")
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+
+	done := make(chan error, 1)
+	go func() { done <- sleepContext(context.Background(), clock, time.Minute) }()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Errorf("sleepContext = %v, want nil after the clock advanced", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- sleepContext(ctx, clock, time.Hour) }()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("sleepContext = %v, want context.Canceled", err)
+	}
+}
+
+func TestRetryBackoffUsesClock(t *testing.T) {
+	var calls atomic.Int32
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			respondError(w, http.StatusServiceUnavailable, 503, "busy")
+			return
+		}
+		respondResult(w, []LogClusterInfo{})
+	})
+	// 退避 1 小时,只有推进假时钟才会重试
+	client := newTestClient(t, api, func(c *Config) { c.RetryBackoff = time.Hour })
+	clock := NewFakeClock(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	client.SetClock(clock)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetClusters(context.Background())
+		done <- err
+	}()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("server called %d times before the backoff elapsed, want 1", n)
+	}
+
+	clock.Advance(24 * time.Hour)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not retry after advancing the clock")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("server called %d times, want 2", n)
+	}
+}
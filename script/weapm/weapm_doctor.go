@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ==================== 配置安全检查 ====================
+
+// ConfigWarning 配置安全检查发现的问题及修复建议
+type ConfigWarning struct {
+	Env     string `json:"env,omitempty"`
+	Problem string `json:"problem"`
+	Fix     string `json:"fix"`
+}
+
+// checkConfigSecurity 检查配置文件的权限及各环境是否使用默认密码
+// 未配置 base_url 的环境视为未启用,不做检查
+func checkConfigSecurity(configPath string) ([]ConfigWarning, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件信息失败: %w", err)
+	}
+
+	var warnings []ConfigWarning
+	// Windows 不使用 Unix 权限位
+	if perm := info.Mode().Perm(); runtime.GOOS != "windows" && perm&0077 != 0 {
+		warnings = append(warnings, ConfigWarning{
+			Problem: fmt.Sprintf("配置文件 %s 权限为 %04o,同组或其他用户可读取其中的密码", configPath, perm),
+			Fix:     fmt.Sprintf("chmod 600 %s", configPath),
+		})
+	}
+
+	configFile, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range configFile.EnvNames() {
+		cfg := configFile.Environments[name]
+		if cfg.BaseURL == "" {
+			continue
+		}
+		// 令牌认证不使用密码
+		if cfg.AuthMode != "" && cfg.AuthMode != AuthModeBasic {
+			continue
+		}
+		if cfg.Password == "" || cfg.Password == defaultPassword {
+			warnings = append(warnings, ConfigWarning{
+				Env:     name,
+				Problem: "使用内置默认密码 (未配置 password 时同样使用默认密码)",
+				Fix:     "在服务端修改 weapm 账号密码,并更新该环境的 password 字段",
+			})
+		}
+	}
+
+	return warnings, nil
+}
+
+// warnConfigSecurity 启动时检查配置文件安全性,问题输出到 stderr,不中断执行
+func warnConfigSecurity(configPath string) {
+	warnings, err := checkConfigSecurity(configPath)
+	if err != nil {
+		return
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠️  %s; 建议: %s (运行 config doctor 查看详情)\n", w.Problem, w.Fix)
+	}
+}
+
+// cmdConfigDoctor 输出配置安全检查结果,存在问题时返回错误
+func cmdConfigDoctor(configPath string) error {
+	if configPath == "" {
+		defaultPath, err := defaultConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath = defaultPath
+	}
+
+	warnings, err := checkConfigSecurity(configPath)
+	if err != nil {
+		return err
+	}
+	if len(warnings) == 0 {
+		fmt.Printf("✅ %s 未发现问题\n", configPath)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(warnings))
+	for _, w := range warnings {
+		env := w.Env
+		if env == "" {
+			env = "-"
+		}
+		rows = append(rows, []string{env, w.Problem, w.Fix})
+	}
+	if err := renderTable(os.Stdout, []string{"环境", "问题", "修复建议"}, rows, 0); err != nil {
+		return err
+	}
+	return fmt.Errorf("配置检查发现 %d 个问题", len(warnings))
+}
+
+// ==================== 配置语义检查 ====================
+
+// 检查结果严重性
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintFinding 配置语义检查发现的问题
+type LintFinding struct {
+	Severity string `json:"severity"`
+	Env      string `json:"env,omitempty"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// lintConfigFile 检查配置文件中的常见错误
+// 未配置 base_url 的环境视为未启用,只检查 active_env 是否指向它
+func lintConfigFile(configFile *ConfigFile) []LintFinding {
+	var findings []LintFinding
+	add := func(severity, env, rule, message string) {
+		findings = append(findings, LintFinding{Severity: severity, Env: env, Rule: rule, Message: message})
+	}
+
+	for _, name := range configFile.EnvNames() {
+		cfg := configFile.Environments[name]
+		if cfg.BaseURL == "" {
+			continue
+		}
+		prod := name == "prod"
+
+		if cfg.Timeout <= 0 {
+			add(LintWarning, name, "timeout", "timeout 未设置或不大于 0,将使用默认的 30 秒")
+		}
+		if !prod {
+			continue
+		}
+		if (cfg.AuthMode == "" || cfg.AuthMode == AuthModeBasic) && (cfg.Password == "" || cfg.Password == defaultPassword) {
+			add(LintError, name, "default-credentials", "生产环境使用内置默认密码")
+		}
+		if cfg.InsecureSkipVerify {
+			add(LintError, name, "insecure-skip-verify", "生产环境关闭了 TLS 证书校验")
+		}
+		if strings.HasPrefix(strings.ToLower(cfg.BaseURL), "http://") {
+			add(LintError, name, "plain-http", fmt.Sprintf("生产环境 base_url 使用明文 HTTP: %s", cfg.BaseURL))
+		}
+	}
+
+	if configFile.ActiveEnv != "" {
+		active, ok := configFile.Environments[configFile.ActiveEnv]
+		switch {
+		case !ok:
+			add(LintError, configFile.ActiveEnv, "active-env", fmt.Sprintf("active_env 指向不存在的环境 %q (可用: %s)", configFile.ActiveEnv, strings.Join(configFile.EnvNames(), ", ")))
+		case active.BaseURL == "":
+			add(LintError, configFile.ActiveEnv, "active-env", fmt.Sprintf("active_env 指向的环境 %s 未配置 base_url", configFile.ActiveEnv))
+		}
+	}
+
+	return findings
+}
+
+// cmdConfigLint 输出配置语义检查结果,存在 error 级别的问题时返回错误
+func cmdConfigLint(configPath string) error {
+	if configPath == "" {
+		defaultPath, err := defaultConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath = defaultPath
+	}
+
+	configFile, err := readConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	findings := lintConfigFile(configFile)
+	if len(findings) == 0 {
+		fmt.Printf("✅ %s 未发现问题\n", configPath)
+		return nil
+	}
+
+	errorCount := 0
+	rows := make([][]string, 0, len(findings))
+	for _, f := range findings {
+		if f.Severity == LintError {
+			errorCount++
+		}
+		env := f.Env
+		if env == "" {
+			env = "-"
+		}
+		rows = append(rows, []string{f.Severity, env, f.Rule, f.Message})
+	}
+	if err := renderTable(os.Stdout, []string{"级别", "环境", "规则", "问题"}, rows, 0); err != nil {
+		return err
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("配置检查发现 %d 个错误", errorCount)
+	}
+	return nil
+}
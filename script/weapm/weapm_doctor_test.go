@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// ==================== 配置安全检查 ====================
+
+func TestCheckConfigSecurityDefaultPasswords(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+active_env: dev
+dev:
+  base_url: "http://dev.example.com"
+  password: "Weapm@123admin"
+staging:
+  base_url: "http://staging.example.com"
+prod:
+  base_url: "https://prod.example.com"
+  password: "s3cret"
+token:
+  base_url: "https://token.example.com"
+  auth_mode: bearer
+  token: "abc"
+unused:
+  description: "未配置 base_url,视为未启用"
+`)
+
+	warnings, err := checkConfigSecurity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envs []string
+	for _, w := range warnings {
+		if w.Env != "" {
+			envs = append(envs, w.Env)
+		}
+	}
+	// 显式默认密码及未配置密码的环境都会告警
+	if want := []string{"dev", "staging"}; !reflect.DeepEqual(envs, want) {
+		t.Errorf("warned envs = %v, want %v", envs, want)
+	}
+}
+
+func TestCheckConfigSecurityPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 不使用 Unix 权限位")
+	}
+	path := writeConfig(t, "config.yaml", "dev:\n  base_url: \"http://dev.example.com\"\n  password: \"s3cret\"\n")
+
+	warnings, err := checkConfigSecurity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %+v, want none for a 0600 file", warnings)
+	}
+
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	warnings, err = checkConfigSecurity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Fix != "chmod 600 "+path || !strings.Contains(warnings[0].Problem, "0644") {
+		t.Errorf("warnings = %+v, want a chmod 600 fix", warnings)
+	}
+}
+
+func TestCheckConfigSecurityMissingFile(t *testing.T) {
+	if _, err := checkConfigSecurity("/nonexistent/config.yaml"); err == nil {
+		t.Fatal("checkConfigSecurity(missing) error = nil")
+	}
+}
+
+// ==================== 配置语义检查 ====================
+
+func TestLintConfigFile(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+active_env: staging
+dev:
+  base_url: "http://dev.example.com"
+  timeout: 30
+  insecure_skip_verify: true
+prod:
+  base_url: "http://prod.example.com"
+  timeout: 0
+  insecure_skip_verify: true
+`)
+	configFile, err := readConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range lintConfigFile(configFile) {
+		got = append(got, f.Severity+"/"+f.Env+"/"+f.Rule)
+	}
+	// 开发环境允许明文 HTTP 及关闭证书校验
+	want := []string{
+		"warning/prod/timeout",
+		"error/prod/default-credentials",
+		"error/prod/insecure-skip-verify",
+		"error/prod/plain-http",
+		"error/staging/active-env",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %v, want %v", got, want)
+	}
+}
+
+func TestLintConfigFileClean(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+active_env: prod
+prod:
+  base_url: "https://prod.example.com"
+  password: "s3cret"
+  timeout: 30
+`)
+	configFile, err := readConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings := lintConfigFile(configFile); len(findings) != 0 {
+		t.Errorf("findings = %+v, want none", findings)
+	}
+	var lintErr error
+	captureStdout(t, func() { lintErr = cmdConfigLint(path) })
+	if lintErr != nil {
+		t.Errorf("cmdConfigLint() = %v, want nil", lintErr)
+	}
+}
+
+func TestConfigFileCommand(t *testing.T) {
+	tests := []struct {
+		args CommandLineArgs
+		want bool
+	}{
+		{CommandLineArgs{Command: "config", Positional: []string{"lint"}}, true},
+		{CommandLineArgs{Command: "config", Positional: []string{"doctor"}}, true},
+		// show 需要加载配置
+		{CommandLineArgs{Command: "config", Positional: []string{"show"}}, false},
+		{CommandLineArgs{Command: "config"}, false},
+		{CommandLineArgs{Command: "clusters", Positional: []string{"lint"}}, false},
+	}
+	for _, tt := range tests {
+		if got := configFileCommand(&tt.args) != nil; got != tt.want {
+			t.Errorf("configFileCommand(%s %v) != nil = %v, want %v", tt.args.Command, tt.args.Positional, got, tt.want)
+		}
+	}
+}
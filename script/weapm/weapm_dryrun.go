@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+)
+
+// ==================== 演练模式 ====================
+
+// WithDryRunOutput 指定演练模式 (Config.DryRun) 下请求计划的输出位置,默认 os.Stderr
+func WithDryRunOutput(w io.Writer) ClientOption {
+	return func(o *clientOptions) {
+		o.dryRunOut = w
+	}
+}
+
+// asRead 标记本次请求为查询 (如 URL 过长时改用 POST 发送的 GET),演练模式下照常发送
+func asRead() RequestOption {
+	return func(o *requestOptions) {
+		o.read = true
+	}
+}
+
+// skipForDryRun 演练模式下是否跳过该请求: 只跳过变更请求,查询请求照常发送以便预检和展示
+func (c *Client) skipForDryRun(method string, options *requestOptions) bool {
+	if !c.config.DryRun || options.read {
+		return false
+	}
+	return method != "GET" && method != "HEAD"
+}
+
+// printDryRun 输出将要发送的请求: 方法、完整 URL (已脱敏) 及请求体,JSON 请求体缩进输出
+func (c *Client) printDryRun(method, endpoint string, body []byte) {
+	w := c.dryRunOut
+	if w == nil {
+		w = os.Stderr
+	}
+
+	fullURL := c.endpointURL(endpoint)
+	if u, err := url.Parse(fullURL); err == nil {
+		fullURL = redactURL(u, c.config.SensitiveParams)
+	}
+	fmt.Fprintf(w, "[dry-run] %s %s\n", method, fullURL)
+
+	if len(body) == 0 {
+		return
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	fmt.Fprintf(w, "%s\n", body)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== 演练模式 ====================
+
+// newDryRunClient 创建演练模式的客户端,请求计划写入返回的 buffer
+func newDryRunClient(t *testing.T, h http.Handler, configure ...func(*Config)) (*Client, *bytes.Buffer, string) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	config := DefaultConfig(srv.URL)
+	config.RetryBackoff = time.Millisecond
+	config.DryRun = true
+	for _, fn := range configure {
+		fn(config)
+	}
+	var plan bytes.Buffer
+	return NewClient(config, WithDryRunOutput(&plan)), &plan, srv.URL
+}
+
+func TestDryRunSkipsMutatingRequests(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", resultHandler(`[{"cluster_name":"LOG001"}]`))
+	client, plan, baseURL := newDryRunClient(t, api)
+
+	// 查询请求照常发送
+	if _, err := client.GetClusters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	err := client.UpdateClusterNode(context.Background(), "LOG001", "10.0.0.1", &UpdateClusterNodeRequest{CpuLimit: "16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := api.requests(); len(got) != 1 || got[0] != "GET /operation/clusters" {
+		t.Errorf("requests = %v, want only the GET", got)
+	}
+	want := "[dry-run] PUT " + baseURL + "/operation/clusters/LOG001/nodes/10.0.0.1\n{\n  \"cpulimit\": \"16\"\n}\n"
+	if plan.String() != want {
+		t.Errorf("plan = %q, want %q", plan.String(), want)
+	}
+}
+
+func TestDryRunPlanRedactsURL(t *testing.T) {
+	client, plan, _ := newDryRunClient(t, newFakeAPI())
+	if _, err := client.doRequest(context.Background(), "DELETE", "/operation/clusters/nodes/10.0.0.1?token=abc", nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plan.String(), "abc") || !strings.Contains(plan.String(), "token=***") {
+		t.Errorf("plan = %q, want the token redacted", plan.String())
+	}
+}
+
+func TestDryRunSendsLongSearch(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("POST /operation/subsystems/search", resultHandler(`[]`))
+	client, plan, _ := newDryRunClient(t, api, func(c *Config) { c.MaxURLLength = 64 })
+
+	// URL 过长改用 POST 的查询仍视为读请求
+	dept := strings.Repeat("d", 100)
+	if _, err := client.SearchSubsystems(context.Background(), &SearchSubsystemsRequest{DevDept: &dept}); err != nil {
+		t.Fatal(err)
+	}
+	if n := api.count("POST /operation/subsystems/search"); n != 1 || plan.Len() != 0 {
+		t.Errorf("search requests = %d, plan = %q, want the search sent", n, plan.String())
+	}
+}
+
+func TestDryRunDeleteNodeSkipsPrompt(t *testing.T) {
+	api := moveFixture()
+	client, plan, _ := newDryRunClient(t, api)
+	// 标准输入为空时确认提示会取消删除
+	withStdin(t, "")
+
+	var err error
+	captureStdout(t, func() {
+		err = cmdDeleteNode(client, &CommandLineArgs{Positional: []string{"10.0.0.1"}, ClusterName: "LOG001", DryRun: true})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.count("DELETE ") != 0 || !strings.Contains(plan.String(), "[dry-run] DELETE ") {
+		t.Errorf("requests = %v, plan = %q, want the delete only planned", api.requests(), plan.String())
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ==================== 数据导出 ====================
+
+// rowWriter 逐行写出表格数据,便于流式导出
+type rowWriter interface {
+	WriteRow(cells []interface{}) error
+	Close() error
+}
+
+// newRowWriter 按格式创建写出器
+func newRowWriter(format string, w io.Writer) (rowWriter, error) {
+	switch format {
+	case "xlsx":
+		return newXLSXWriter(w, "subsystems")
+	case "csv":
+		return &csvRowWriter{w: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s (可用: xlsx, csv)", format)
+	}
+}
+
+// csvRowWriter CSV 写出器
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) WriteRow(cells []interface{}) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = fmt.Sprint(cell)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// xlsxWriter 轻量 XLSX 写出器
+// 只生成单个工作表,字符串以内联字符串写入,数值写为数字单元格以便 Excel 直接求和;
+// 工作表内容直接流式写入 zip,内存占用与行数无关
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+}
+
+var xlsxStaticParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+func newXLSXWriter(w io.Writer, sheetName string) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(f, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`, xmlEscape(sheetName))
+
+	// 工作表必须是最后一个条目,之后逐行流式写入
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(sheet)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	return &xlsxWriter{zw: zw, sheet: bw}, nil
+}
+
+func (x *xlsxWriter) WriteRow(cells []interface{}) error {
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+	for i, cell := range cells {
+		ref := xlsxColumn(i) + strconv.Itoa(x.row)
+		switch v := cell.(type) {
+		case int:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case int64:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case float64:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			fmt.Fprintf(x.sheet, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, xmlEscape(fmt.Sprint(v)))
+		}
+	}
+	_, err := x.sheet.WriteString("</row>")
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	x.sheet.WriteString("</sheetData></worksheet>")
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// xlsxColumn 将从 0 开始的列序号转换为列字母 (0 -> A, 26 -> AA)
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// ==================== 子系统清单导出 ====================
+
+// exportBatchSize 每批获取详情的子系统数量,控制导出时的内存占用
+const exportBatchSize = 100
+
+var subsystemExportHeaders = []interface{}{
+	"subsys_id", "subsys_name", "subsys_chtname", "devdept", "business_owner",
+	"subsystem_owner", "system_name", "state", "important_level", "cluster_name",
+	"expected_traffic", "actual_traffic",
+}
+
+// ExportSubsystems 导出子系统清单,按批获取详情以补充归属集群和流量
+// 详情获取失败的子系统仍会导出基本信息,流量列为 0; redactor 不为 nil 时写出前先脱敏
+func (c *Client) ExportSubsystems(ctx context.Context, w rowWriter, redactor *Redactor) error {
+	subsystems, err := c.GetSubsystems(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteRow(subsystemExportHeaders); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(subsystems); start += exportBatchSize {
+		end := start + exportBatchSize
+		if end > len(subsystems) {
+			end = len(subsystems)
+		}
+		batch := subsystems[start:end]
+
+		details := make([]*SubsystemDetailResult, len(batch))
+		forEachConcurrent(len(batch), defaultConcurrency, func(i int) {
+			detail, err := c.GetSubsystemDetail(ctx, batch[i].SubsysID)
+			if err != nil {
+				logger.Printf("获取子系统 %s 详情失败: %v", batch[i].SubsysID, err)
+				return
+			}
+			details[i] = detail
+		})
+
+		for i, s := range batch {
+			redactor.Apply(&s)
+			var cluster string
+			var expected, actual int64
+			if d := details[i]; d != nil {
+				redactor.Apply(d)
+				cluster, expected, actual = d.ClusterName, d.ExpectedTraffic, d.ActualTraffic
+			}
+			row := []interface{}{
+				s.SubsysID, s.SubsysName, s.SubsysChtname, s.DevDept, s.BusinessOwner,
+				s.SubsystemOwner, s.SystemName, s.State, s.ImportantLevel, cluster,
+				expected, actual,
+			}
+			if err := w.WriteRow(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func cmdExportSubsystems(client *Client, args *CommandLineArgs) error {
+	if args.Out == "" {
+		return fmt.Errorf("请使用 --out 指定输出文件")
+	}
+	format := args.Format
+	if format == "" {
+		format = "xlsx"
+	}
+
+	var redactor *Redactor
+	if args.Redact != "" {
+		var err error
+		if redactor, err = NewRedactor(args.Redact, args.RedactMode, SubsystemDetailResult{}); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(args.Out)
+	if err != nil {
+		return fmt.Errorf("创建输出文件失败: %w", err)
+	}
+	defer f.Close()
+
+	w, err := newRowWriter(format, f)
+	if err != nil {
+		return err
+	}
+	if err := client.ExportSubsystems(args.Context(), w, redactor); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("写入输出文件失败: %w", err)
+	}
+
+	fmt.Printf(`{"code": 0, "message": "已导出到 %s"}`+"\n", args.Out)
+	return nil
+}
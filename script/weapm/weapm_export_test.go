@@ -0,0 +1,151 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== XLSX 写出 ====================
+
+func TestXLSXColumn(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := xlsxColumn(tt.i); got != tt.want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", tt.i, got, tt.want)
+		}
+	}
+}
+
+// readZipEntry 读取 zip 中指定条目的内容
+func readZipEntry(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	t.Fatalf("zip entry %s not found", name)
+	return ""
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newRowWriter("xlsx", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]interface{}{"name", "traffic"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]interface{}{"a<b & c", int64(2048)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet := readZipEntry(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t>name</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t>a&lt;b &amp; c</t></is></c>`,
+		`<c r="B2"><v>2048</v></c>`, // 数值写为数字单元格
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet missing %s:\n%s", want, sheet)
+		}
+	}
+	if !strings.HasSuffix(sheet, "</sheetData></worksheet>") {
+		t.Errorf("sheet not closed: %s", sheet)
+	}
+	if workbook := readZipEntry(t, buf.Bytes(), "xl/workbook.xml"); !strings.Contains(workbook, `name="subsystems"`) {
+		t.Errorf("workbook = %s, want sheet named subsystems", workbook)
+	}
+}
+
+func TestNewRowWriterUnknownFormat(t *testing.T) {
+	if _, err := newRowWriter("ods", io.Discard); err == nil {
+		t.Fatal("newRowWriter(ods) error = nil, want unsupported format")
+	}
+}
+
+// ==================== 子系统清单导出 ====================
+
+func TestExportSubsystems(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/subsystems", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []SubSystem{
+			{SubsysID: "SYS001", SubsysName: "payment", State: "enabled"},
+			{SubsysID: "SYS002", SubsysName: "order", State: "enabled"},
+		})
+	})
+	api.handle("GET /operation/subsystem/SYS001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, SubsystemDetailResult{ClusterName: "LOG001", ExpectedTraffic: 2048, ActualTraffic: 1024})
+	})
+	// SYS002 详情获取失败,仍导出基本信息
+	client := newTestClient(t, api)
+
+	var buf bytes.Buffer
+	w, err := newRowWriter("csv", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ExportSubsystems(context.Background(), w, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d rows, want header + 2", len(records))
+	}
+	if records[0][0] != "subsys_id" || len(records[0]) != len(subsystemExportHeaders) {
+		t.Errorf("header = %v", records[0])
+	}
+	want := [][]string{
+		{"SYS001", "payment", "LOG001", "2048", "1024"},
+		{"SYS002", "order", "", "0", "0"},
+	}
+	for i, record := range records[1:] {
+		got := []string{record[0], record[1], record[9], record[10], record[11]}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("row %d = %v, want %v", i+1, got, want[i])
+		}
+	}
+}
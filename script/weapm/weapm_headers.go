@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// ==================== 自定义请求头 ====================
+
+// HeaderRequestID 关联 ID 请求头,未指定时每次调用生成一个随机值,重试沿用同一值
+const HeaderRequestID = "X-Request-ID"
+
+// headersKey context 中单次调用请求头的键
+type headersKey struct{}
+
+// WithHeaders 返回附带请求头的 context,使用该 context 的调用都会发送这些请求头,
+// 优先于 Config.DefaultHeaders; 多次调用时合并,同名请求头以后设置的为准
+//
+//	ctx = WithHeaders(ctx, map[string]string{HeaderRequestID: traceID})
+//	clusters, err := client.GetClusters(ctx)
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := make(map[string]string, len(headers))
+	for name, value := range headersFromContext(ctx) {
+		merged[name] = value
+	}
+	for name, value := range headers {
+		merged[http.CanonicalHeaderKey(name)] = value
+	}
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+// headersFromContext 返回 WithHeaders 设置的请求头,未设置时为 nil
+func headersFromContext(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(headersKey{}).(map[string]string)
+	return headers
+}
+
+// newRequestID 生成 16 字节随机数的十六进制表示
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// withRequestID 默认请求头及 ctx 中都没有 X-Request-ID 时生成一个,
+// 在一次调用开始时设置,使所有重试尝试使用相同的关联 ID
+func (c *Client) withRequestID(ctx context.Context) context.Context {
+	key := http.CanonicalHeaderKey(HeaderRequestID)
+	if _, ok := headersFromContext(ctx)[key]; ok {
+		return ctx
+	}
+	for name := range c.config.DefaultHeaders {
+		if http.CanonicalHeaderKey(name) == key {
+			return ctx
+		}
+	}
+	return WithHeaders(ctx, map[string]string{HeaderRequestID: newRequestID()})
+}
+
+// setHeaders 依次设置 Config.DefaultHeaders 及请求 context 中的请求头
+func (c *Client) setHeaders(req *http.Request) {
+	for name, value := range c.config.DefaultHeaders {
+		req.Header.Set(name, value)
+	}
+	for name, value := range headersFromContext(req.Context()) {
+		req.Header.Set(name, value)
+	}
+}
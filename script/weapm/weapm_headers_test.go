@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+// ==================== 自定义请求头 ====================
+
+func TestWithHeaders(t *testing.T) {
+	ctx := WithHeaders(context.Background(), map[string]string{"x-tenant": "ops", "X-Env": "dev"})
+	ctx = WithHeaders(ctx, map[string]string{"x-env": "prod"})
+
+	// 名称规范化后合并,后设置的为准
+	want := map[string]string{"X-Tenant": "ops", "X-Env": "prod"}
+	if got := headersFromContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("headers = %v, want %v", got, want)
+	}
+	if got := headersFromContext(context.Background()); got != nil {
+		t.Errorf("headers without WithHeaders = %v, want nil", got)
+	}
+}
+
+// headerRecorder 记录每次请求的请求头,前 failing 次返回 503
+type headerRecorder struct {
+	mu      sync.Mutex
+	failing int
+	seen    []http.Header
+}
+
+func (h *headerRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.seen = append(h.seen, r.Header.Clone())
+	fail := len(h.seen) <= h.failing
+	h.mu.Unlock()
+	if fail {
+		respondError(w, http.StatusServiceUnavailable, 503, "busy")
+		return
+	}
+	respondResult(w, []LogClusterInfo{})
+}
+
+var requestIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func TestRequestHeaders(t *testing.T) {
+	rec := &headerRecorder{}
+	client := newTestClient(t, rec, func(c *Config) {
+		c.DefaultHeaders = map[string]string{"X-Tenant": "ops", "X-Env": "dev"}
+	})
+
+	ctx := WithHeaders(context.Background(), map[string]string{"x-env": "prod"})
+	if _, err := client.GetClusters(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range rec.seen {
+		// 按次设置的请求头优先于默认请求头
+		if h.Get("X-Tenant") != "ops" || h.Get("X-Env") != "prod" {
+			t.Errorf("headers = %v, want X-Tenant ops and X-Env prod", h)
+		}
+		if id := h.Get(HeaderRequestID); !requestIDPattern.MatchString(id) {
+			t.Errorf("%s = %q, want a generated ID", HeaderRequestID, id)
+		}
+	}
+	if len(rec.seen) != 2 || rec.seen[0].Get(HeaderRequestID) == rec.seen[1].Get(HeaderRequestID) {
+		t.Errorf("request IDs = %v, want a new ID per call", rec.seen)
+	}
+}
+
+func TestRequestIDReusedAcrossRetries(t *testing.T) {
+	rec := &headerRecorder{failing: 2}
+	client := newTestClient(t, rec)
+
+	if _, err := client.GetClusters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.seen) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(rec.seen))
+	}
+	id := rec.seen[0].Get(HeaderRequestID)
+	for i, h := range rec.seen {
+		if h.Get(HeaderRequestID) != id {
+			t.Errorf("attempt %d %s = %q, want %q", i+1, HeaderRequestID, h.Get(HeaderRequestID), id)
+		}
+	}
+}
+
+func TestRequestIDProvided(t *testing.T) {
+	tests := []struct {
+		name      string
+		defaults  map[string]string
+		ctxHeader map[string]string
+		want      string
+	}{
+		{"from context", nil, map[string]string{"x-request-id": "trace-1"}, "trace-1"},
+		{"from default headers", map[string]string{"x-request-id": "static-1"}, nil, "static-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &headerRecorder{}
+			client := newTestClient(t, rec, func(c *Config) { c.DefaultHeaders = tt.defaults })
+			ctx := context.Background()
+			if tt.ctxHeader != nil {
+				ctx = WithHeaders(ctx, tt.ctxHeader)
+			}
+			if _, err := client.GetClusters(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.seen[0].Values(HeaderRequestID); !reflect.DeepEqual(got, []string{tt.want}) {
+				t.Errorf("%s = %q, want [%s]", HeaderRequestID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultHeadersRedacted(t *testing.T) {
+	client := newTestClient(t, newFakeAPI(), func(c *Config) {
+		c.DefaultHeaders = map[string]string{"X-Auth-Token": "s3cret"}
+	})
+	if got := client.EffectiveConfig().DefaultHeaders["X-Auth-Token"]; got != redactedValue {
+		t.Errorf("EffectiveConfig().DefaultHeaders = %q, want %q", got, redactedValue)
+	}
+	// 不修改客户端实际使用的配置
+	if client.config.DefaultHeaders["X-Auth-Token"] != "s3cret" {
+		t.Error("EffectiveConfig modified the client's headers")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ==================== 配置模板 ====================
+
+// configFieldDoc 配置模板中字段的默认值及说明
+type configFieldDoc struct {
+	value   interface{}
+	comment string
+}
+
+// configFieldDocs 按 yaml 字段名索引的默认值及说明,未列出的 EnvConfig 字段按零值写入
+var configFieldDocs = map[string]configFieldDoc{
+	"base_url":                {"http://localhost:8080", "API 基础 URL (必填)"},
+	"username":                {"weapmUser", "用户名"},
+	"password":                {"", "密码, 建议使用 \"${WEAPM_PASSWORD}\" 引用环境变量; 为空时使用内置默认密码"},
+	"auth_mode":               {AuthModeBasic, "认证方式: basic / bearer / apikey"},
+	"token":                   {"", "auth_mode 为 bearer 时必填"},
+	"api_key":                 {"", "auth_mode 为 apikey 时必填"},
+	"timeout":                 {30, "单次请求超时时间(秒)"},
+	"max_retries":             {3, "最大重试次数"},
+	"max_status_retries":      {0, "可重试状态码的最大重试次数, 0 时使用 max_retries"},
+	"max_connection_retries":  {0, "连接失败的最大重试次数, 0 时使用 max_retries"},
+	"retry_backoff_factor":    {0.5, "重试退避基数(秒), 第 n 次重试等待 基数 * 2^(n-1)"},
+	"retry_backoff_max":       {30, "单次退避上限(秒)"},
+	"retry_jitter":            {false, "在 [0, 退避时间] 内随机等待"},
+	"retry_after_max":         {120, "服务端 Retry-After 的等待上限(秒)"},
+	"pool_connections":        {defaultPoolConnections, "每个主机的连接池大小"},
+	"enable_logging":          {true, "是否启用请求日志"},
+	"cache_ttl":               {0, "GET 响应缓存时长(秒), 0 表示不缓存"},
+	"max_limit":               {defaultMaxLimit, "搜索/分页 limit 上限"},
+	"envelope_key":            {"result", "响应中数据所在的字段名"},
+	"max_response_bytes":      {defaultMaxResponseBytes, "单个响应(解压后)最大字节数"},
+	"max_url_length":          {defaultMaxURLLength, "完整 URL 最大长度, 超出时改用 POST 表单发送参数"},
+	"base_path":               {defaultBasePath, "接口路径前缀"},
+	"success_codes":           {[]int{0}, "视为成功的业务码"},
+	"insecure_skip_verify":    {false, "跳过 TLS 证书校验, 仅限测试环境"},
+	"tls_ca_cert_file":        {"", "私有 CA 证书 (PEM)"},
+	"tls_client_cert_file":    {"", "mTLS 客户端证书 (PEM), 需与 tls_client_key_file 同时配置"},
+	"tls_client_key_file":     {"", "mTLS 客户端私钥 (PEM)"},
+	"proxy":                   {"", "代理 URL (http/https/socks5), 为空时使用 HTTP_PROXY 等环境变量"},
+	"signing_secret":          {"", "服务端要求请求签名时填写"},
+	"cluster_name_pattern":    {DefaultClusterNamePattern, "集群名称格式(正则)"},
+	"skip_cluster_name_check": {false, "关闭集群名称格式校验"},
+	"legacy_adjust_query":     {false, "调整子系统归属集群时以查询参数发送 (兼容旧版服务端)"},
+	"user_agent":              {"", "请求的 User-Agent, 为空时使用 weapm-client/<版本> (env=<环境>)"},
+	"rate_limit":              {0, "每秒最多发送的请求数 (含重试), 0 表示不限制"},
+	"rate_burst":              {0, "限流允许的突发请求数, 0 时为 1"},
+	"default_headers":         {map[string]string{}, "每个请求附带的请求头, 未包含 X-Request-ID 时每次调用自动生成"},
+	"audit_syslog":            {"", "变更请求审计写入的 syslog: local / udp://host:514 / tcp://host:514, 为空时不审计"},
+	"audit_syslog_facility":   {defaultAuditFacility, "审计 syslog facility"},
+	"audit_syslog_tag":        {defaultAuditTag, "审计 syslog tag"},
+	"sensitive_params":        {DefaultSensitiveParams, "请求日志中值替换为 *** 的查询参数"},
+	"description":             {"", "环境描述"},
+}
+
+// formatYAMLValue 将默认值格式化为 YAML 标量或流式序列
+func formatYAMLValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []int:
+		items := make([]string, len(v))
+		for i, n := range v {
+			items[i] = strconv.Itoa(n)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = strconv.Quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = key + ": " + strconv.Quote(v[key])
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeEnvTemplate 按 EnvConfig 字段顺序写入一个环境的配置,overrides 覆盖部分字段的默认值
+func writeEnvTemplate(b *strings.Builder, env string, overrides map[string]interface{}) {
+	fmt.Fprintf(b, "%s:\n", env)
+	t := reflect.TypeOf(EnvConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		doc, ok := configFieldDocs[name]
+		value := doc.value
+		if !ok {
+			value = reflect.Zero(field.Type).Interface()
+		}
+		if v, ok := overrides[name]; ok {
+			value = v
+		}
+
+		line := fmt.Sprintf("  %s: %s", name, formatYAMLValue(value))
+		if doc.comment != "" {
+			line = padCell(line, 34) + " # " + doc.comment
+		}
+		fmt.Fprintln(b, line)
+	}
+}
+
+// configTemplate 生成包含 dev、prod 及 active_env 的带注释配置模板
+func configTemplate() string {
+	var b strings.Builder
+	b.WriteString("# WEAPM-LOGSERVER API 客户端配置文件 (由 config init 生成)\n")
+	b.WriteString("# 凭据优先级: 环境变量 (WEAPM_USERNAME / WEAPM_PASSWORD / WEAPM_TOKEN) > 命令行参数 > 配置文件\n\n")
+	b.WriteString("# 开发/测试环境配置\n")
+	writeEnvTemplate(&b, "dev", map[string]interface{}{
+		"description": "开发测试环境",
+	})
+	b.WriteString("\n# 生产环境配置\n")
+	writeEnvTemplate(&b, "prod", map[string]interface{}{
+		"base_url":       "https://weapm.example.com",
+		"enable_logging": false,
+		"description":    "生产环境",
+	})
+	b.WriteString("\n# 默认使用的环境 (可通过 --env 覆盖)\n")
+	b.WriteString("active_env: \"dev\"\n")
+	return b.String()
+}
+
+// cmdConfigInit 写入配置模板,文件已存在时需指定 force 才会覆盖
+func cmdConfigInit(path string, force bool) error {
+	if path == "" {
+		defaultPath, err := defaultConfigPath()
+		if err != nil {
+			return err
+		}
+		path = defaultPath
+	}
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("配置文件已存在: %s (使用 --force 覆盖)", path)
+	}
+
+	// 配置文件包含凭据,仅所有者可读写
+	if err := os.WriteFile(path, []byte(configTemplate()), 0600); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	fmt.Printf("✅ 已生成配置文件: %s\n", path)
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== 配置模板 ====================
+
+func TestFormatYAMLValue(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{"dev", `"dev"`},
+		{`^LOG\d+$`, `"^LOG\\d+$"`},
+		{[]int{0, 200}, "[0, 200]"},
+		{30, "30"},
+		{false, "false"},
+		{map[string]string{}, "{}"},
+		{map[string]string{"X-Tenant": "ops", "X-Env": "a\"b"}, `{X-Env: "a\"b", X-Tenant: "ops"}`},
+	}
+	for _, tt := range tests {
+		if got := formatYAMLValue(tt.v); got != tt.want {
+			t.Errorf("formatYAMLValue(%#v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+// 模板包含 EnvConfig 的全部字段,且生成后可直接加载
+func TestConfigTemplateLoads(t *testing.T) {
+	clearCredentialEnv(t)
+	template := configTemplate()
+	envType := reflect.TypeOf(EnvConfig{})
+	for i := 0; i < envType.NumField(); i++ {
+		name := strings.Split(envType.Field(i).Tag.Get("yaml"), ",")[0]
+		if !strings.Contains(template, "\n  "+name+": ") {
+			t.Errorf("template missing field %s", name)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	captureStdout(t, func() {
+		if err := cmdConfigInit(path, false); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var dev, prod *Config
+	captureStdout(t, func() {
+		var err error
+		if dev, err = LoadConfigFromYAML(path, ""); err != nil {
+			t.Fatalf("LoadConfigFromYAML(dev): %v", err)
+		}
+		if prod, err = LoadConfigFromYAML(path, "prod"); err != nil {
+			t.Fatalf("LoadConfigFromYAML(prod): %v", err)
+		}
+	})
+	if dev.Env != "dev" || dev.BaseURL != "http://localhost:8080" || dev.Timeout != 30*time.Second {
+		t.Errorf("dev = %+v, want the template defaults", dev)
+	}
+	if dev.ClusterNamePattern != DefaultClusterNamePattern {
+		t.Errorf("ClusterNamePattern = %q, want %q", dev.ClusterNamePattern, DefaultClusterNamePattern)
+	}
+	if prod.BaseURL != "https://weapm.example.com" || prod.EnableLogging {
+		t.Errorf("prod = %+v, want the prod overrides", prod)
+	}
+}
+
+func TestCmdConfigInitExistingFile(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "dev: {}\n")
+
+	captureStdout(t, func() {
+		if err := cmdConfigInit(path, false); err == nil || !strings.Contains(err.Error(), "--force") {
+			t.Errorf("error = %v, want a hint to use --force", err)
+		}
+	})
+	if data, _ := os.ReadFile(path); string(data) != "dev: {}\n" {
+		t.Errorf("existing file was modified without --force: %q", data)
+	}
+
+	captureStdout(t, func() {
+		if err := cmdConfigInit(path, true); err != nil {
+			t.Fatal(err)
+		}
+	})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != configTemplate() {
+		t.Error("file was not overwritten with --force")
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// ==================== 表格输出 ====================
+
+// ellipsis 截断后追加的省略号
+const ellipsis = "…"
+
+// runeWidth 返回字符在终端中的显示宽度 (中日韩及全角字符占 2 列)
+func runeWidth(r rune) int {
+	switch {
+	case r == 0:
+		return 0
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+		return 0
+	case r < 0x1100:
+		return 1
+	case r <= 0x115F, // 谚文字母
+		r >= 0x2E80 && r <= 0x303E,   // CJK 部首、符号和标点
+		r >= 0x3041 && r <= 0x33FF,   // 假名、CJK 兼容字符
+		r >= 0x3400 && r <= 0x4DBF,   // CJK 扩展 A
+		r >= 0x4E00 && r <= 0x9FFF,   // CJK 统一汉字
+		r >= 0xA000 && r <= 0xA4CF,   // 彝文
+		r >= 0xAC00 && r <= 0xD7A3,   // 谚文音节
+		r >= 0xF900 && r <= 0xFAFF,   // CJK 兼容汉字
+		r >= 0xFE30 && r <= 0xFE4F,   // CJK 兼容形式
+		r >= 0xFF00 && r <= 0xFF60,   // 全角字符
+		r >= 0xFFE0 && r <= 0xFFE6,   // 全角符号
+		r >= 0x1F300 && r <= 0x1F64F, // Emoji
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD: // CJK 扩展 B 及以后
+		return 2
+	}
+	return 1
+}
+
+// displayWidth 计算字符串的显示宽度
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// truncateCell 将单元格截断到 maxWidth 显示宽度以内,超出部分以省略号代替
+// maxWidth <= 0 表示不截断
+func truncateCell(s string, maxWidth int) string {
+	if maxWidth <= 0 || displayWidth(s) <= maxWidth {
+		return s
+	}
+
+	limit := maxWidth - displayWidth(ellipsis)
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if width+w > limit {
+			break
+		}
+		b.WriteRune(r)
+		width += w
+	}
+	b.WriteString(ellipsis)
+	return b.String()
+}
+
+// padCell 按显示宽度在右侧补齐空格
+func padCell(s string, width int) string {
+	if pad := width - displayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// renderTable 以对齐的表格形式输出,列宽按显示宽度计算
+// maxColWidth > 0 时超长单元格会被截断
+func renderTable(w io.Writer, headers []string, rows [][]string, maxColWidth int) error {
+	widths := make([]int, len(headers))
+	cells := make([][]string, 0, len(rows)+1)
+	for _, row := range append([][]string{headers}, rows...) {
+		truncated := make([]string, len(widths))
+		for j := range widths {
+			if j < len(row) {
+				truncated[j] = truncateCell(row[j], maxColWidth)
+			}
+			if dw := displayWidth(truncated[j]); dw > widths[j] {
+				widths[j] = dw
+			}
+		}
+		cells = append(cells, truncated)
+	}
+
+	for _, row := range cells {
+		parts := make([]string, len(widths))
+		for j := range widths {
+			parts[j] = padCell(row[j], widths[j])
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(parts, "  "), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==================== 模板输出 ====================
+
+// templateFuncs 输出模板可用的辅助函数
+var templateFuncs = template.FuncMap{
+	"bytes":    humanizeBytes,
+	"join":     strings.Join,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"truncate": func(width int, s string) string { return truncateCell(s, width) },
+}
+
+// humanizeBytes 将字节数格式化为易读形式,如 1536 -> "1.5 KB"
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// parseOutputTemplate 解析 --template / --template-file 指定的模板,两者都未指定时返回 nil
+func parseOutputTemplate(text, file string) (*template.Template, error) {
+	if text != "" && file != "" {
+		return nil, fmt.Errorf("--template 与 --template-file 不能同时使用")
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取模板文件失败: %w", err)
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("模板语法错误: %w", err)
+	}
+	return tmpl, nil
+}
+
+// 输出格式 (--output)
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputCSV   = "csv"
+)
+
+// validateOutput 校验 --output 参数
+func validateOutput(output string) error {
+	switch output {
+	case "", outputJSON, outputTable, outputCSV:
+		return nil
+	default:
+		return fmt.Errorf("不支持的输出格式: %s (可用: json, table, csv)", output)
+	}
+}
+
+// tabular 将已知的结果类型转换为表头和行,不支持的类型返回 false
+func tabular(v interface{}) ([]string, [][]string, bool) {
+	switch result := v.(type) {
+	case []LogClusterInfo:
+		headers := []string{"CLUSTER", "DEFAULT", "TOPIC", "BACKEND_DOMAIN", "STORAGE_DOMAIN"}
+		rows := make([][]string, 0, len(result))
+		for _, cluster := range result {
+			isDefault := ""
+			if cluster.IsDefault == 1 {
+				isDefault = "yes"
+			}
+			rows = append(rows, []string{cluster.ClusterName, isDefault, cluster.Topic, cluster.BackendDomain, cluster.StorageDomain})
+		}
+		return headers, rows, true
+	case *LogClusterInfo:
+		return tabular([]LogClusterInfo{*result})
+	case []SubSystem:
+		headers := []string{"ID", "NAME", "DEPARTMENT", "OWNER", "STATE"}
+		rows := make([][]string, 0, len(result))
+		for _, subsystem := range result {
+			rows = append(rows, []string{subsystem.SubsysID, subsystem.SubsysName, subsystem.DevDept, subsystem.SubsystemOwner, subsystem.State})
+		}
+		return headers, rows, true
+	case []LogSubClusterSubSystem:
+		headers := []string{"ID", "NAME", "OWNER", "TRAFFIC", "STATUS"}
+		rows := make([][]string, 0, len(result))
+		for _, subsystem := range result {
+			rows = append(rows, []string{subsystem.SubsystemID, subsystem.SubsysName, subsystem.SubsystemOwner, strconv.FormatInt(subsystem.Traffic, 10), subsystem.Status})
+		}
+		return headers, rows, true
+	case []LogStoreInstance:
+		headers := []string{"CLUSTER", "ADDRESS", "ROLE", "CPU", "MEM", "STATUS"}
+		rows := make([][]string, 0, len(result))
+		for _, node := range result {
+			rows = append(rows, []string{node.ClusterName, node.Address, node.Role, node.CpuLimit, node.MemLimit, node.Status})
+		}
+		return headers, rows, true
+	case []TrafficPoint:
+		headers := []string{"TIMESTAMP", "TRAFFIC"}
+		rows := make([][]string, 0, len(result))
+		for _, point := range result {
+			rows = append(rows, []string{point.Timestamp.Format(time.RFC3339), strconv.FormatInt(point.Traffic, 10)})
+		}
+		return headers, rows, true
+	case []*SubsystemDetailResult:
+		headers := []string{"ID", "NAME", "CLUSTER", "COLLECTED", "EXPECTED_TRAFFIC", "ACTUAL_TRAFFIC"}
+		rows := make([][]string, 0, len(result))
+		for _, detail := range result {
+			rows = append(rows, []string{
+				detail.SubsystemInfo.SubsysID,
+				detail.SubsystemInfo.SubsysName,
+				detail.ClusterName,
+				strconv.FormatBool(detail.Collected),
+				strconv.FormatInt(detail.ExpectedTraffic, 10),
+				strconv.FormatInt(detail.ActualTraffic, 10),
+			})
+		}
+		return headers, rows, true
+	}
+	return nil, nil, false
+}
+
+// writeRows 按 output 格式 (table / csv) 写入表头和行
+func writeRows(w io.Writer, output string, headers []string, rows [][]string, maxColWidth int) error {
+	if output == outputTable {
+		return renderTable(w, headers, rows, maxColWidth)
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(headers)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+// reportFormat 返回自带输出格式的命令 (status、report、cost-report、schema) 的格式:
+// -o 与 --format 等价,同时指定时必须一致
+func reportFormat(args *CommandLineArgs) (string, error) {
+	switch {
+	case args.Output == "":
+		return args.Format, nil
+	case args.Format == "" || args.Format == args.Output:
+		return args.Output, nil
+	default:
+		return "", fmt.Errorf("--format %s 与 -o %s 冲突,请只指定一个", args.Format, args.Output)
+	}
+}
+
+// writeResult 按 output 格式将结果写入 w
+func writeResult(w io.Writer, output string, v interface{}, maxColWidth int) error {
+	if output == outputTable || output == outputCSV {
+		headers, rows, ok := tabular(v)
+		if !ok {
+			return fmt.Errorf("该命令的结果不支持 %s 输出,请使用 json", output)
+		}
+		return writeRows(w, output, headers, rows, maxColWidth)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// printResult 输出命令结果: 指定了模板时按模板渲染,否则按 --output 格式输出 (默认缩进 JSON)
+func printResult(args *CommandLineArgs, v interface{}) error {
+	if args.tmpl != nil {
+		var buf bytes.Buffer
+		if err := args.tmpl.Execute(&buf, v); err != nil {
+			return fmt.Errorf("模板渲染失败: %w", err)
+		}
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	return writeResult(os.Stdout, args.Output, v, args.MaxColWidth)
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"支付系统", 8},
+		{"LOG001 集群", 11},
+		{"ＡＢ", 4}, // 全角字母
+		{"é", 1}, // 组合附加符号不占宽度
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.in); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateCell(t *testing.T) {
+	tests := []struct {
+		in       string
+		maxWidth int
+		want     string
+	}{
+		{"payment", 0, "payment"},
+		{"payment", 7, "payment"},
+		{"payment", 5, "paym…"},
+		{"支付系统", 8, "支付系统"},
+		{"支付系统", 5, "支付…"},
+		// 宽字符放不下时不拆开,宁可少占一列
+		{"支付系统", 4, "支…"},
+		{"a支付", 3, "a…"},
+	}
+	for _, tt := range tests {
+		got := truncateCell(tt.in, tt.maxWidth)
+		if got != tt.want {
+			t.Errorf("truncateCell(%q, %d) = %q, want %q", tt.in, tt.maxWidth, got, tt.want)
+		}
+		if tt.maxWidth > 0 && displayWidth(got) > tt.maxWidth {
+			t.Errorf("truncateCell(%q, %d) width = %d, exceeds limit", tt.in, tt.maxWidth, displayWidth(got))
+		}
+	}
+}
+
+func TestRenderTableAlignsWideCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	err := renderTable(&buf, []string{"ID", "NAME", "OWNER"}, [][]string{
+		{"SYS001", "支付系统", "zhangsan"},
+		{"SYS002", "order", "lisi"},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "" +
+		"ID      NAME      OWNER\n" +
+		"SYS001  支付系统  zhangsan\n" +
+		"SYS002  order     lisi\n"
+	if buf.String() != want {
+		t.Errorf("renderTable output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestRenderTableMaxColWidth(t *testing.T) {
+	var buf bytes.Buffer
+	err := renderTable(&buf, []string{"ID", "NAME"}, [][]string{
+		{"SYS001", "一个非常长的子系统中文名称"},
+	}, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if lines[1] != "SYS001  一个…" {
+		t.Errorf("row = %q, want %q", lines[1], "SYS001  一个…")
+	}
+}
+
+func TestRenderTableShortRows(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderTable(&buf, []string{"A", "B"}, [][]string{{"x"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if want := "A  B\nx\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+// ==================== 模板输出 ====================
+
+func TestHumanizeBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KB"},
+		{5 << 30, "5.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := humanizeBytes(tt.n); got != tt.want {
+			t.Errorf("humanizeBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseOutputTemplate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out.tmpl")
+	if err := os.WriteFile(file, []byte(`{{ len . }}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		text, file string
+		wantNil    bool
+		wantErr    string
+	}{
+		{name: "none", wantNil: true},
+		{name: "inline", text: `{{ len . }}`},
+		{name: "file", file: file},
+		{name: "both", text: `{{ len . }}`, file: file, wantErr: "不能同时使用"},
+		{name: "syntax", text: `{{ range . }}`, wantErr: "模板语法错误"},
+		{name: "missing file", file: filepath.Join(t.TempDir(), "missing.tmpl"), wantErr: "读取模板文件失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseOutputTemplate(tt.text, tt.file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (tmpl == nil) != tt.wantNil {
+				t.Errorf("template = %v, want nil: %v", tmpl, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestOutputTemplateFuncs(t *testing.T) {
+	tmpl, err := parseOutputTemplate(`{{ range . }}{{ upper .SubsystemID }} {{ .Traffic | bytes }} {{ truncate 4 .SubsysName }};{{ end }}`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []LogSubClusterSubSystem{
+		{SubsystemID: "sys001", SubsysName: "pay", Traffic: 2048},
+		{SubsystemID: "sys002", SubsysName: "settlement", Traffic: 100},
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	want := "SYS001 2.0 KB pay;SYS002 100 B " + truncateCell("settlement", 4) + ";"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+// ==================== 输出格式 ====================
+
+func TestValidateOutput(t *testing.T) {
+	for _, output := range []string{"", "json", "table", "csv"} {
+		if err := validateOutput(output); err != nil {
+			t.Errorf("validateOutput(%q) = %v, want nil", output, err)
+		}
+	}
+	if err := validateOutput("yaml"); err == nil {
+		t.Error("validateOutput(yaml) = nil, want an error")
+	}
+}
+
+// updateGolden 使用 go test -run TestWriteResult -update-golden 重新生成 testdata 下的期望输出
+var updateGolden = flag.Bool("update-golden", false, "重新生成 testdata/*.golden")
+
+// assertGolden 比较 got 与 testdata/<name>.golden
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output:\n%s\nwant (%s):\n%s", got, path, want)
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	clusters := []LogClusterInfo{
+		{ClusterName: "LOG001", IsDefault: 1, Topic: "log-topic", BackendDomain: "backend.weapm", StorageDomain: "es.weapm"},
+		{ClusterName: "LOG002", Topic: "log-topic-2", BackendDomain: "backend2.weapm", StorageDomain: "es2.weapm"},
+	}
+	subsystems := []SubSystem{
+		{SubsysID: "SYS001", SubsysName: "支付系统", DevDept: "支付部", SubsystemOwner: "zhangsan", State: "online"},
+	}
+	tests := []struct {
+		golden string
+		output string
+		v      interface{}
+	}{
+		{"clusters.table", "table", clusters},
+		{"clusters.csv", "csv", clusters},
+		{"subsystems.table", "table", subsystems},
+		{"subsystems.csv", "csv", subsystems},
+		{"default.json", "", map[string]int{"count": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeResult(&buf, tt.output, tt.v, 0); err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, tt.golden, buf.String())
+		})
+	}
+}
+
+func TestWriteResultUnsupportedType(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeResult(&buf, "csv", map[string]int{"count": 1}, 0)
+	if err == nil || !strings.Contains(err.Error(), "请使用 json") {
+		t.Errorf("error = %v, want a hint to use json", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want nothing", buf.String())
+	}
+}
+
+func TestReportFormat(t *testing.T) {
+	tests := []struct {
+		format, output string
+		want           string
+		wantErr        bool
+	}{
+		{"", "", "", false},
+		{"csv", "", "csv", false},
+		{"", "json", "json", false},
+		{"json", "json", "json", false},
+		{"json", "csv", "", true},
+	}
+	for _, tt := range tests {
+		got, err := reportFormat(&CommandLineArgs{Format: tt.format, Output: tt.output})
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("reportFormat(--format %q, -o %q) = %q, %v, want %q (wantErr %v)", tt.format, tt.output, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// status、schema、report、cost-report 的 -o 与 --format 等价
+func TestReportCommandsHonorOutputFlag(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/dashboard", resultHandler(`{"clusterLogCounts":[{"clusterName":"LOG001","usedBytes":1073741824}]}`))
+	api.handle("GET /operation/subsystems", resultHandler(`[]`))
+	client := newTestClient(t, api)
+
+	tests := []struct {
+		name string
+		run  func(args *CommandLineArgs) error
+	}{
+		{"cost-report", func(args *CommandLineArgs) error {
+			args.CostPerGB = 0.1
+			return cmdCostReport(client, args)
+		}},
+		{"report departments", func(args *CommandLineArgs) error {
+			args.Positional = []string{"departments"}
+			return cmdReport(client, args)
+		}},
+		{"status", func(args *CommandLineArgs) error {
+			return cmdStatus(client, args)
+		}},
+		{"schema", func(args *CommandLineArgs) error {
+			args.Validate = true
+			return cmdSchema(args)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			out := captureStdout(t, func() { err = tt.run(&CommandLineArgs{Output: "json"}) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !json.Valid([]byte(out)) {
+				t.Errorf("-o json output is not JSON:\n%s", out)
+			}
+
+			err = tt.run(&CommandLineArgs{Format: "json", Output: "csv"})
+			if err == nil || !strings.Contains(err.Error(), "冲突") {
+				t.Errorf("--format json -o csv error = %v, want a conflict", err)
+			}
+		})
+	}
+}
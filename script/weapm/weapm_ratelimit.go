@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// ==================== 客户端限流 ====================
+
+// newRateLimiter 创建每秒 limit 个请求的限流器,burst 小于 1 时按 1 处理
+func newRateLimiter(limit float64, burst int) *rate.Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(limit), burst)
+}
+
+// waitRateLimit 预占一个令牌并等待到可以发送请求
+// 不使用 Limiter.Wait: 时间经由 Clock 获取,测试中可用 FakeClock 推进;
+// ctx 先结束时取消预占,归还令牌并返回 ctx.Err()
+func waitRateLimit(ctx context.Context, clock Clock, limiter *rate.Limiter) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := clock.Now()
+	r := limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return fmt.Errorf("限流配置无效: burst=%d", limiter.Burst())
+	}
+	if err := sleepContext(ctx, clock, r.DelayFrom(now)); err != nil {
+		r.CancelAt(clock.Now())
+		return err
+	}
+	return nil
+}
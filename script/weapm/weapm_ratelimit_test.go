@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ==================== 客户端限流 ====================
+
+func TestWaitRateLimitSpacing(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	limiter := newRateLimiter(2, 2)
+
+	// 初始装满 2 个令牌,第 3 个请求需等待 500ms
+	for i := 0; i < 2; i++ {
+		if err := waitRateLimit(context.Background(), clock, limiter); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done := make(chan error, 1)
+	go func() { done <- waitRateLimit(context.Background(), clock, limiter) }()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(500 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// 长时间空闲后最多积攒 burst 个
+	clock.Advance(time.Hour)
+	now := clock.Now()
+	for i, want := range []time.Duration{0, 0, 500 * time.Millisecond} {
+		if d := limiter.ReserveN(now, 1).DelayFrom(now); d != want {
+			t.Errorf("reservation #%d after idle delay = %v, want %v", i+1, d, want)
+		}
+	}
+}
+
+func TestNewRateLimiterBurstDefault(t *testing.T) {
+	if b := newRateLimiter(1, 0).Burst(); b != 1 {
+		t.Errorf("Burst() = %d, want 1", b)
+	}
+}
+
+func TestWaitRateLimitCanceled(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	limiter := newRateLimiter(1, 1)
+	if err := waitRateLimit(context.Background(), clock, limiter); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- waitRateLimit(ctx, clock, limiter) }()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("waitRateLimit() = %v, want context.Canceled", err)
+	}
+	// 取消后归还预占的令牌,下一个请求只需等 1s
+	now := clock.Now()
+	if d := limiter.ReserveN(now, 1).DelayFrom(now); d != time.Second {
+		t.Errorf("delay after cancel = %v, want 1s", d)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{})
+	})
+	client := newTestClient(t, api, func(c *Config) { c.RateLimit = 1 })
+	clock := NewFakeClock(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	client.SetClock(clock)
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 2; i++ {
+			if _, err := client.GetClusters(context.Background()); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	// 第二个请求等待令牌,时间推进前不发送
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if n := api.count("GET /operation/clusters"); n != 1 {
+		t.Errorf("requests before advancing = %d, want 1", n)
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if n := api.count("GET /operation/clusters"); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
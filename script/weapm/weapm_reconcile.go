@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ==================== 期望状态同步 ====================
+
+// DesiredClusterState 单个集群的节点期望状态 (对应期望状态目录中的一个 YAML 文件)
+//
+//	cluster: LOG008
+//	nodes:
+//	  - address: 127.0.0.2
+//	    role: write
+//	    cpulimit: "8"
+//	    memlimit: "16"
+type DesiredClusterState struct {
+	Cluster string                  `yaml:"cluster"`
+	Nodes   []AddClusterNodeRequest `yaml:"nodes"`
+}
+
+// loadDesiredState 读取目录下所有 *.yaml / *.yml 文件,返回 集群名 -> 期望状态
+// 文件未指定 cluster 时使用文件名 (不含扩展名) 作为集群名
+func loadDesiredState(dir string) (map[string]*DesiredClusterState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取期望状态目录失败: %w", err)
+	}
+
+	states := make(map[string]*DesiredClusterState)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取期望状态文件失败: %w", err)
+		}
+
+		var state DesiredClusterState
+		if err := yaml.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("解析期望状态文件 %s 失败: %w", path, err)
+		}
+		if state.Cluster == "" {
+			state.Cluster = strings.TrimSuffix(entry.Name(), ext)
+		}
+		if _, dup := states[state.Cluster]; dup {
+			return nil, fmt.Errorf("集群 %s 在多个期望状态文件中重复定义", state.Cluster)
+		}
+		states[state.Cluster] = &state
+	}
+
+	return states, nil
+}
+
+// Reconciler 周期性将期望状态目录同步到集群节点
+type Reconciler struct {
+	client  *Client
+	dir     string
+	dryRun  bool
+	force   bool // 允许删除集群全部节点或最后一个 master
+	managed map[string]bool // 上一轮纳管的集群,用于发现被移除的文件
+}
+
+// NewReconciler 创建同步器
+// force 为 false 时,不会删除集群的全部节点或最后一个 master
+func NewReconciler(client *Client, dir string, dryRun, force bool) *Reconciler {
+	return &Reconciler{
+		client:  client,
+		dir:     dir,
+		dryRun:  dryRun,
+		force:   force,
+		managed: make(map[string]bool),
+	}
+}
+
+// RunOnce 执行一轮同步: 读取期望状态、与实际状态对比并应用差异
+// 期望状态文件被移除时仅停止纳管该集群,不会删除其节点
+func (r *Reconciler) RunOnce(ctx context.Context) error {
+	states, err := loadDesiredState(r.dir)
+	if err != nil {
+		return err
+	}
+
+	for cluster := range r.managed {
+		if _, ok := states[cluster]; !ok {
+			logger.Printf("期望状态文件已移除,停止纳管集群: %s", cluster)
+			delete(r.managed, cluster)
+		}
+	}
+
+	clusters := make([]string, 0, len(states))
+	for cluster := range states {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	var failed int
+	for _, cluster := range clusters {
+		if !r.managed[cluster] {
+			logger.Printf("开始纳管集群: %s", cluster)
+			r.managed[cluster] = true
+		}
+		if err := r.reconcileCluster(ctx, states[cluster]); err != nil {
+			logger.Printf("同步集群 %s 失败: %v", cluster, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d 个集群同步失败", failed)
+	}
+	return nil
+}
+
+func (r *Reconciler) reconcileCluster(ctx context.Context, state *DesiredClusterState) error {
+	diff, err := r.client.DiffClusterNodes(ctx, state.Cluster, state.Nodes)
+	if err != nil {
+		return err
+	}
+	if diff.Empty() {
+		return nil
+	}
+
+	prefix := ""
+	if r.dryRun {
+		prefix = "[dry-run] "
+	}
+
+	for i := range diff.ToAdd {
+		node := diff.ToAdd[i]
+		logger.Printf("%s添加节点: %s -> %s (%s)", prefix, node.Address, state.Cluster, node.Role)
+		if r.dryRun {
+			continue
+		}
+		if err := r.client.AddClusterNode(ctx, state.Cluster, &node); err != nil {
+			return fmt.Errorf("添加节点 %s 失败: %w", node.Address, err)
+		}
+	}
+
+	for _, node := range r.plannedRemovals(diff) {
+		logger.Printf("%s删除节点: %s <- %s", prefix, node.Address, state.Cluster)
+		if r.dryRun {
+			continue
+		}
+		if err := r.client.deleteClusterNodeFrom(ctx, state.Cluster, node.Address); err != nil {
+			return fmt.Errorf("删除节点 %s 失败: %w", node.Address, err)
+		}
+	}
+
+	for _, node := range diff.Changed {
+		logger.Printf("节点配置与期望状态不一致,需手动处理: %s (%s)", node.Address, state.Cluster)
+	}
+
+	return nil
+}
+
+// plannedRemovals 返回本轮实际要删除的节点
+// 未指定 --force 时沿用 delete-nodes 的规则保留最后一个 master,
+// 并拒绝删除集群的全部节点 (例如期望状态文件为空),被跳过的节点记录到日志
+func (r *Reconciler) plannedRemovals(diff *NodeDiff) []LogStoreInstance {
+	if r.force || len(diff.ToRemove) == 0 {
+		return diff.ToRemove
+	}
+
+	ips := make([]string, len(diff.ToRemove))
+	for i, node := range diff.ToRemove {
+		ips[i] = node.Address
+	}
+	targets, skipped := planNodeDeletion(diff.Current, ips)
+	for _, s := range skipped {
+		logger.Printf("跳过删除节点: %s <- %s (%s)", s.Address, diff.ClusterName, s.Error)
+	}
+	if len(targets) == len(diff.Current) {
+		logger.Printf("期望状态将删除集群 %s 的全部 %d 个节点,已跳过删除,确认后使用 --force", diff.ClusterName, len(targets))
+		return nil
+	}
+	return targets
+}
+
+// cmdReconcile 按 --interval 周期持续同步期望状态,直到收到中断信号或达到 --timeout
+func cmdReconcile(client *Client, args *CommandLineArgs) error {
+	if args.Dir == "" {
+		return fmt.Errorf("请使用 --dir 指定期望状态目录")
+	}
+	if args.Interval <= 0 {
+		return fmt.Errorf("--interval 必须大于 0")
+	}
+
+	ctx, stop := context.WithCancel(args.Context())
+	defer stop()
+
+	reconciler := NewReconciler(client, args.Dir, args.DryRun, args.Force)
+	for {
+		if err := reconciler.RunOnce(ctx); err != nil {
+			logger.Printf("本轮同步未完成: %v", err)
+		}
+
+		if sleepContext(ctx, client.clock, args.Interval) != nil {
+			logger.Printf("命令已取消,停止同步")
+			return nil
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFiles 在 dir 下按 文件名 -> 内容 写入文件
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadDesiredState(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"LOG001.yaml": "nodes:\n  - address: 10.0.0.1\n    role: write\n",
+		"other.yml":   "cluster: LOG002\nnodes:\n  - address: 10.0.0.2\n",
+		"README.txt":  "not a state file",
+	})
+
+	states, err := loadDesiredState(dir)
+	if err != nil {
+		t.Fatalf("loadDesiredState: %v", err)
+	}
+	if len(states) != 2 {
+		t.Fatalf("got %d clusters, want 2: %v", len(states), states)
+	}
+	// 未指定 cluster 时取文件名
+	if s := states["LOG001"]; s == nil || len(s.Nodes) != 1 || s.Nodes[0].Role != "write" {
+		t.Errorf("LOG001 = %+v", s)
+	}
+	if s := states["LOG002"]; s == nil || s.Nodes[0].Address != "10.0.0.2" {
+		t.Errorf("LOG002 = %+v", s)
+	}
+}
+
+func TestLoadDesiredStateDuplicateCluster(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"LOG001.yaml": "nodes: []\n",
+		"copy.yaml":   "cluster: LOG001\n",
+	})
+
+	if _, err := loadDesiredState(dir); err == nil || !strings.Contains(err.Error(), "重复定义") {
+		t.Errorf("loadDesiredState = %v, want a duplicate cluster error", err)
+	}
+}
+
+func TestDiffClusterNodes(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters/LOG001", clusterWithNodes("LOG001",
+		LogStoreInstance{Address: "10.0.0.1", Role: "write", CpuLimit: "8"},
+		LogStoreInstance{Address: "10.0.0.2", Role: "read"},
+		LogStoreInstance{Address: "10.0.0.4", Role: "write"}))
+	client := newTestClient(t, api)
+
+	diff, err := client.DiffClusterNodes(context.Background(), "LOG001", []AddClusterNodeRequest{
+		{Address: "10.0.0.1", Role: "write", CpuLimit: "8"},
+		{Address: "10.0.0.2", Role: "write"}, // 角色不一致
+		{Address: "10.0.0.3", Role: "write"}, // 新增
+	})
+	if err != nil {
+		t.Fatalf("DiffClusterNodes: %v", err)
+	}
+	if len(diff.ToAdd) != 1 || diff.ToAdd[0].Address != "10.0.0.3" {
+		t.Errorf("ToAdd = %+v, want 10.0.0.3", diff.ToAdd)
+	}
+	if len(diff.ToRemove) != 1 || diff.ToRemove[0].Address != "10.0.0.4" {
+		t.Errorf("ToRemove = %+v, want 10.0.0.4", diff.ToRemove)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Address != "10.0.0.2" {
+		t.Errorf("Changed = %+v, want 10.0.0.2", diff.Changed)
+	}
+}
+
+// reconcileFixture LOG001 现有 10.0.0.1 和 10.0.0.2,期望状态为 10.0.0.1 和 10.0.0.3
+func reconcileFixture(t *testing.T) (*fakeAPI, string) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters/LOG001", clusterWithNodes("LOG001",
+		LogStoreInstance{Address: "10.0.0.1", Role: "write"},
+		LogStoreInstance{Address: "10.0.0.2", Role: "write"}))
+	api.handle("POST /operation/clusters/LOG001/nodes", func(w http.ResponseWriter, r *http.Request) {
+		var node AddClusterNodeRequest
+		json.NewDecoder(r.Body).Decode(&node)
+		if node.Address != "10.0.0.3" {
+			t.Errorf("added %s, want 10.0.0.3", node.Address)
+		}
+		respondResult(w, nil)
+	})
+	api.handle("DELETE /operation/clusters/nodes/10.0.0.2", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, nil)
+	})
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"LOG001.yaml": "nodes:\n  - address: 10.0.0.1\n    role: write\n  - address: 10.0.0.3\n    role: write\n",
+	})
+	return api, dir
+}
+
+func TestReconcilerRunOnce(t *testing.T) {
+	api, dir := reconcileFixture(t)
+	client := newTestClient(t, api)
+
+	if err := NewReconciler(client, dir, false, false).RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if n := api.count("POST /operation/clusters/LOG001/nodes"); n != 1 {
+		t.Errorf("adds = %d, want 1", n)
+	}
+	if n := api.count("DELETE /operation/clusters/nodes/10.0.0.2"); n != 1 {
+		t.Errorf("deletes of 10.0.0.2 = %d, want 1", n)
+	}
+}
+
+func TestReconcilerDryRun(t *testing.T) {
+	api, dir := reconcileFixture(t)
+	client := newTestClient(t, api)
+
+	if err := NewReconciler(client, dir, true, false).RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if n := api.count("POST ") + api.count("DELETE "); n != 0 {
+		t.Errorf("dry run sent %d mutations, want none", n)
+	}
+}
+
+func TestReconcilerStopsManagingRemovedFile(t *testing.T) {
+	api, dir := reconcileFixture(t)
+	client := newTestClient(t, api)
+	r := NewReconciler(client, dir, true, false)
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !r.managed["LOG001"] {
+		t.Fatal("LOG001 not managed after first run")
+	}
+
+	os.Remove(filepath.Join(dir, "LOG001.yaml"))
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if r.managed["LOG001"] {
+		t.Error("LOG001 still managed after its file was removed")
+	}
+	if n := api.count("DELETE "); n != 0 {
+		t.Errorf("removing the state file sent %d deletes, want none", n)
+	}
+}
+
+// emptyStateFixture LOG001 现有 nodes,期望状态文件为空
+func emptyStateFixture(t *testing.T, nodes ...LogStoreInstance) (*fakeAPI, string) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters/LOG001", clusterWithNodes("LOG001", nodes...))
+	for _, node := range nodes {
+		api.handle("DELETE /operation/clusters/nodes/"+node.Address, func(w http.ResponseWriter, r *http.Request) {
+			respondResult(w, nil)
+		})
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"LOG001.yaml": ""})
+	return api, dir
+}
+
+func TestReconcilerRefusesToRemoveAllNodes(t *testing.T) {
+	api, dir := emptyStateFixture(t,
+		LogStoreInstance{Address: "10.0.0.1", Role: "write"},
+		LogStoreInstance{Address: "10.0.0.2", Role: "read"})
+	client := newTestClient(t, api)
+
+	var err error
+	logs := captureLog(func() { err = NewReconciler(client, dir, false, false).RunOnce(context.Background()) })
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if n := api.count("DELETE "); n != 0 {
+		t.Errorf("empty state file sent %d deletes, want none", n)
+	}
+	if !strings.Contains(logs, "全部 2 个节点") {
+		t.Errorf("log = %q, want a skip notice", logs)
+	}
+}
+
+func TestReconcilerKeepsLastMaster(t *testing.T) {
+	api, dir := emptyStateFixture(t,
+		LogStoreInstance{Address: "10.0.0.1", Role: masterRole},
+		LogStoreInstance{Address: "10.0.0.2", Role: "write"})
+	client := newTestClient(t, api)
+
+	var err error
+	logs := captureLog(func() { err = NewReconciler(client, dir, false, false).RunOnce(context.Background()) })
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if n := api.count("DELETE /operation/clusters/nodes/10.0.0.2"); n != 1 {
+		t.Errorf("deletes of 10.0.0.2 = %d, want 1", n)
+	}
+	if n := api.count("DELETE /operation/clusters/nodes/10.0.0.1"); n != 0 {
+		t.Errorf("deleted the last master %d times", n)
+	}
+	if !strings.Contains(logs, "跳过删除节点: 10.0.0.1") {
+		t.Errorf("log = %q, want the master skip logged", logs)
+	}
+}
+
+func TestReconcilerForceRemovesAllNodes(t *testing.T) {
+	api, dir := emptyStateFixture(t,
+		LogStoreInstance{Address: "10.0.0.1", Role: masterRole},
+		LogStoreInstance{Address: "10.0.0.2", Role: "write"})
+	client := newTestClient(t, api)
+
+	if err := NewReconciler(client, dir, false, true).RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if n := api.count("DELETE "); n != 2 {
+		t.Errorf("deletes = %d, want 2 with force", n)
+	}
+}
+
+func TestCmdReconcileStopsWithCommandContext(t *testing.T) {
+	api, dir := reconcileFixture(t)
+	client := newTestClient(t, api)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	args := &CommandLineArgs{Dir: dir, Interval: time.Hour, DryRun: true, ctx: ctx}
+	if err := cmdReconcile(client, args); err != nil {
+		t.Fatal(err)
+	}
+	if n := api.count("GET /operation/clusters/LOG001"); n != 1 {
+		t.Errorf("reconcile rounds = %d, want 1 before the deadline", n)
+	}
+}
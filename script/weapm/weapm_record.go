@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ==================== 快照记录 ====================
+
+// jsonlRecorder 按天滚动的 JSONL 追加写入器
+// 输出路径 dashboard.jsonl 实际写入 dashboard-20260115.jsonl,跨天后自动切换到新文件
+type jsonlRecorder struct {
+	base string
+	day  string
+	f    *os.File
+}
+
+func newJSONLRecorder(path string) *jsonlRecorder {
+	return &jsonlRecorder{base: path}
+}
+
+// dailyPath 在扩展名前插入日期
+func dailyPath(base string, t time.Time) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-" + t.Format("20060102") + ext
+}
+
+// Write 追加一行 JSON,必要时切换到当天的文件
+func (r *jsonlRecorder) Write(t time.Time, v interface{}) error {
+	if day := t.Format("20060102"); day != r.day || r.f == nil {
+		if err := r.Close(); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(dailyPath(r.base, t), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("打开记录文件失败: %w", err)
+		}
+		r.f, r.day = f, day
+	}
+
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = r.f.Write(append(line, '\n'))
+	return err
+}
+
+func (r *jsonlRecorder) Close() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// dashboardSnapshot 一条数据大盘快照
+type dashboardSnapshot struct {
+	Timestamp string          `json:"timestamp"`
+	Dashboard json.RawMessage `json:"dashboard"`
+}
+
+// recordLoop 立即执行一次 fn,之后每隔 interval 执行,直到 ctx 结束
+func recordLoop(ctx context.Context, clock Clock, interval time.Duration, fn func(now time.Time)) {
+	for {
+		fn(clock.Now())
+		if sleepContext(ctx, clock, interval) != nil {
+			return
+		}
+	}
+}
+
+// cmdRecord 按间隔记录数据大盘快照,每行一个带时间戳的 JSON 对象,直到收到中断信号或达到 --timeout
+func cmdRecord(client *Client, args *CommandLineArgs) error {
+	if args.Out == "" {
+		return fmt.Errorf("请使用 --out 指定输出文件")
+	}
+	if args.Interval <= 0 {
+		return fmt.Errorf("--interval 必须大于 0")
+	}
+
+	ctx, stop := context.WithCancel(args.Context())
+	defer stop()
+
+	recorder := newJSONLRecorder(args.Out)
+	defer recorder.Close()
+
+	var writeErr error
+	recordLoop(ctx, client.clock, args.Interval, func(now time.Time) {
+		raw, err := client.GetDashboardRaw(ctx)
+		if err != nil {
+			logger.Printf("获取数据大盘失败,跳过本次记录: %v", err)
+			return
+		}
+		snapshot := dashboardSnapshot{Timestamp: now.Format(time.RFC3339), Dashboard: raw}
+		if err := recorder.Write(now, snapshot); err != nil {
+			writeErr = err
+			stop()
+		}
+	})
+
+	return writeErr
+}
+
+// ==================== 子系统流量记录 ====================
+
+// trafficSample 一个子系统在某次记录时的实际流量
+type trafficSample struct {
+	Timestamp     string `json:"timestamp"`
+	SubsysID      string `json:"subsys_id"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+	ActualTraffic int64  `json:"actual_traffic"`
+}
+
+// recordTrafficOnce 获取当前子系统列表,按批并发获取详情并逐批写入流量记录,返回写入的条数
+// 每次记录重新获取列表,期间新增的子系统从下一次开始记录,已删除 (404) 的子系统跳过;
+// 同一时间只保留一批详情,内存占用不随记录次数增长
+func recordTrafficOnce(ctx context.Context, client *Client, recorder *jsonlRecorder, now time.Time, concurrency int) (int, error) {
+	subsystems, err := client.GetSubsystems(ctx)
+	if err != nil {
+		logger.Printf("获取子系统列表失败,跳过本次记录: %v", err)
+		return 0, nil
+	}
+
+	timestamp := now.Format(time.RFC3339)
+	var written int
+	for start := 0; start < len(subsystems); start += defaultPageSize {
+		end := start + defaultPageSize
+		if end > len(subsystems) {
+			end = len(subsystems)
+		}
+		batch := subsystems[start:end]
+		samples := make([]*trafficSample, len(batch))
+		forEachWithBudget(ctx, client.clock, len(batch), concurrency, func(ctx context.Context, i int) error {
+			detail, err := client.GetSubsystemDetail(ctx, batch[i].SubsysID)
+			switch {
+			case IsNotFound(err):
+				logger.Printf("子系统 %s 已删除,跳过", batch[i].SubsysID)
+				return nil
+			case err != nil:
+				logger.Printf("获取子系统 %s 详情失败,跳过: %v", batch[i].SubsysID, err)
+				return err
+			}
+			samples[i] = &trafficSample{
+				Timestamp:     timestamp,
+				SubsysID:      batch[i].SubsysID,
+				ClusterName:   detail.ClusterName,
+				ActualTraffic: detail.ActualTraffic,
+			}
+			return nil
+		})
+
+		for _, sample := range samples {
+			if sample == nil {
+				continue
+			}
+			if err := recorder.Write(now, sample); err != nil {
+				return written, err
+			}
+			written++
+		}
+	}
+	return written, nil
+}
+
+// cmdRecordTraffic 按间隔记录每个子系统的实际流量,每行一个带时间戳的 JSON 对象,直到收到中断信号或达到 --timeout
+func cmdRecordTraffic(client *Client, args *CommandLineArgs) error {
+	if args.Out == "" {
+		return fmt.Errorf("请使用 --out 指定输出文件")
+	}
+	if args.Interval <= 0 {
+		return fmt.Errorf("--interval 必须大于 0")
+	}
+	if args.Concurrency <= 0 {
+		return fmt.Errorf("--concurrency 必须大于 0")
+	}
+
+	ctx, stop := context.WithCancel(args.Context())
+	defer stop()
+
+	recorder := newJSONLRecorder(args.Out)
+	defer recorder.Close()
+
+	var writeErr error
+	recordLoop(ctx, client.clock, args.Interval, func(now time.Time) {
+		n, err := recordTrafficOnce(ctx, client, recorder, now, args.Concurrency)
+		if err != nil {
+			writeErr = err
+			stop()
+			return
+		}
+		logger.Printf("已记录 %d 个子系统的流量", n)
+	})
+
+	return writeErr
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== 快照记录 ====================
+
+func TestDailyPath(t *testing.T) {
+	day := time.Date(2026, 1, 15, 23, 59, 0, 0, time.UTC)
+	tests := []struct {
+		base, want string
+	}{
+		{"dashboard.jsonl", "dashboard-20260115.jsonl"},
+		{"/var/log/weapm/traffic.jsonl", "/var/log/weapm/traffic-20260115.jsonl"},
+		{"snapshots", "snapshots-20260115"},
+	}
+	for _, tt := range tests {
+		if got := dailyPath(tt.base, day); got != tt.want {
+			t.Errorf("dailyPath(%q) = %q, want %q", tt.base, got, tt.want)
+		}
+	}
+}
+
+// readJSONL 读取 JSONL 文件的每一行
+func readJSONL(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestJSONLRecorderRotatesDaily(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "dashboard.jsonl")
+	recorder := newJSONLRecorder(base)
+	day1 := time.Date(2026, 1, 15, 23, 0, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Hour)
+
+	for _, write := range []struct {
+		at time.Time
+		n  int
+	}{{day1, 1}, {day1.Add(time.Minute), 2}, {day2, 3}} {
+		if err := recorder.Write(write.at, map[string]int{"n": write.n}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := readJSONL(t, dailyPath(base, day1)); len(lines) != 2 {
+		t.Errorf("day 1 has %d lines, want 2", len(lines))
+	}
+	if lines := readJSONL(t, dailyPath(base, day2)); len(lines) != 1 || lines[0]["n"] != float64(3) {
+		t.Errorf("day 2 lines = %v, want the third write", lines)
+	}
+	if _, err := os.Stat(base); !os.IsNotExist(err) {
+		t.Errorf("base path %s should not be written directly", base)
+	}
+}
+
+func TestRecordLoop(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticks := make(chan time.Time, 10)
+	done := make(chan struct{})
+	go func() {
+		recordLoop(ctx, clock, time.Minute, func(now time.Time) { ticks <- now })
+		close(done)
+	}()
+
+	// 立即执行一次,之后每推进一个间隔执行一次
+	for i := 0; i < 3; i++ {
+		got := <-ticks
+		if want := clock.Now(); !got.Equal(want) {
+			t.Errorf("tick %d at %v, want %v", i, got, want)
+		}
+		for clock.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if i < 2 {
+			clock.Advance(time.Minute)
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("recordLoop did not stop after cancel")
+	}
+	if len(ticks) != 0 {
+		t.Errorf("%d extra ticks after cancel", len(ticks))
+	}
+}
+
+// ==================== 子系统流量记录 ====================
+
+func TestRecordTrafficOnce(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/subsystems", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []SubSystem{{SubsysID: "SYS001"}, {SubsysID: "SYS002"}, {SubsysID: "SYS003"}})
+	})
+	api.handle("GET /operation/subsystem/SYS001", resultHandler(`{"clusterName": "LOG001", "actualTraffic": 2048}`))
+	// SYS002 已删除 (404)
+	api.handle("GET /operation/subsystem/SYS003", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusInternalServerError, 500, "boom")
+	})
+	client := newTestClient(t, api, func(c *Config) { c.MaxRetries = 0 })
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	recorder := newJSONLRecorder(path)
+	now := time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC)
+
+	n, err := recordTrafficOnce(context.Background(), client, recorder, now, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Close()
+	if n != 1 {
+		t.Errorf("written = %d, want 1 (deleted and failing subsystems skipped)", n)
+	}
+	lines := readJSONL(t, dailyPath(path, now))
+	if len(lines) != 1 || lines[0]["subsys_id"] != "SYS001" || lines[0]["actual_traffic"] != float64(2048) ||
+		lines[0]["cluster_name"] != "LOG001" || lines[0]["timestamp"] != "2026-01-15T08:00:00Z" {
+		t.Errorf("lines = %v, want one SYS001 sample", lines)
+	}
+}
+
+func TestRecordTrafficOnceBatches(t *testing.T) {
+	var subsystems []SubSystem
+	api := newFakeAPI()
+	for i := 1; i <= defaultPageSize+5; i++ {
+		id := fmt.Sprintf("SYS%03d", i)
+		subsystems = append(subsystems, SubSystem{SubsysID: id})
+		api.handle("GET /operation/subsystem/"+id, resultHandler(fmt.Sprintf(`{"actualTraffic": %d}`, i)))
+	}
+	api.handle("GET /operation/subsystems", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, subsystems)
+	})
+	client := newTestClient(t, api)
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	recorder := newJSONLRecorder(path)
+	now := time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC)
+
+	n, err := recordTrafficOnce(context.Background(), client, recorder, now, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Close()
+
+	// 跨批次仍按列表顺序写入
+	lines := readJSONL(t, dailyPath(path, now))
+	if n != len(subsystems) || len(lines) != len(subsystems) {
+		t.Fatalf("written = %d, lines = %d, want %d", n, len(lines), len(subsystems))
+	}
+	for i, line := range lines {
+		if line["subsys_id"] != subsystems[i].SubsysID {
+			t.Fatalf("line %d = %v, want %s", i, line, subsystems[i].SubsysID)
+		}
+	}
+}
+
+func TestRecordTrafficOnceListFailure(t *testing.T) {
+	api := newFakeAPI() // 子系统列表 404
+	client := newTestClient(t, api)
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	recorder := newJSONLRecorder(path)
+
+	n, err := recordTrafficOnce(context.Background(), client, recorder, time.Now(), 1)
+	if n != 0 || err != nil {
+		t.Errorf("recordTrafficOnce() = %d, %v, want 0, nil so the next interval retries", n, err)
+	}
+}
+
+func TestCmdRecordTrafficValidatesArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args CommandLineArgs
+		want string
+	}{
+		{"no out", CommandLineArgs{Interval: time.Minute, Concurrency: 1}, "--out"},
+		{"no interval", CommandLineArgs{Out: "traffic.jsonl", Concurrency: 1}, "--interval"},
+		{"no concurrency", CommandLineArgs{Out: "traffic.jsonl", Interval: time.Minute}, "--concurrency"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cmdRecordTraffic(NewClient(DefaultConfig("http://weapm")), &tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %s", err, tt.want)
+			}
+		})
+	}
+}
+
+// 命令的 context (--timeout 或中断信号) 结束时停止记录
+func TestCmdRecordStopsWithCommandContext(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/dashboard", resultHandler(`{"clusterNum":1}`))
+	client := newTestClient(t, api)
+	out := filepath.Join(t.TempDir(), "dashboard.jsonl")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	args := &CommandLineArgs{Out: out, Interval: time.Hour, ctx: ctx}
+	if err := cmdRecord(client, args); err != nil {
+		t.Fatal(err)
+	}
+	if n := api.count("GET /operation/dashboard"); n != 1 {
+		t.Errorf("dashboard requested %d times, want 1 before the deadline", n)
+	}
+}
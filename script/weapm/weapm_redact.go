@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ==================== 导出脱敏 ====================
+
+// 脱敏方式
+const (
+	RedactMask = "mask" // 替换为 ***
+	RedactHash = "hash" // 替换为稳定的哈希值,同一原值脱敏结果相同,便于对外分享后仍可关联
+)
+
+// Redactor 按 JSON 字段名对结构体中的字符串字段脱敏
+type Redactor struct {
+	fields map[string]bool
+	mode   string
+}
+
+// NewRedactor 创建脱敏器,fields 为逗号分隔的 JSON 字段名,
+// 字段需存在于 sample 的类型中 (含嵌套结构体),避免拼写错误导致静默不脱敏
+func NewRedactor(fields, mode string, sample interface{}) (*Redactor, error) {
+	if mode == "" {
+		mode = RedactMask
+	}
+	if mode != RedactMask && mode != RedactHash {
+		return nil, fmt.Errorf("不支持的脱敏方式: %s (可用: mask, hash)", mode)
+	}
+
+	known := make(map[string]bool)
+	collectJSONFields(reflect.TypeOf(sample), known)
+
+	r := &Redactor{fields: make(map[string]bool), mode: mode}
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !known[field] {
+			names := make([]string, 0, len(known))
+			for name := range known {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("不支持的脱敏字段: %q (可用: %s)", field, strings.Join(names, ", "))
+		}
+		r.fields[field] = true
+	}
+	return r, nil
+}
+
+// collectJSONFields 收集类型中所有字段的 JSON 名称
+func collectJSONFields(t reflect.Type, names map[string]bool) {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name := jsonFieldName(f); name != "" {
+			names[name] = true
+		}
+		collectJSONFields(f.Type, names)
+	}
+}
+
+// jsonFieldName 返回字段的 JSON 名称,忽略的字段返回空
+func jsonFieldName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// Apply 就地脱敏 v 指向的结构体 (或结构体切片),递归处理嵌套结构体; r 为 nil 时不做处理
+func (r *Redactor) Apply(v interface{}) {
+	if r == nil || len(r.fields) == 0 {
+		return
+	}
+	r.apply(reflect.ValueOf(v))
+}
+
+func (r *Redactor) apply(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			r.apply(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			r.apply(v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := v.Field(i)
+			if !f.CanSet() {
+				continue
+			}
+			if f.Kind() == reflect.String && r.fields[jsonFieldName(t.Field(i))] {
+				f.SetString(r.redact(f.String()))
+				continue
+			}
+			r.apply(f)
+		}
+	}
+}
+
+func (r *Redactor) redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	if r.mode == RedactHash {
+		sum := sha256.Sum256([]byte(s))
+		return "sha256:" + hex.EncodeToString(sum[:])[:12]
+	}
+	return redactedValue
+}
+
+// ==================== 日志脱敏 ====================
+
+// DefaultSensitiveParams 未配置 SensitiveParams 时在请求日志中脱敏的查询参数
+var DefaultSensitiveParams = []string{"password", "passwd", "token", "access_token", "api_key", "apikey", "secret", "signature"}
+
+// redactURL 返回用于日志的 URL: 去掉 userinfo 中的密码,sensitive 中的查询参数 (不区分大小写) 值替换为 ***
+// 其余参数保持原样及顺序; sensitive 为 nil 时使用 DefaultSensitiveParams
+func redactURL(u *url.URL, sensitive []string) string {
+	if sensitive == nil {
+		sensitive = DefaultSensitiveParams
+	}
+	redacted := *u
+	if redacted.RawQuery != "" {
+		pairs := strings.Split(redacted.RawQuery, "&")
+		for i, pair := range pairs {
+			key, _, hasValue := strings.Cut(pair, "=")
+			if name, err := url.QueryUnescape(key); err == nil {
+				key = name
+			}
+			if !hasValue || !containsFold(sensitive, key) {
+				continue
+			}
+			pairs[i] = strings.SplitN(pair, "=", 2)[0] + "=" + redactedValue
+		}
+		redacted.RawQuery = strings.Join(pairs, "&")
+	}
+	return redacted.Redacted()
+}
+
+// containsFold 判断 list 中是否有与 s 忽略大小写相等的项
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURLError 将 *url.Error 中的 URL 替换为脱敏后的形式,避免错误信息及日志中带出敏感参数
+func redactURLError(err error, sensitive []string) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		urlErr.URL = redactURL(u, sensitive)
+	}
+	return err
+}
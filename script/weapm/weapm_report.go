@@ -0,0 +1,490 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==================== 报表 ====================
+
+// DepartmentTraffic 部门流量汇总
+type DepartmentTraffic struct {
+	Department     string `json:"department"`
+	SubsystemCount int    `json:"subsystemCount"`
+	TotalTraffic   int64  `json:"totalTraffic"`
+	TopSubsystemID string `json:"topSubsystemId"`
+	TopSubsystem   string `json:"topSubsystem"`
+	TopTraffic     int64  `json:"topTraffic"`
+	Partial        bool   `json:"partial,omitempty"` // 部分子系统的流量未能获取,汇总值偏小
+}
+
+// unknownDepartment 未填写部门的子系统归入此分组
+const unknownDepartment = "(未知部门)"
+
+// AggregateDepartmentTraffic 按 DevDept 汇总子系统数、总流量及流量最大的子系统,按总流量降序排列
+// traffic 为 子系统ID -> 实际流量,缺失的子系统按 0 计算,并将其所在部门标记为 Partial
+func AggregateDepartmentTraffic(subsystems []SubSystem, traffic map[string]int64) []DepartmentTraffic {
+	byDept := make(map[string]*DepartmentTraffic)
+	for _, s := range subsystems {
+		dept := s.DevDept
+		if dept == "" {
+			dept = unknownDepartment
+		}
+		agg, ok := byDept[dept]
+		if !ok {
+			agg = &DepartmentTraffic{Department: dept}
+			byDept[dept] = agg
+		}
+
+		t, ok := traffic[s.SubsysID]
+		if !ok {
+			agg.Partial = true
+		}
+		agg.SubsystemCount++
+		agg.TotalTraffic += t
+		if agg.TopSubsystemID == "" || t > agg.TopTraffic {
+			agg.TopSubsystemID, agg.TopSubsystem, agg.TopTraffic = s.SubsysID, s.SubsysName, t
+		}
+	}
+
+	result := make([]DepartmentTraffic, 0, len(byDept))
+	for _, agg := range byDept {
+		result = append(result, *agg)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalTraffic != result[j].TotalTraffic {
+			return result[i].TotalTraffic > result[j].TotalTraffic
+		}
+		return result[i].Department < result[j].Department
+	})
+	return result
+}
+
+// GetSubsystemTrafficMap 并发获取子系统详情,返回 子系统ID -> 实际流量
+// 获取失败或超出 ctx 截止时间的子系统不在结果中,此时仍返回其余子系统的流量及汇总错误
+func (c *Client) GetSubsystemTrafficMap(ctx context.Context, subsystems []SubSystem) (map[string]int64, error) {
+	traffic := make([]int64, len(subsystems))
+	errs := forEachWithBudget(ctx, c.clock, len(subsystems), defaultConcurrency, func(ctx context.Context, i int) error {
+		detail, err := c.GetSubsystemDetail(ctx, subsystems[i].SubsysID)
+		if err != nil {
+			return err
+		}
+		traffic[i] = detail.ActualTraffic
+		return nil
+	})
+
+	result := make(map[string]int64, len(subsystems))
+	var failed int
+	var firstErr error
+	for i, s := range subsystems {
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("获取子系统 %s 详情失败: %w", s.SubsysID, errs[i])
+			}
+			failed++
+			continue
+		}
+		result[s.SubsysID] = traffic[i]
+	}
+	if firstErr != nil {
+		return result, fmt.Errorf("%d/%d 个子系统详情获取失败,首个错误: %w", failed, len(subsystems), firstErr)
+	}
+	return result, nil
+}
+
+func cmdReport(client *Client, args *CommandLineArgs) error {
+	sub := ""
+	if len(args.Positional) > 0 {
+		sub = args.Positional[0]
+	}
+
+	format, err := reportFormat(args)
+	if err != nil {
+		return err
+	}
+	// report --format md 未指定子命令时输出集群健康报告
+	if sub == "" && format == "md" {
+		sub = "health"
+	}
+
+	switch sub {
+	case "departments":
+		return cmdReportDepartments(client, args)
+	case "health":
+		return cmdReportHealth(client, args)
+	default:
+		return fmt.Errorf("未知 report 子命令: %q (可用: departments, health)", sub)
+	}
+}
+
+func cmdReportDepartments(client *Client, args *CommandLineArgs) error {
+	format, err := reportFormat(args)
+	if err != nil {
+		return err
+	}
+	ctx := args.Context()
+	subsystems, err := client.GetSubsystems(ctx)
+	if err != nil {
+		return err
+	}
+	// 部分子系统获取失败时仍输出汇总,失败涉及的部门标记为不完整,最后以错误退出
+	traffic, trafficErr := client.GetSubsystemTrafficMap(ctx, subsystems)
+	departments := AggregateDepartmentTraffic(subsystems, traffic)
+	if err := printDepartments(args, format, departments); err != nil {
+		return err
+	}
+	if trafficErr != nil {
+		return fmt.Errorf("部门流量汇总不完整 (标记 * 或 partial 的部门): %w", trafficErr)
+	}
+	return nil
+}
+
+// printDepartments 按 format 输出部门流量汇总,表格及 CSV 中不完整的部门名称后加 *
+func printDepartments(args *CommandLineArgs, format string, departments []DepartmentTraffic) error {
+	headers := []string{"DEPARTMENT", "SUBSYSTEMS", "TOTAL_TRAFFIC", "TOP_SUBSYSTEM", "TOP_TRAFFIC"}
+	rows := make([][]string, 0, len(departments))
+	for _, d := range departments {
+		name := d.Department
+		if d.Partial {
+			name += " *"
+		}
+		rows = append(rows, []string{
+			name,
+			strconv.Itoa(d.SubsystemCount),
+			strconv.FormatInt(d.TotalTraffic, 10),
+			fmt.Sprintf("%s (%s)", d.TopSubsystem, d.TopSubsystemID),
+			strconv.FormatInt(d.TopTraffic, 10),
+		})
+	}
+
+	switch format {
+	case "", outputTable:
+		return writeRows(os.Stdout, outputTable, headers, rows, args.MaxColWidth)
+	case outputCSV:
+		return writeRows(os.Stdout, outputCSV, headers, rows, args.MaxColWidth)
+	case outputJSON:
+		return printResult(args, departments)
+	default:
+		return fmt.Errorf("不支持的报表格式: %s (可用: table, csv, json)", format)
+	}
+}
+
+// ==================== 存储成本估算 ====================
+
+// ClusterCost 集群月度存储成本估算
+type ClusterCost struct {
+	ClusterName string  `json:"clusterName"`
+	UsedGB      float64 `json:"usedGb"`
+	CapacityGB  float64 `json:"capacityGb"`
+	MonthlyCost float64 `json:"monthlyCost"` // 按当前用量计算
+	// CapacityMonthlyCost 按容量 (存满) 计算,容量未知时为空
+	CapacityMonthlyCost *float64 `json:"capacityMonthlyCost,omitempty"`
+}
+
+// computeStorageCost 按每 GB 每月单价计算各集群成本
+func computeStorageCost(counts []ClusterLogCount, costPerGBMonth float64) []ClusterCost {
+	costs := make([]ClusterCost, 0, len(counts))
+	for _, count := range counts {
+		cost := ClusterCost{
+			ClusterName: count.ClusterName,
+			UsedGB:      float64(count.TotalLogBytes) / float64(gigabyte),
+			CapacityGB:  float64(count.CapacityBytes) / float64(gigabyte),
+		}
+		cost.MonthlyCost = cost.UsedGB * costPerGBMonth
+		if count.CapacityBytes > 0 {
+			capacityCost := cost.CapacityGB * costPerGBMonth
+			cost.CapacityMonthlyCost = &capacityCost
+		}
+		costs = append(costs, cost)
+	}
+	return costs
+}
+
+// EstimateStorageCost 根据数据大盘中的集群日志用量估算每个集群的月度存储成本
+func (c *Client) EstimateStorageCost(ctx context.Context, costPerGBMonth float64) ([]ClusterCost, error) {
+	if costPerGBMonth < 0 {
+		return nil, fmt.Errorf("每 GB 单价不能为负数: %v", costPerGBMonth)
+	}
+
+	dashboard, err := c.GetDashboard(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range dashboard.FailedSections {
+		if section == "clusterLogCounts" {
+			return nil, fmt.Errorf("数据大盘的集群日志统计解析失败,无法估算成本")
+		}
+	}
+
+	return computeStorageCost(dashboard.ClusterLogCounts, costPerGBMonth), nil
+}
+
+func cmdCostReport(client *Client, args *CommandLineArgs) error {
+	if args.CostPerGB <= 0 {
+		return fmt.Errorf("请使用 --cost-per-gb 指定每 GB 每月单价")
+	}
+
+	format, err := reportFormat(args)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "", outputTable, outputCSV, outputJSON:
+	default:
+		return fmt.Errorf("不支持的报表格式: %s (可用: table, csv, json)", format)
+	}
+
+	costs, err := client.EstimateStorageCost(args.Context(), args.CostPerGB)
+	if err != nil {
+		return err
+	}
+	if format == outputJSON {
+		return printResult(args, costs)
+	}
+
+	var total float64
+	rows := make([][]string, 0, len(costs)+1)
+	for _, cost := range costs {
+		capacityCost := "未知"
+		if cost.CapacityMonthlyCost != nil {
+			capacityCost = fmt.Sprintf("%.2f", *cost.CapacityMonthlyCost)
+		}
+		rows = append(rows, []string{
+			cost.ClusterName,
+			fmt.Sprintf("%.2f", cost.UsedGB),
+			fmt.Sprintf("%.2f", cost.CapacityGB),
+			fmt.Sprintf("%.2f", cost.MonthlyCost),
+			capacityCost,
+		})
+		total += cost.MonthlyCost
+	}
+	rows = append(rows, []string{"合计", "", "", fmt.Sprintf("%.2f", total), ""})
+
+	if format == "" {
+		format = outputTable
+	}
+	return writeRows(os.Stdout, format, []string{"CLUSTER", "USED_GB", "CAPACITY_GB", "MONTHLY_COST", "CAPACITY_COST"}, rows, args.MaxColWidth)
+}
+
+// ==================== 流量峰值 ====================
+
+// ClusterPeak 单个集群的流量峰值
+type ClusterPeak struct {
+	ClusterName string `json:"clusterName"`
+	PeakTraffic int64  `json:"peakTraffic"`
+	PeakTime    string `json:"peakTime"`
+}
+
+// FleetPeak 全部集群的流量峰值汇总
+type FleetPeak struct {
+	PeakTraffic int64         `json:"peakTraffic"`
+	ClusterName string        `json:"clusterName,omitempty"` // 峰值所在集群,所有集群峰值均为 0 时为空
+	PeakTime    string        `json:"peakTime,omitempty"`
+	Clusters    []ClusterPeak `json:"clusters"`
+}
+
+// aggregateFleetPeak 汇总各集群报表中的流量峰值,峰值相同时取先出现的集群; 未获取到的集群 (nil) 跳过
+func aggregateFleetPeak(details []*ClusterDetailResult) FleetPeak {
+	fleet := FleetPeak{Clusters: make([]ClusterPeak, 0, len(details))}
+	for _, detail := range details {
+		if detail == nil {
+			continue
+		}
+		peak := ClusterPeak{
+			ClusterName: detail.ClusterInfo.ClusterName,
+			PeakTraffic: detail.ReportData.PeakTraffic,
+			PeakTime:    detail.ReportData.PeakTime,
+		}
+		fleet.Clusters = append(fleet.Clusters, peak)
+		if peak.PeakTraffic > fleet.PeakTraffic {
+			fleet.PeakTraffic = peak.PeakTraffic
+			fleet.ClusterName = peak.ClusterName
+			fleet.PeakTime = peak.PeakTime
+		}
+	}
+	return fleet
+}
+
+// GetFleetPeakTraffic 并发获取所有集群报表,返回全局流量峰值及各集群峰值
+// 部分集群获取失败时仍返回其余集群的汇总结果及错误
+func (c *Client) GetFleetPeakTraffic(ctx context.Context) (FleetPeak, error) {
+	details, err := c.GetAllClusterDetails(ctx)
+	return aggregateFleetPeak(details), err
+}
+
+// ==================== 集群健康报告 ====================
+
+// ClusterHealthRow 健康报告中的单个集群
+type ClusterHealthRow struct {
+	ClusterName   string  `json:"clusterName"`
+	UsedBytes     int64   `json:"usedBytes"`
+	CapacityBytes int64   `json:"capacityBytes"`
+	Utilization   float64 `json:"utilization"` // 容量未知时为 -1
+	NodeCount     int     `json:"nodeCount"`
+	PeakTraffic   int64   `json:"peakTraffic"`
+	PeakTime      string  `json:"peakTime"`
+}
+
+// HealthReport 集群健康报告: 集群用量/节点/峰值、不健康节点及日志量 Top 子系统
+type HealthReport struct {
+	GeneratedAt    time.Time            `json:"generatedAt"`
+	Clusters       []ClusterHealthRow   `json:"clusters"`
+	UnhealthyNodes []LogStoreInstance   `json:"unhealthyNodes"`
+	TopSubsystems  []SubsystemLogDetail `json:"topSubsystems"`
+}
+
+// buildHealthReport 由数据大盘及各集群详情汇总健康报告; 未获取到的集群 (nil) 跳过
+func buildHealthReport(dashboard *DashboardResult, details []*ClusterDetailResult, now time.Time) *HealthReport {
+	usage := make(map[string]ClusterLogCount, len(dashboard.ClusterLogCounts))
+	for _, count := range dashboard.ClusterLogCounts {
+		usage[count.ClusterName] = count
+	}
+
+	report := &HealthReport{GeneratedAt: now, TopSubsystems: dashboard.TopSubsystems}
+	for _, detail := range details {
+		if detail == nil {
+			continue
+		}
+		name := detail.ClusterInfo.ClusterName
+		nodes := flattenNodeGroups(detail, name)
+		row := ClusterHealthRow{
+			ClusterName: name,
+			Utilization: -1,
+			NodeCount:   len(nodes),
+			PeakTraffic: detail.ReportData.PeakTraffic,
+			PeakTime:    detail.ReportData.PeakTime,
+		}
+		if count, ok := usage[name]; ok {
+			row.UsedBytes, row.CapacityBytes = count.TotalLogBytes, count.CapacityBytes
+			if count.CapacityBytes > 0 {
+				row.Utilization = float64(count.TotalLogBytes) / float64(count.CapacityBytes)
+			}
+		}
+		report.Clusters = append(report.Clusters, row)
+
+		for _, node := range nodes {
+			if !nodeHealthy(node) {
+				report.UnhealthyNodes = append(report.UnhealthyNodes, node)
+			}
+		}
+	}
+	return report
+}
+
+// GetHealthReport 获取数据大盘及所有集群详情生成健康报告
+// 部分集群详情获取失败时仍返回其余集群的报告及错误
+func (c *Client) GetHealthReport(ctx context.Context) (*HealthReport, error) {
+	dashboard, err := c.GetDashboard(ctx)
+	if err != nil {
+		return nil, err
+	}
+	details, err := c.GetAllClusterDetails(ctx)
+	if details == nil && err != nil {
+		return nil, err
+	}
+	return buildHealthReport(dashboard, details, time.Now()), err
+}
+
+// markdownCell 转义 Markdown 表格单元格中的竖线及换行,空值显示为 -
+func markdownCell(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "-"
+	}
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// writeMarkdownTable 输出 Markdown 表格,rows 为空时输出 "无"
+func writeMarkdownTable(w io.Writer, headers []string, rows [][]string) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "无")
+		return
+	}
+	separators := make([]string, len(headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(w, "| %s |\n", strings.Join(separators, " | "))
+	for _, row := range rows {
+		cells := make([]string, len(headers))
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = markdownCell(row[i])
+			} else {
+				cells[i] = "-"
+			}
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+// renderHealthMarkdown 将健康报告输出为 Markdown 文档
+func renderHealthMarkdown(w io.Writer, report *HealthReport) {
+	fmt.Fprintln(w, "# WEAPM 集群健康报告")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "生成时间: %s\n\n", report.GeneratedAt.Format("2006-01-02 15:04:05"))
+
+	fmt.Fprintf(w, "## 集群 (%d)\n\n", len(report.Clusters))
+	rows := make([][]string, 0, len(report.Clusters))
+	for _, c := range report.Clusters {
+		utilization := "未知"
+		if c.Utilization >= 0 {
+			utilization = fmt.Sprintf("%.1f%%", c.Utilization*100)
+		}
+		rows = append(rows, []string{
+			c.ClusterName,
+			utilization,
+			humanizeBytes(c.UsedBytes),
+			humanizeBytes(c.CapacityBytes),
+			strconv.Itoa(c.NodeCount),
+			strconv.FormatInt(c.PeakTraffic, 10),
+			c.PeakTime,
+		})
+	}
+	writeMarkdownTable(w, []string{"集群", "使用率", "已用", "容量", "节点数", "流量峰值", "峰值时间"}, rows)
+
+	fmt.Fprintf(w, "\n## 不健康节点 (%d)\n\n", len(report.UnhealthyNodes))
+	rows = make([][]string, 0, len(report.UnhealthyNodes))
+	for _, n := range report.UnhealthyNodes {
+		rows = append(rows, []string{n.ClusterName, n.Address, n.Role, n.Status})
+	}
+	writeMarkdownTable(w, []string{"集群", "地址", "角色", "状态"}, rows)
+
+	fmt.Fprintf(w, "\n## Top 子系统 (%d)\n\n", len(report.TopSubsystems))
+	rows = make([][]string, 0, len(report.TopSubsystems))
+	for _, s := range report.TopSubsystems {
+		rows = append(rows, []string{s.SubsysID, s.SubsysName, s.Department, s.ClusterName, strconv.FormatInt(s.TotalLogMb, 10)})
+	}
+	writeMarkdownTable(w, []string{"子系统ID", "名称", "部门", "集群", "日志量(MB)"}, rows)
+}
+
+func cmdReportHealth(client *Client, args *CommandLineArgs) error {
+	format, err := reportFormat(args)
+	if err != nil {
+		return err
+	}
+	if format != "" && format != "md" && format != outputJSON {
+		return fmt.Errorf("不支持的报表格式: %s (可用: md, json)", format)
+	}
+
+	report, err := client.GetHealthReport(args.Context())
+	if report == nil {
+		return err
+	}
+	if format == outputJSON {
+		if err := printResult(args, report); err != nil {
+			return err
+		}
+	} else {
+		renderHealthMarkdown(os.Stdout, report)
+	}
+	return err
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== 部门流量汇总 ====================
+
+func TestAggregateDepartmentTraffic(t *testing.T) {
+	subsystems := []SubSystem{
+		{SubsysID: "SYS001", SubsysName: "payment", DevDept: "交易研发部"},
+		{SubsysID: "SYS002", SubsysName: "order", DevDept: "交易研发部"},
+		{SubsysID: "SYS003", SubsysName: "account", DevDept: "基础研发部"},
+		{SubsysID: "SYS004", SubsysName: "report", DevDept: "数据部"},
+		{SubsysID: "SYS005", SubsysName: "legacy"},
+	}
+	// SYS004 流量缺失,数据部标记为 Partial
+	traffic := map[string]int64{"SYS001": 2048, "SYS002": 4096, "SYS003": 512, "SYS005": 512}
+
+	got := AggregateDepartmentTraffic(subsystems, traffic)
+	want := []DepartmentTraffic{
+		{Department: "交易研发部", SubsystemCount: 2, TotalTraffic: 6144, TopSubsystemID: "SYS002", TopSubsystem: "order", TopTraffic: 4096},
+		{Department: unknownDepartment, SubsystemCount: 1, TotalTraffic: 512, TopSubsystemID: "SYS005", TopSubsystem: "legacy", TopTraffic: 512},
+		{Department: "基础研发部", SubsystemCount: 1, TotalTraffic: 512, TopSubsystemID: "SYS003", TopSubsystem: "account", TopTraffic: 512},
+		{Department: "数据部", SubsystemCount: 1, TopSubsystemID: "SYS004", TopSubsystem: "report", Partial: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateDepartmentTraffic() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestGetSubsystemTrafficMapPartial(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/subsystem/SYS001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, SubsystemDetailResult{ActualTraffic: 2048})
+	})
+	client := newTestClient(t, api)
+	subsystems := []SubSystem{{SubsysID: "SYS001"}, {SubsysID: "SYS002"}}
+
+	traffic, err := client.GetSubsystemTrafficMap(context.Background(), subsystems)
+	if err == nil || !strings.Contains(err.Error(), "1/2") || !strings.Contains(err.Error(), "SYS002") {
+		t.Fatalf("error = %v, want 1/2 failures naming SYS002", err)
+	}
+	if !IsNotFound(err) {
+		t.Errorf("error = %v, want the 404 to stay inspectable", err)
+	}
+	// 其余子系统的流量仍然返回
+	if !reflect.DeepEqual(traffic, map[string]int64{"SYS001": 2048}) {
+		t.Errorf("traffic = %v, want only SYS001", traffic)
+	}
+
+	departments := AggregateDepartmentTraffic([]SubSystem{{SubsysID: "SYS001", DevDept: "A"}, {SubsysID: "SYS002", DevDept: "A"}}, traffic)
+	if len(departments) != 1 || !departments[0].Partial || departments[0].TotalTraffic != 2048 {
+		t.Errorf("departments = %+v, want A partial with 2048", departments)
+	}
+}
+
+// ==================== 存储成本估算 ====================
+
+func TestComputeStorageCost(t *testing.T) {
+	counts := []ClusterLogCount{
+		{ClusterName: "LOG001", TotalLogBytes: 300 * gigabyte, CapacityBytes: 1024 * gigabyte},
+		{ClusterName: "LOG002", TotalLogBytes: 512 * megabyte},
+	}
+
+	costs := computeStorageCost(counts, 0.5)
+	if len(costs) != 2 {
+		t.Fatalf("got %d costs, want 2", len(costs))
+	}
+	if c := costs[0]; c.UsedGB != 300 || c.MonthlyCost != 150 || c.CapacityMonthlyCost == nil || *c.CapacityMonthlyCost != 512 {
+		t.Errorf("LOG001 cost = %+v, want 150 used / 512 at capacity", c)
+	}
+	// 容量未知时不给出存满成本
+	if c := costs[1]; c.UsedGB != 0.5 || c.MonthlyCost != 0.25 || c.CapacityMonthlyCost != nil {
+		t.Errorf("LOG002 cost = %+v, want 0.25 used and no capacity cost", c)
+	}
+}
+
+func TestEstimateStorageCost(t *testing.T) {
+	tests := []struct {
+		name      string
+		dashboard string
+		perGB     float64
+		wantErr   string
+	}{
+		{"ok", `{"clusterLogCounts":[{"clustername":"LOG001","total_log_gb":"2GB"}]}`, 1, ""},
+		{"negative price", `{}`, -1, "不能为负数"},
+		{"bad section", `{"clusterLogCounts":"broken"}`, 1, "无法估算成本"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.handle("GET /operation/dashboard", resultHandler(tt.dashboard))
+			client := newTestClient(t, api)
+
+			costs, err := client.EstimateStorageCost(context.Background(), tt.perGB)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(costs) != 1 || costs[0].MonthlyCost != 2 {
+				t.Errorf("costs = %+v, want 2 for LOG001", costs)
+			}
+		})
+	}
+}
+
+// ==================== 全局流量峰值 ====================
+
+func TestAggregateFleetPeak(t *testing.T) {
+	detail := func(name string, peak int64, at string) *ClusterDetailResult {
+		return &ClusterDetailResult{
+			ClusterInfo: LogClusterInfo{ClusterName: name},
+			ReportData:  ClusterReportData{PeakTraffic: peak, PeakTime: at},
+		}
+	}
+	fleet := aggregateFleetPeak([]*ClusterDetailResult{
+		detail("LOG001", 100, "t1"),
+		nil, // 未获取到的集群
+		detail("LOG002", 300, "t2"),
+		detail("LOG003", 300, "t3"), // 峰值相同时取先出现的集群
+	})
+
+	if fleet.ClusterName != "LOG002" || fleet.PeakTraffic != 300 || fleet.PeakTime != "t2" {
+		t.Errorf("fleet peak = %s/%d/%s, want LOG002/300/t2", fleet.ClusterName, fleet.PeakTraffic, fleet.PeakTime)
+	}
+	if len(fleet.Clusters) != 3 {
+		t.Errorf("clusters = %+v, want 3 entries", fleet.Clusters)
+	}
+
+	if empty := aggregateFleetPeak(nil); empty.Clusters == nil || empty.PeakTraffic != 0 {
+		t.Errorf("aggregateFleetPeak(nil) = %+v, want an empty non-nil cluster list", empty)
+	}
+}
+
+func TestGetFleetPeakTrafficPartial(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{{ClusterName: "LOG001"}, {ClusterName: "LOG002"}})
+	})
+	api.handle("GET /operation/clusters/LOG001", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, ClusterDetailResult{
+			ClusterInfo: LogClusterInfo{ClusterName: "LOG001"},
+			ReportData:  ClusterReportData{PeakTraffic: 5368709120},
+		})
+	})
+	// LOG002 详情 404
+
+	fleet, err := newTestClient(t, api).GetFleetPeakTraffic(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "LOG002") {
+		t.Errorf("error = %v, want the LOG002 failure", err)
+	}
+	if fleet.ClusterName != "LOG001" || fleet.PeakTraffic != 5368709120 || len(fleet.Clusters) != 1 {
+		t.Errorf("fleet = %+v, want the LOG001 peak", fleet)
+	}
+}
+
+// ==================== 集群健康报告 ====================
+
+func TestMarkdownCell(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", "-"},
+		{"  ", "-"},
+		{"LOG001", "LOG001"},
+		{"a|b", `a\|b`},
+		{"line1\r\nline2\nline3", "line1 line2 line3"},
+	}
+	for _, tt := range tests {
+		if got := markdownCell(tt.in); got != tt.want {
+			t.Errorf("markdownCell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteMarkdownTable(t *testing.T) {
+	var buf bytes.Buffer
+	writeMarkdownTable(&buf, []string{"A", "B"}, [][]string{{"x|y", ""}, {"z"}})
+	want := "| A | B |\n| --- | --- |\n| x\\|y | - |\n| z | - |\n"
+	if buf.String() != want {
+		t.Errorf("table = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeMarkdownTable(&buf, []string{"A"}, nil)
+	if buf.String() != "无\n" {
+		t.Errorf("empty table = %q, want 无", buf.String())
+	}
+}
+
+func TestBuildHealthReport(t *testing.T) {
+	dashboard := &DashboardResult{
+		ClusterLogCounts: []ClusterLogCount{{ClusterName: "LOG001", TotalLogBytes: 250, CapacityBytes: 1000}},
+		TopSubsystems:    []SubsystemLogDetail{{SubsysID: "SYS001", TotalLogMb: 900}},
+	}
+	details := []*ClusterDetailResult{
+		{
+			ClusterInfo: LogClusterInfo{ClusterName: "LOG001"},
+			NodeGroups: []NodeGroup{{Role: "write", Nodes: []LogStoreInstance{
+				{Address: "10.0.0.1", Status: "running"},
+				{Address: "10.0.0.2", Status: "stopped"},
+			}}},
+			ReportData: ClusterReportData{PeakTraffic: 4096, PeakTime: "10:00"},
+		},
+		nil, // 获取失败的集群
+		{ClusterInfo: LogClusterInfo{ClusterName: "LOG002"}},
+	}
+	now := time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC)
+
+	report := buildHealthReport(dashboard, details, now)
+	want := []ClusterHealthRow{
+		{ClusterName: "LOG001", UsedBytes: 250, CapacityBytes: 1000, Utilization: 0.25, NodeCount: 2, PeakTraffic: 4096, PeakTime: "10:00"},
+		{ClusterName: "LOG002", Utilization: -1},
+	}
+	if !reflect.DeepEqual(report.Clusters, want) {
+		t.Errorf("Clusters = %+v, want %+v", report.Clusters, want)
+	}
+	// 节点组中的节点补全角色和集群
+	if len(report.UnhealthyNodes) != 1 || report.UnhealthyNodes[0].Address != "10.0.0.2" ||
+		report.UnhealthyNodes[0].Role != "write" || report.UnhealthyNodes[0].ClusterName != "LOG001" {
+		t.Errorf("UnhealthyNodes = %+v, want 10.0.0.2 in LOG001/write", report.UnhealthyNodes)
+	}
+	if !report.GeneratedAt.Equal(now) || len(report.TopSubsystems) != 1 {
+		t.Errorf("report = %+v, want the generation time and top subsystems", report)
+	}
+}
+
+func TestRenderHealthMarkdown(t *testing.T) {
+	report := &HealthReport{
+		GeneratedAt: time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC),
+		Clusters: []ClusterHealthRow{
+			{ClusterName: "LOG001", Utilization: 0.25, NodeCount: 2},
+			{ClusterName: "LOG002", Utilization: -1},
+		},
+		TopSubsystems: []SubsystemLogDetail{{SubsysID: "SYS001", SubsysName: "支付|结算", TotalLogMb: 900}},
+	}
+
+	var buf bytes.Buffer
+	renderHealthMarkdown(&buf, report)
+	output := buf.String()
+	for _, want := range []string{
+		"生成时间: 2026-01-15 08:00:00",
+		"## 集群 (2)",
+		"| LOG001 | 25.0% |",
+		"| LOG002 | 未知 |",
+		"## 不健康节点 (0)\n\n无\n",
+		`| SYS001 | 支付\|结算 |`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("markdown missing %q:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ==================== 序列化自检 ====================
+
+// schemaTypes 参与序列化自检的请求/响应类型
+var schemaTypes = []interface{}{
+	APIResponse{},
+	DashboardResult{},
+	ClusterTrafficData{},
+	SubsystemLogDetail{},
+	ClusterLogCount{},
+	LogClusterInfo{},
+	LogStoreInstance{},
+	ClusterDetailResult{},
+	NodeGroup{},
+	ClusterReportData{},
+	LogSubClusterSubSystem{},
+	SubSystem{},
+	SubsystemExistsResult{},
+	SubsystemDetailResult{},
+	SubsystemInstance{},
+	SubsystemPage{},
+	AsyncJob{},
+	AddClusterNodeRequest{},
+	AddSubsystemRequest{},
+	UpdateSubsystemRequest{},
+}
+
+// SchemaFinding 序列化自检发现的问题
+// error 表示往返序列化丢失数据或标签冲突; warning 表示标签与字段名相近但不一致,可能是拼写错误
+// (也可能是服务端字段本身的拼写,如 updateime)
+type SchemaFinding struct {
+	Type     string `json:"type"`
+	Field    string `json:"field"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+var (
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// jsonTagName 返回字段的 json 标签名,未设置标签时为空
+func jsonTagName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// editDistance 两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// checkSchemaTags 检查 json 标签冲突及疑似拼写错误
+// 标签与字段名忽略大小写和下划线后相同视为一致,编辑距离不超过 2 视为疑似拼写错误 (过短的字段名不检查)
+func checkSchemaTags(t reflect.Type) []SchemaFinding {
+	var findings []SchemaFinding
+	seen := make(map[string]string)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonTagName(field)
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		if other, ok := seen[name]; ok {
+			findings = append(findings, SchemaFinding{
+				Type: t.Name(), Field: field.Name, Severity: LintError,
+				Message: fmt.Sprintf("json 标签 %q 与字段 %s 重复,两个字段都不会被序列化", name, other),
+			})
+		}
+		seen[name] = field.Name
+
+		normalized := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+		fieldName := strings.ToLower(field.Name)
+		if len(fieldName) > 3 && normalized != fieldName && editDistance(normalized, fieldName) <= 2 {
+			findings = append(findings, SchemaFinding{
+				Type: t.Name(), Field: field.Name, Severity: LintWarning,
+				Message: fmt.Sprintf("json 标签 %q 与字段名相近,可能拼写错误", name),
+			})
+		}
+	}
+	return findings
+}
+
+// fillSample 为 v 填充非零示例值,depth 限制嵌套深度
+// 实现了 json.Unmarshaler 的类型填充后先做一次往返,得到其归一化后的值
+func fillSample(v reflect.Value, name string, depth int) {
+	if depth > 4 {
+		return
+	}
+	if v.Type() == rawMessageType {
+		v.SetBytes([]byte(`{"sample":1}`))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("sample-" + name)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(7)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(7)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf("sample-" + name))
+		}
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillSample(v.Elem(), name, depth+1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillSample(v.Index(0), name, depth+1)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		v.Set(reflect.MakeMap(v.Type()))
+		elem := reflect.New(v.Type().Elem()).Elem()
+		fillSample(elem, name, depth+1)
+		v.SetMapIndex(reflect.ValueOf("sample").Convert(v.Type().Key()), elem)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				fillSample(v.Field(i), t.Field(i).Name, depth+1)
+			}
+		}
+		if reflect.PtrTo(t).Implements(unmarshalerType) {
+			if data, err := json.Marshal(v.Interface()); err == nil {
+				_ = json.Unmarshal(data, v.Addr().Interface())
+			}
+		}
+	}
+}
+
+// checkSchemaRoundTrip 用示例数据往返序列化,报告值发生变化的字段
+func checkSchemaRoundTrip(t reflect.Type) []SchemaFinding {
+	sample := reflect.New(t).Elem()
+	fillSample(sample, t.Name(), 0)
+
+	data, err := json.Marshal(sample.Interface())
+	if err != nil {
+		return []SchemaFinding{{Type: t.Name(), Field: "-", Severity: LintError, Message: fmt.Sprintf("序列化失败: %v", err)}}
+	}
+	decoded := reflect.New(t)
+	if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+		return []SchemaFinding{{Type: t.Name(), Field: "-", Severity: LintError, Message: fmt.Sprintf("反序列化失败: %v", err)}}
+	}
+
+	var findings []SchemaFinding
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || jsonTagName(field) == "-" {
+			continue
+		}
+		if !reflect.DeepEqual(sample.Field(i).Interface(), decoded.Elem().Field(i).Interface()) {
+			findings = append(findings, SchemaFinding{
+				Type: t.Name(), Field: field.Name, Severity: LintError,
+				Message: fmt.Sprintf("往返序列化后值不一致: %v -> %v", sample.Field(i).Interface(), decoded.Elem().Field(i).Interface()),
+			})
+		}
+	}
+	return findings
+}
+
+// ValidateSchemas 对 types 中的每个类型检查 json 标签并做往返序列化
+func ValidateSchemas(types []interface{}) []SchemaFinding {
+	var findings []SchemaFinding
+	for _, v := range types {
+		t := reflect.TypeOf(v)
+		findings = append(findings, checkSchemaTags(t)...)
+		findings = append(findings, checkSchemaRoundTrip(t)...)
+	}
+	return findings
+}
+
+// cmdSchema schema --validate: 输出序列化自检结果,存在 error 级别问题时返回错误
+func cmdSchema(args *CommandLineArgs) error {
+	if !args.Validate {
+		return fmt.Errorf("请指定 --validate")
+	}
+
+	format, err := reportFormat(args)
+	if err != nil {
+		return err
+	}
+	if format != "" && format != outputTable && format != outputJSON {
+		return fmt.Errorf("schema 不支持 %s 输出 (可用: table, json)", format)
+	}
+
+	findings := ValidateSchemas(schemaTypes)
+	if format == outputJSON || args.tmpl != nil {
+		if err := printResult(args, findings); err != nil {
+			return err
+		}
+	} else if len(findings) == 0 {
+		fmt.Printf("✅ %d 个类型往返序列化一致\n", len(schemaTypes))
+	} else {
+		rows := make([][]string, 0, len(findings))
+		for _, f := range findings {
+			rows = append(rows, []string{f.Severity, f.Type, f.Field, f.Message})
+		}
+		if err := renderTable(os.Stdout, []string{"级别", "类型", "字段", "问题"}, rows, args.MaxColWidth); err != nil {
+			return err
+		}
+	}
+
+	errorCount := 0
+	for _, f := range findings {
+		if f.Severity == LintError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("序列化自检发现 %d 个错误", errorCount)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ==================== 序列化自检 ====================
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"updatetime", "updateime", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+type schemaTagSample struct {
+	UpdateTime  string `json:"updateime"`
+	ClusterName string `json:"cluster_name"`
+	Skipped     string `json:"-"`
+	ID          string `json:"idx"` // 过短的字段名不检查拼写
+}
+
+func TestCheckSchemaTags(t *testing.T) {
+	// 重复标签会被 go vet 拒绝,运行时构造
+	duplicate := reflect.StructOf([]reflect.StructField{
+		{Name: "First", Type: reflect.TypeOf(""), Tag: `json:"name"`},
+		{Name: "Second", Type: reflect.TypeOf(""), Tag: `json:"name"`},
+	})
+	tests := []struct {
+		typ  reflect.Type
+		want []string
+	}{
+		{reflect.TypeOf(schemaTagSample{}), []string{"warning/UpdateTime"}},
+		{duplicate, []string{"error/Second"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, f := range checkSchemaTags(tt.typ) {
+			got = append(got, f.Severity+"/"+f.Field)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("checkSchemaTags(%v) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+// lossyInt 序列化时丢失数值
+type lossyInt int
+
+func (lossyInt) MarshalJSON() ([]byte, error) { return []byte("0"), nil }
+
+type schemaRoundTripSample struct {
+	Name  string   `json:"name"`
+	Count lossyInt `json:"count"`
+}
+
+func TestCheckSchemaRoundTrip(t *testing.T) {
+	findings := checkSchemaRoundTrip(reflect.TypeOf(schemaRoundTripSample{}))
+	if len(findings) != 1 || findings[0].Field != "Count" || findings[0].Severity != LintError {
+		t.Errorf("findings = %+v, want one error for Count", findings)
+	}
+}
+
+// 已注册的请求/响应类型不应有 error 级别的问题
+func TestValidateSchemasRegisteredTypes(t *testing.T) {
+	for _, f := range ValidateSchemas(schemaTypes) {
+		if f.Severity == LintError {
+			t.Errorf("%s.%s: %s", f.Type, f.Field, f.Message)
+		}
+	}
+}
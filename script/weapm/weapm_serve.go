@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/Dreamshe-92/skill/script/weapm/weapmprom"
+)
+
+// ==================== 服务模式 ====================
+
+// healthzTimeout 健康检查探测服务端的超时时间
+const healthzTimeout = 5 * time.Second
+
+// healthStatus /healthz 响应
+type healthStatus struct {
+	Status    string  `json:"status"` // ok / unavailable
+	Server    string  `json:"server"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// newServeMux 创建服务模式的路由:
+//   - /healthz  服务端可达时返回 200,否则返回 503
+//   - /metrics  gatherer 中的 Prometheus 指标
+func newServeMux(client *Client, gatherer prometheus.Gatherer) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthzTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := client.Ping(ctx)
+		status := healthStatus{
+			Status:    "ok",
+			Server:    client.config.BaseURL,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		code := http.StatusOK
+		if err != nil {
+			status.Status, status.Error = "unavailable", err.Error()
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(status)
+	})
+
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+
+	return mux
+}
+
+// newMetricsRegistry 创建服务模式的指标注册表,包含客户端的缓存命中/未命中及重试次数
+// (采集时从客户端读取当前值),并将 weapmprom.Observer 设为客户端的指标观测,
+// 记录每个接口的请求耗时、重试及错误次数
+func newMetricsRegistry(client *Client) (*prometheus.Registry, error) {
+	reg := prometheus.NewRegistry()
+	observer, err := weapmprom.NewObserver(reg)
+	if err != nil {
+		return nil, fmt.Errorf("注册请求指标失败: %w", err)
+	}
+	client.SetMetricsObserver(observer)
+
+	collectors := []prometheus.Collector{
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "weapm_cache_hits_total",
+			Help: "Number of responses served from the cache.",
+		}, func() float64 { return float64(client.CacheStats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "weapm_cache_misses_total",
+			Help: "Number of cache lookups that missed.",
+		}, func() float64 { return float64(client.CacheStats().Misses) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "weapm_retries_total",
+			Help: "Number of request retries performed.",
+		}, func() float64 {
+			total, _ := client.RetryStats()
+			return float64(total)
+		}),
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, fmt.Errorf("注册指标失败: %w", err)
+		}
+	}
+	return reg, nil
+}
+
+// cmdServe 以服务模式运行,收到 SIGINT/SIGTERM 或达到 --timeout 后优雅退出
+func cmdServe(client *Client, args *CommandLineArgs) error {
+	reg, err := newMetricsRegistry(client)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:              args.Addr,
+		Handler:           newServeMux(client, reg),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := context.WithCancel(args.Context())
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Printf("服务模式已启动: %s (/healthz, /metrics)", args.Addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Printf("命令已取消,正在关闭服务")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("关闭服务失败: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ==================== 服务模式 ====================
+
+func TestHealthz(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantCode   int
+		wantStatus string
+	}{
+		{"available", http.StatusOK, http.StatusOK, "ok"},
+		{"unavailable", http.StatusServiceUnavailable, http.StatusServiceUnavailable, "unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.handle("GET "+pingEndpoint, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+			client := newTestClient(t, api)
+
+			rec := httptest.NewRecorder()
+			newServeMux(client, nil).ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			var status healthStatus
+			if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+				t.Fatal(err)
+			}
+			if status.Status != tt.wantStatus || status.Server != client.config.BaseURL {
+				t.Errorf("status = %+v, want %s for %s", status, tt.wantStatus, client.config.BaseURL)
+			}
+			if (status.Error != "") != (tt.wantStatus != "ok") {
+				t.Errorf("error = %q", status.Error)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{})
+	})
+	client := newTestClient(t, api, func(c *Config) { c.CacheTTL = time.Minute })
+	reg, err := newMetricsRegistry(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetClusters(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := httptest.NewServer(newServeMux(client, reg))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, want := range []string{
+		"weapm_cache_hits_total 1",
+		"weapm_cache_misses_total 1",
+		"weapm_retries_total 0",
+		"weapm_client_request_duration_seconds_count",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestCmdServeStopsWithCommandContext(t *testing.T) {
+	client := newTestClient(t, newFakeAPI())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := cmdServe(client, &CommandLineArgs{Addr: "127.0.0.1:0", ctx: ctx}); err != nil {
+		t.Errorf("cmdServe() = %v, want a clean shutdown", err)
+	}
+}
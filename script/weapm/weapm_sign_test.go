@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 请求签名 ====================
+
+// expectedSignature 按 METHOD\nPATH?QUERY\nBODY\nTIMESTAMP 独立计算签名
+func expectedSignature(secret, method, uri, body, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + uri + "\n" + body + "\n" + timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHMACSigner(t *testing.T) {
+	signer := NewHMACSigner("s3cret")
+	signer.now = func() time.Time { return time.Unix(1768464000, 0) }
+
+	req := httptest.NewRequest("POST", "http://weapm/operation/subsystem/SYS001?force=1", nil)
+	if err := signer.Sign(req, []byte(`{"traffic":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get(HeaderTimestamp); got != "1768464000" {
+		t.Errorf("%s = %q, want 1768464000", HeaderTimestamp, got)
+	}
+	want := expectedSignature("s3cret", "POST", "/operation/subsystem/SYS001?force=1", `{"traffic":1}`, "1768464000")
+	if got := req.Header.Get(HeaderSignature); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+}
+
+func TestSigningSecretSignsEveryAttempt(t *testing.T) {
+	var calls, valid atomic.Int32
+	api := newFakeAPI()
+	api.handle("PUT /operation/subsystem/SYS001", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := expectedSignature("s3cret", r.Method, r.URL.RequestURI(), string(body), r.Header.Get(HeaderTimestamp))
+		if r.Header.Get(HeaderSignature) == want {
+			valid.Add(1)
+		}
+		if calls.Add(1) == 1 {
+			respondError(w, http.StatusServiceUnavailable, 503, "busy")
+			return
+		}
+		respondResult(w, nil)
+	})
+	client := newTestClient(t, api, func(c *Config) { c.SigningSecret = "s3cret" })
+
+	traffic := int64(2048)
+	if err := client.UpdateSubsystem(context.Background(), "SYS001", &UpdateSubsystemRequest{Traffic: &traffic}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 || valid.Load() != 2 {
+		t.Errorf("valid signatures = %d of %d attempts, want 2 of 2", valid.Load(), calls.Load())
+	}
+}
+
+func TestRequestSignerError(t *testing.T) {
+	api := newFakeAPI()
+	client := newTestClient(t, api)
+	client.SetRequestSigner(RequestSignerFunc(func(req *http.Request, body []byte) error {
+		return errors.New("key unavailable")
+	}))
+
+	_, err := client.GetClusters(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "key unavailable") {
+		t.Errorf("error = %v, want the signer error", err)
+	}
+	if len(api.requests()) != 0 {
+		t.Errorf("requests = %v, want none when signing fails", api.requests())
+	}
+}
+
+func TestSigningSecretRedacted(t *testing.T) {
+	client := newTestClient(t, newFakeAPI(), func(c *Config) { c.SigningSecret = "s3cret" })
+	if got := client.EffectiveConfig().SigningSecret; got != redactedValue {
+		t.Errorf("EffectiveConfig().SigningSecret = %q, want %q", got, redactedValue)
+	}
+}
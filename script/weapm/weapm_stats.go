@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ==================== 重试统计 ====================
+
+// retryWindow 近期重试统计的时间窗口
+const retryWindow = time.Minute
+
+// retryTracker 线程安全的重试计数器,记录累计次数及最近一分钟内的重试时间点
+type retryTracker struct {
+	mu     sync.Mutex
+	total  int
+	recent []time.Time
+}
+
+// record 记录一次重试
+func (t *retryTracker) record(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total++
+	t.recent = append(t.prune(now), now)
+}
+
+// prune 丢弃窗口外的记录,调用方需持有锁
+func (t *retryTracker) prune(now time.Time) []time.Time {
+	cutoff := now.Add(-retryWindow)
+	i := 0
+	for i < len(t.recent) && !t.recent[i].After(cutoff) {
+		i++
+	}
+	return t.recent[i:]
+}
+
+func (t *retryTracker) stats(now time.Time) (total, last1m int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recent = t.prune(now)
+	return t.total, len(t.recent)
+}
+
+// RetryStats 返回客户端累计重试次数及最近一分钟内的重试次数,
+// 可用于调用方实现自适应的降级或限流
+func (c *Client) RetryStats() (total, last1m int) {
+	return c.retries.stats(c.clock.Now())
+}
+
+// ==================== 重试失败分类 ====================
+
+// 重试失败原因分类
+const (
+	RetryReasonServerError       = "server_error"       // 服务端返回 5xx
+	RetryReasonRateLimited       = "rate_limited"       // 服务端返回 429
+	RetryReasonStatus            = "status"             // 其他被判定为可重试的状态码
+	RetryReasonTimeout           = "timeout"            // 请求或读取超时
+	RetryReasonConnectionRefused = "connection_refused" // 连接被拒绝
+	RetryReasonNetwork           = "network"            // 其他网络错误
+)
+
+// RetryExhaustedError 重试次数用尽后返回的错误,记录各次失败的原因分类
+type RetryExhaustedError struct {
+	Attempts int            // 总尝试次数
+	Reason   string         // 出现次数最多的失败原因,次数相同时取最近一次
+	Reasons  map[string]int // 各失败原因出现次数
+	Last     error          // 最后一次失败的错误
+}
+
+func (e *RetryExhaustedError) Error() string {
+	return fmt.Sprintf("请求失败,已重试 %d 次 (主要原因: %s): %v", e.Attempts-1, e.Reason, e.Last)
+}
+
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.Last
+}
+
+// newRetryExhaustedError 按每次尝试的失败原因汇总生成错误
+func newRetryExhaustedError(reasons []string, last error) *RetryExhaustedError {
+	e := &RetryExhaustedError{Attempts: len(reasons), Reasons: make(map[string]int), Last: last}
+	for _, reason := range reasons {
+		e.Reasons[reason]++
+	}
+	for i := len(reasons) - 1; i >= 0; i-- {
+		if e.Reason == "" || e.Reasons[reasons[i]] > e.Reasons[e.Reason] {
+			e.Reason = reasons[i]
+		}
+	}
+	return e
+}
+
+// classifyRetryStatus 对可重试的状态码分类
+func classifyRetryStatus(statusCode int) string {
+	switch {
+	case statusCode >= 500:
+		return RetryReasonServerError
+	case statusCode == http.StatusTooManyRequests:
+		return RetryReasonRateLimited
+	case statusCode == http.StatusRequestTimeout:
+		return RetryReasonTimeout
+	default:
+		return RetryReasonStatus
+	}
+}
+
+// classifyRetryError 对请求或读取响应时的错误分类
+func classifyRetryError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return RetryReasonTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return RetryReasonConnectionRefused
+	default:
+		return RetryReasonNetwork
+	}
+}
+
+// ==================== 指标观测 ====================
+
+// MetricsObserver 请求指标观测接口,doRequest 在每次尝试 (包括重试) 结束后调用 ObserveRequest,
+// 在每次重试前调用 ObserveRetry; 实现需并发安全
+// Prometheus 实现见 weapmprom 子包,serve 模式下通过 /metrics 暴露
+type MetricsObserver interface {
+	// ObserveRequest 记录一次尝试的状态码及耗时,连接失败 (未收到响应) 时 status 为 0
+	ObserveRequest(method, endpoint string, status int, dur time.Duration)
+	// ObserveRetry 记录一次重试,reason 为上一次失败的原因分类 (RetryReason*)
+	ObserveRetry(method, endpoint, reason string)
+}
+
+// noopMetricsObserver 默认的空实现
+type noopMetricsObserver struct{}
+
+func (noopMetricsObserver) ObserveRequest(method, endpoint string, status int, dur time.Duration) {}
+func (noopMetricsObserver) ObserveRetry(method, endpoint, reason string)                          {}
+
+// SetMetricsObserver 设置请求指标观测,nil 表示不观测
+func (c *Client) SetMetricsObserver(observer MetricsObserver) {
+	if observer == nil {
+		observer = noopMetricsObserver{}
+	}
+	c.metrics = observer
+}
+
+// metricsEndpoint 去掉查询参数后的接口路径,作为指标的 endpoint 标签
+func metricsEndpoint(endpoint string) string {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		return endpoint[:i]
+	}
+	return endpoint
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// ==================== 重试统计 ====================
+
+func TestRetryTrackerWindow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var tracker retryTracker
+	tracker.record(start)
+	tracker.record(start.Add(30 * time.Second))
+	tracker.record(start.Add(50 * time.Second))
+
+	tests := []struct {
+		at         time.Duration
+		wantTotal  int
+		wantLast1m int
+	}{
+		{55 * time.Second, 3, 3},
+		{time.Minute, 3, 2}, // 恰好一分钟前的记录已在窗口外
+		{90 * time.Second, 3, 1},
+		{2 * time.Minute, 3, 0},
+	}
+	for _, tt := range tests {
+		total, last1m := tracker.stats(start.Add(tt.at))
+		if total != tt.wantTotal || last1m != tt.wantLast1m {
+			t.Errorf("stats(+%v) = %d, %d, want %d, %d", tt.at, total, last1m, tt.wantTotal, tt.wantLast1m)
+		}
+	}
+}
+
+func TestRetryStatsCountsRetries(t *testing.T) {
+	var calls atomic.Int32
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			respondError(w, http.StatusServiceUnavailable, 503, "busy")
+			return
+		}
+		respondResult(w, []LogClusterInfo{})
+	})
+	client := newTestClient(t, api)
+
+	if _, err := client.GetClusters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if total, last1m := client.RetryStats(); total != 2 || last1m != 2 {
+		t.Errorf("RetryStats() = %d, %d, want 2, 2", total, last1m)
+	}
+}
+
+// ==================== 重试失败分类 ====================
+
+func TestNewRetryExhaustedError(t *testing.T) {
+	last := errors.New("last")
+	tests := []struct {
+		name    string
+		reasons []string
+		want    string
+	}{
+		{"majority", []string{RetryReasonTimeout, RetryReasonServerError, RetryReasonServerError}, RetryReasonServerError},
+		// 次数相同时取最近一次
+		{"tie goes to most recent", []string{RetryReasonServerError, RetryReasonTimeout}, RetryReasonTimeout},
+		{"single", []string{RetryReasonNetwork}, RetryReasonNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newRetryExhaustedError(tt.reasons, last)
+			if e.Reason != tt.want {
+				t.Errorf("Reason = %q, want %q", e.Reason, tt.want)
+			}
+			if e.Attempts != len(tt.reasons) {
+				t.Errorf("Attempts = %d, want %d", e.Attempts, len(tt.reasons))
+			}
+			if !errors.Is(e, last) {
+				t.Error("error does not unwrap to the last error")
+			}
+		})
+	}
+}
+
+// timeoutError 实现 net.Error 的超时错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyRetryError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{context.DeadlineExceeded, RetryReasonTimeout},
+		{fmt.Errorf("read: %w", timeoutError{}), RetryReasonTimeout},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, RetryReasonConnectionRefused},
+		{io.ErrUnexpectedEOF, RetryReasonNetwork},
+	}
+	for _, tt := range tests {
+		if got := classifyRetryError(tt.err); got != tt.want {
+			t.Errorf("classifyRetryError(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryExhaustedServerError(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusBadGateway, 502, "bad gateway")
+	})
+	client := newTestClient(t, api, func(c *Config) { c.MaxRetries = 2 })
+
+	_, err := client.GetClusters(context.Background())
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("error = %v, want *RetryExhaustedError", err)
+	}
+	if exhausted.Attempts != 3 || exhausted.Reason != RetryReasonServerError || exhausted.Reasons[RetryReasonServerError] != 3 {
+		t.Errorf("error = %+v, want 3 server_error attempts", exhausted)
+	}
+}
+
+func TestRetryExhaustedConnectionRefused(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close() // 关闭后该地址拒绝连接
+
+	config := DefaultConfig(srv.URL)
+	config.RetryBackoff = time.Millisecond
+	config.MaxRetries = 1
+	_, err := NewClient(config).GetClusters(context.Background())
+
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("error = %v, want *RetryExhaustedError", err)
+	}
+	if exhausted.Reason != RetryReasonConnectionRefused {
+		t.Errorf("Reason = %q, want %q", exhausted.Reason, RetryReasonConnectionRefused)
+	}
+}
+
+func TestClassifyRetryStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusBadGateway, RetryReasonServerError},
+		{http.StatusTooManyRequests, RetryReasonRateLimited},
+		{http.StatusRequestTimeout, RetryReasonTimeout},
+		{http.StatusConflict, RetryReasonStatus},
+	}
+	for _, tt := range tests {
+		if got := classifyRetryStatus(tt.status); got != tt.want {
+			t.Errorf("classifyRetryStatus(%d) = %s, want %s", tt.status, got, tt.want)
+		}
+	}
+}
+
+// ==================== 指标观测 ====================
+
+// recordingObserver 记录所有观测调用
+type recordingObserver struct {
+	mu       sync.Mutex
+	requests []string
+	retries  []string
+}
+
+func (o *recordingObserver) ObserveRequest(method, endpoint string, status int, dur time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests = append(o.requests, fmt.Sprintf("%s %s %d", method, endpoint, status))
+}
+
+func (o *recordingObserver) ObserveRetry(method, endpoint, reason string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retries = append(o.retries, fmt.Sprintf("%s %s %s", method, endpoint, reason))
+}
+
+func TestMetricsObserver(t *testing.T) {
+	var calls atomic.Int32
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			dropConnection(t, w)
+		case 2:
+			respondError(w, http.StatusServiceUnavailable, 503, "busy")
+		default:
+			respondResult(w, []LogClusterInfo{})
+		}
+	})
+	client := newTestClient(t, api, func(c *Config) { c.MaxRetries = 3 })
+	observer := &recordingObserver{}
+	client.SetMetricsObserver(observer)
+
+	if _, err := client.GetClusters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// 连接失败的状态码为 0
+	wantRequests := []string{
+		"GET /operation/clusters 0",
+		"GET /operation/clusters 503",
+		"GET /operation/clusters 200",
+	}
+	if !reflect.DeepEqual(observer.requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", observer.requests, wantRequests)
+	}
+	wantRetries := []string{
+		"GET /operation/clusters " + RetryReasonNetwork,
+		"GET /operation/clusters " + RetryReasonServerError,
+	}
+	if !reflect.DeepEqual(observer.retries, wantRetries) {
+		t.Errorf("retries = %v, want %v", observer.retries, wantRetries)
+	}
+
+	// nil 恢复为空实现
+	client.SetMetricsObserver(nil)
+	if _, ok := client.metrics.(noopMetricsObserver); !ok {
+		t.Errorf("metrics = %T, want noopMetricsObserver", client.metrics)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	tests := []struct{ endpoint, want string }{
+		{"/operation/clusters", "/operation/clusters"},
+		{"/operation/subsystems?offset=0&limit=10", "/operation/subsystems"},
+	}
+	for _, tt := range tests {
+		if got := metricsEndpoint(tt.endpoint); got != tt.want {
+			t.Errorf("metricsEndpoint(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==================== 状态总览 ====================
+
+// capacityWarnRatio 集群用量超过容量的该比例时在总览中提示
+const capacityWarnRatio = 0.8
+
+// ClusterUsage 集群容量使用情况
+type ClusterUsage struct {
+	ClusterName string  `json:"clusterName"`
+	UsedBytes   int64   `json:"usedBytes"`
+	Capacity    int64   `json:"capacityBytes"`
+	Ratio       float64 `json:"ratio"`
+}
+
+// StatusSummary 服务端状态总览,各部分独立获取,失败的部分记录在对应的 *Error 字段中
+type StatusSummary struct {
+	Reachable  bool    `json:"reachable"`
+	LatencyMs  float64 `json:"latencyMs"`
+	PingError  string  `json:"pingError,omitempty"`
+	Clusters   int     `json:"clusters"`
+	Subsystems int     `json:"subsystems"`
+	CountError string  `json:"countError,omitempty"`
+
+	NearFull      []ClusterUsage `json:"nearFull"`
+	CapacityError string         `json:"capacityError,omitempty"`
+
+	UnhealthyNodes []LogStoreInstance `json:"unhealthyNodes"`
+	NodesError     string             `json:"nodesError,omitempty"`
+}
+
+// nearFullClusters 返回用量超过容量 ratio 的集群,按使用率降序; 容量未知的集群跳过
+func nearFullClusters(counts []ClusterLogCount, ratio float64) []ClusterUsage {
+	var usages []ClusterUsage
+	for _, count := range counts {
+		if count.CapacityBytes <= 0 {
+			continue
+		}
+		usage := ClusterUsage{
+			ClusterName: count.ClusterName,
+			UsedBytes:   count.TotalLogBytes,
+			Capacity:    count.CapacityBytes,
+			Ratio:       float64(count.TotalLogBytes) / float64(count.CapacityBytes),
+		}
+		if usage.Ratio >= ratio {
+			usages = append(usages, usage)
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Ratio > usages[j].Ratio })
+	return usages
+}
+
+// GetStatusSummary 并发获取状态总览,某一部分失败不影响其余部分
+func (c *Client) GetStatusSummary(ctx context.Context) *StatusSummary {
+	summary := &StatusSummary{}
+	var wg sync.WaitGroup
+	run := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+
+	run(func() {
+		start := time.Now()
+		if err := c.Ping(ctx); err != nil {
+			summary.PingError = err.Error()
+			return
+		}
+		summary.Reachable = true
+		summary.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	})
+	run(func() {
+		dashboard, err := c.GetDashboard(ctx)
+		if err != nil {
+			summary.CountError, summary.CapacityError = err.Error(), err.Error()
+			return
+		}
+		summary.Clusters, summary.Subsystems = dashboard.ClusterNum, dashboard.SubsystemCount
+		for _, section := range dashboard.FailedSections {
+			if section == "clusterLogCounts" {
+				summary.CapacityError = "集群日志统计解析失败"
+				return
+			}
+		}
+		summary.NearFull = nearFullClusters(dashboard.ClusterLogCounts, capacityWarnRatio)
+	})
+	run(func() {
+		nodes, err := c.ListAllNodes(ctx)
+		if err != nil {
+			summary.NodesError = err.Error()
+			return
+		}
+		for _, node := range nodes {
+			if !nodeHealthy(node) {
+				summary.UnhealthyNodes = append(summary.UnhealthyNodes, node)
+			}
+		}
+	})
+
+	wg.Wait()
+	return summary
+}
+
+// ANSI 颜色
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// useColor 标准输出为终端且未设置 NO_COLOR 时启用颜色
+func useColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// renderStatus 输出紧凑的人类可读总览
+func renderStatus(w io.Writer, s *StatusSummary, color bool) {
+	paint := func(c, text string) string {
+		if !color {
+			return text
+		}
+		return c + text + colorReset
+	}
+	unknown := func(err string) string {
+		return paint(colorYellow, "未知") + " (" + err + ")"
+	}
+	line := func(label, value string) {
+		fmt.Fprintf(w, "%s %s\n", padCell(label, 12), value)
+	}
+
+	if s.Reachable {
+		line("服务端", fmt.Sprintf("%s %.0fms", paint(colorGreen, "可达"), s.LatencyMs))
+	} else {
+		line("服务端", paint(colorRed, "不可达")+" "+s.PingError)
+	}
+
+	if s.CountError != "" {
+		line("集群/子系统", unknown(s.CountError))
+	} else {
+		line("集群/子系统", fmt.Sprintf("%d / %d", s.Clusters, s.Subsystems))
+	}
+
+	switch {
+	case s.CapacityError != "":
+		line("容量", unknown(s.CapacityError))
+	case len(s.NearFull) == 0:
+		line("容量", paint(colorGreen, fmt.Sprintf("无集群超过 %.0f%%", capacityWarnRatio*100)))
+	default:
+		items := make([]string, len(s.NearFull))
+		for i, u := range s.NearFull {
+			items[i] = fmt.Sprintf("%s %.0f%%", u.ClusterName, u.Ratio*100)
+		}
+		line("容量", paint(colorRed, strings.Join(items, ", ")))
+	}
+
+	switch {
+	case s.NodesError != "":
+		line("节点", unknown(s.NodesError))
+	case len(s.UnhealthyNodes) == 0:
+		line("节点", paint(colorGreen, "全部健康"))
+	default:
+		items := make([]string, len(s.UnhealthyNodes))
+		for i, node := range s.UnhealthyNodes {
+			items[i] = fmt.Sprintf("%s/%s(%s)", node.ClusterName, node.Address, node.Status)
+		}
+		line("节点", paint(colorRed, fmt.Sprintf("%d 个不健康: %s", len(items), strings.Join(items, ", "))))
+	}
+}
+
+func cmdStatus(client *Client, args *CommandLineArgs) error {
+	format, err := reportFormat(args)
+	if err != nil {
+		return err
+	}
+	if format != "" && format != outputTable && format != outputJSON {
+		return fmt.Errorf("status 不支持 %s 输出 (可用: table, json)", format)
+	}
+
+	summary := client.GetStatusSummary(args.Context())
+	if format == outputJSON || args.tmpl != nil {
+		return printResult(args, summary)
+	}
+	renderStatus(os.Stdout, summary, useColor())
+	return nil
+}
+
+// ==================== 迁移目标建议 ====================
+
+// ClusterSuggestion 可接收子系统流量的候选目标集群
+type ClusterSuggestion struct {
+	ClusterName      string  `json:"clusterName"`
+	FreeBytes        int64   `json:"freeBytes"` // 迁入前的剩余容量
+	CapacityBytes    int64   `json:"capacityBytes"`
+	UtilizationAfter float64 `json:"utilizationAfter"` // 迁入后的使用率
+}
+
+// subsystemTraffic 评估容量所用的子系统流量: 优先实际流量,尚无实际流量时使用预登记流量
+func subsystemTraffic(detail *SubsystemDetailResult) int64 {
+	if detail.ActualTraffic > 0 {
+		return detail.ActualTraffic
+	}
+	return detail.ExpectedTraffic
+}
+
+// rankTargetClusters 返回剩余容量可容纳 traffic 的集群,排除 current,按剩余容量降序
+// 容量未知的集群跳过
+func rankTargetClusters(counts []ClusterLogCount, current string, traffic int64) []ClusterSuggestion {
+	var suggestions []ClusterSuggestion
+	for _, count := range counts {
+		if count.ClusterName == current || count.CapacityBytes <= 0 {
+			continue
+		}
+		free := count.CapacityBytes - count.TotalLogBytes
+		if free < traffic {
+			continue
+		}
+		suggestions = append(suggestions, ClusterSuggestion{
+			ClusterName:      count.ClusterName,
+			FreeBytes:        free,
+			CapacityBytes:    count.CapacityBytes,
+			UtilizationAfter: float64(count.TotalLogBytes+traffic) / float64(count.CapacityBytes),
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].FreeBytes != suggestions[j].FreeBytes {
+			return suggestions[i].FreeBytes > suggestions[j].FreeBytes
+		}
+		return suggestions[i].ClusterName < suggestions[j].ClusterName
+	})
+	return suggestions
+}
+
+// SuggestTargetClusters 根据子系统流量及数据大盘中各集群的容量,返回可容纳该子系统的候选集群,
+// 按剩余容量降序排列,不含子系统当前归属的集群; 流量按字节与集群剩余容量比较
+func (c *Client) SuggestTargetClusters(ctx context.Context, subsystemID string) ([]ClusterSuggestion, error) {
+	detail, err := c.GetSubsystemDetail(ctx, subsystemID)
+	if err != nil {
+		return nil, fmt.Errorf("获取子系统详情失败: %w", err)
+	}
+	dashboard, err := c.GetDashboard(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取集群容量失败: %w", err)
+	}
+	return rankTargetClusters(dashboard.ClusterLogCounts, detail.ClusterName, subsystemTraffic(detail)), nil
+}
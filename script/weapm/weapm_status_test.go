@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ==================== 状态总览 ====================
+
+func TestNearFullClusters(t *testing.T) {
+	counts := []ClusterLogCount{
+		{ClusterName: "LOG001", TotalLogBytes: 50, CapacityBytes: 100},
+		{ClusterName: "LOG002", TotalLogBytes: 95, CapacityBytes: 100},
+		{ClusterName: "LOG003", TotalLogBytes: 80, CapacityBytes: 100},
+		{ClusterName: "LOG004", TotalLogBytes: 500}, // 容量未知
+	}
+	usages := nearFullClusters(counts, 0.8)
+	if len(usages) != 2 || usages[0].ClusterName != "LOG002" || usages[1].ClusterName != "LOG003" {
+		t.Errorf("nearFullClusters() = %+v, want LOG002 then LOG003", usages)
+	}
+}
+
+func TestGetStatusSummary(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{{ClusterName: "LOG001"}})
+	})
+	api.handle("GET /operation/clusters/LOG001", clusterWithNodes("LOG001",
+		LogStoreInstance{Address: "10.0.0.1", ClusterName: "LOG001", Status: "running"},
+		LogStoreInstance{Address: "10.0.0.2", ClusterName: "LOG001", Status: "stopped"}))
+	api.handle("GET /operation/dashboard", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, map[string]interface{}{
+			"clusterNum":       1,
+			"subsystemCount":   12,
+			"clusterLogCounts": []map[string]interface{}{{"clustername": "LOG001", "total_log_gb": 900, "capacity": 1000}},
+		})
+	})
+	summary := newTestClient(t, api).GetStatusSummary(context.Background())
+
+	if !summary.Reachable || summary.Clusters != 1 || summary.Subsystems != 12 {
+		t.Errorf("summary = %+v, want reachable with 1 cluster and 12 subsystems", summary)
+	}
+	if len(summary.NearFull) != 1 || summary.NearFull[0].ClusterName != "LOG001" {
+		t.Errorf("NearFull = %+v, want LOG001", summary.NearFull)
+	}
+	if len(summary.UnhealthyNodes) != 1 || summary.UnhealthyNodes[0].Address != "10.0.0.2" {
+		t.Errorf("UnhealthyNodes = %+v, want 10.0.0.2", summary.UnhealthyNodes)
+	}
+}
+
+func TestGetStatusSummaryPartialFailure(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, []LogClusterInfo{})
+	})
+	// 数据大盘接口 404,其余部分不受影响
+	summary := newTestClient(t, api).GetStatusSummary(context.Background())
+
+	if !summary.Reachable || summary.NodesError != "" {
+		t.Errorf("summary = %+v, want ping and nodes to succeed", summary)
+	}
+	if summary.CountError == "" || summary.CapacityError == "" {
+		t.Errorf("summary = %+v, want count and capacity errors", summary)
+	}
+}
+
+func TestRenderStatus(t *testing.T) {
+	summary := &StatusSummary{
+		Reachable:      true,
+		LatencyMs:      12,
+		CountError:     "dashboard unavailable",
+		CapacityError:  "dashboard unavailable",
+		UnhealthyNodes: []LogStoreInstance{{ClusterName: "LOG001", Address: "10.0.0.2", Status: "stopped"}},
+	}
+
+	var buf bytes.Buffer
+	renderStatus(&buf, summary, false)
+	output := buf.String()
+	for _, want := range []string{"可达 12ms", "未知 (dashboard unavailable)", "1 个不健康: LOG001/10.0.0.2(stopped)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "\033[") {
+		t.Errorf("output contains color codes with color disabled:\n%s", output)
+	}
+
+	buf.Reset()
+	renderStatus(&buf, summary, true)
+	if !strings.Contains(buf.String(), colorRed) {
+		t.Errorf("colored output missing red for unhealthy nodes:\n%s", buf.String())
+	}
+}
+
+// ==================== 迁移目标建议 ====================
+
+func TestSubsystemTraffic(t *testing.T) {
+	if got := subsystemTraffic(&SubsystemDetailResult{ActualTraffic: 300, ExpectedTraffic: 100}); got != 300 {
+		t.Errorf("subsystemTraffic() = %d, want the actual traffic", got)
+	}
+	if got := subsystemTraffic(&SubsystemDetailResult{ExpectedTraffic: 100}); got != 100 {
+		t.Errorf("subsystemTraffic() = %d, want the expected traffic without actual traffic", got)
+	}
+}
+
+func TestRankTargetClusters(t *testing.T) {
+	counts := []ClusterLogCount{
+		{ClusterName: "LOG001", TotalLogBytes: 100, CapacityBytes: 1000}, // 当前集群
+		{ClusterName: "LOG002", TotalLogBytes: 900, CapacityBytes: 1000}, // 剩余 100,不足
+		{ClusterName: "LOG003", TotalLogBytes: 500, CapacityBytes: 1000},
+		{ClusterName: "LOG004", TotalLogBytes: 0},                         // 容量未知
+		{ClusterName: "LOG005", TotalLogBytes: 1500, CapacityBytes: 2000}, // 剩余与 LOG003 相同
+		{ClusterName: "LOG006", TotalLogBytes: 0, CapacityBytes: 800},
+	}
+
+	suggestions := rankTargetClusters(counts, "LOG001", 200)
+	var names []string
+	for _, s := range suggestions {
+		names = append(names, s.ClusterName)
+	}
+	if want := []string{"LOG006", "LOG003", "LOG005"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("suggestions = %v, want %v", names, want)
+	}
+	if s := suggestions[1]; s.FreeBytes != 500 || s.UtilizationAfter != 0.7 {
+		t.Errorf("LOG003 = %+v, want 500 free and 0.7 utilization after", s)
+	}
+}
+
+func TestSuggestTargetClusters(t *testing.T) {
+	api := newFakeAPI()
+	api.handle("GET /operation/subsystem/SYS001", resultHandler(`{"clusterName": "LOG001", "actualTraffic": 200}`))
+	api.handle("GET /operation/dashboard", func(w http.ResponseWriter, r *http.Request) {
+		respondResult(w, map[string]interface{}{
+			"clusterLogCounts": []map[string]interface{}{
+				{"clustername": "LOG001", "total_log_gb": 0, "capacity": 50},
+				{"clustername": "LOG002", "total_log_gb": 1, "capacity": 10},
+			},
+		})
+	})
+
+	suggestions, err := newTestClient(t, api).SuggestTargetClusters(context.Background(), "SYS001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].ClusterName != "LOG002" {
+		t.Errorf("suggestions = %+v, want only LOG002", suggestions)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ==================== 耗时统计 ====================
+
+// networkStats 客户端累计的网络耗时 (发送请求到读完响应体) 及请求次数
+type networkStats struct {
+	nanos    int64
+	requests int64
+}
+
+func (s *networkStats) record(d time.Duration) {
+	atomic.AddInt64(&s.nanos, int64(d))
+	atomic.AddInt64(&s.requests, 1)
+}
+
+// NetworkTime 返回客户端累计的网络耗时及 HTTP 请求次数 (含重试),并发请求的耗时会叠加
+func (c *Client) NetworkTime() (time.Duration, int) {
+	return time.Duration(atomic.LoadInt64(&c.network.nanos)), int(atomic.LoadInt64(&c.network.requests))
+}
+
+// 阶段名称
+const (
+	phaseConfig  = "config"
+	phaseClient  = "client"
+	phaseNetwork = "network"
+	phaseRender  = "render"
+)
+
+type phase struct {
+	name   string
+	d      time.Duration
+	detail string
+}
+
+// phaseTimer 按顺序记录命令各阶段耗时,未启用时所有方法为空操作
+type phaseTimer struct {
+	enabled bool
+	start   time.Time
+	last    time.Time
+	phases  []phase
+}
+
+func newPhaseTimer(enabled bool) *phaseTimer {
+	now := time.Now()
+	return &phaseTimer{enabled: enabled, start: now, last: now}
+}
+
+// Mark 记录从上一次 Mark 到现在的耗时为 name 阶段
+func (t *phaseTimer) Mark(name string) {
+	now := time.Now()
+	if t.enabled {
+		t.phases = append(t.phases, phase{name: name, d: now.Sub(t.last)})
+	}
+	t.last = now
+}
+
+// Add 直接记录一个阶段的耗时
+func (t *phaseTimer) Add(name string, d time.Duration, detail string) {
+	if t.enabled {
+		t.phases = append(t.phases, phase{name: name, d: d, detail: detail})
+	}
+}
+
+// Report 输出各阶段耗时及总耗时
+func (t *phaseTimer) Report(w io.Writer) {
+	if !t.enabled {
+		return
+	}
+	fmt.Fprintln(w, "⏱  耗时:")
+	for _, p := range t.phases {
+		line := fmt.Sprintf("  %s %8.1fms", padCell(p.name, 8), float64(p.d.Microseconds())/1000)
+		if p.detail != "" {
+			line += " (" + p.detail + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "  %s %8.1fms\n", padCell("total", 8), float64(time.Since(t.start).Microseconds())/1000)
+}
+
+// recordCommand 将命令执行耗时拆分为网络与本地处理 (渲染) 两部分
+func (t *phaseTimer) recordCommand(client *Client) {
+	if !t.enabled {
+		return
+	}
+	now := time.Now()
+	command := now.Sub(t.last)
+	t.last = now
+
+	network, requests := client.NetworkTime()
+	render := command - network
+	if render < 0 {
+		// 并发请求的网络耗时叠加后可能超过命令耗时
+		render = 0
+	}
+	t.Add(phaseNetwork, network, fmt.Sprintf("%d 次请求", requests))
+	t.Add(phaseRender, render, "")
+}
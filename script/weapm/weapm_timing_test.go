@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 耗时统计 ====================
+
+func TestNetworkTimeCountsRetries(t *testing.T) {
+	var calls atomic.Int32
+	api := newFakeAPI()
+	api.handle("GET /operation/clusters", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		if calls.Add(1) == 1 {
+			respondError(w, http.StatusServiceUnavailable, 503, "busy")
+			return
+		}
+		respondResult(w, []LogClusterInfo{})
+	})
+	client := newTestClient(t, api)
+
+	if _, err := client.GetClusters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	network, requests := client.NetworkTime()
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 including the retry", requests)
+	}
+	if network < 10*time.Millisecond {
+		t.Errorf("network = %v, want at least 10ms", network)
+	}
+}
+
+func TestPhaseTimerReport(t *testing.T) {
+	client := NewClient(DefaultConfig("http://weapm"))
+	client.network.record(40 * time.Millisecond)
+	client.network.record(20 * time.Millisecond)
+
+	timer := newPhaseTimer(true)
+	timer.Mark(phaseConfig)
+	timer.Mark(phaseClient)
+	timer.recordCommand(client)
+
+	var buf bytes.Buffer
+	timer.Report(&buf)
+	output := buf.String()
+	for _, want := range []string{"config", "client", "60.0ms (2 次请求)", "render", "total"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("report missing %q:\n%s", want, output)
+		}
+	}
+	// 网络耗时超过命令耗时 (并发叠加) 时渲染耗时记为 0
+	if last := timer.phases[len(timer.phases)-1]; last.name != phaseRender || last.d != 0 {
+		t.Errorf("render phase = %+v, want 0", last)
+	}
+}
+
+func TestPhaseTimerDisabled(t *testing.T) {
+	timer := newPhaseTimer(false)
+	timer.Mark(phaseConfig)
+	timer.recordCommand(NewClient(DefaultConfig("http://weapm")))
+
+	var buf bytes.Buffer
+	timer.Report(&buf)
+	if buf.Len() != 0 || len(timer.phases) != 0 {
+		t.Errorf("disabled timer recorded %v and wrote %q", timer.phases, buf.String())
+	}
+}
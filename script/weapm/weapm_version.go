@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// ==================== 版本信息 ====================
+
+// 构建时通过 -ldflags 注入,例如:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// buildInfo 返回版本、提交及构建时间; 未注入提交/时间时尝试从 Go 嵌入的 VCS 信息读取,仍没有时为 unknown
+func buildInfo() (v, c, d string) {
+	v, c, d = version, commit, buildDate
+	if c == "" || d == "" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range info.Settings {
+				switch {
+				case s.Key == "vcs.revision" && c == "":
+					c = s.Value
+				case s.Key == "vcs.time" && d == "":
+					d = s.Value
+				}
+			}
+		}
+	}
+	if c == "" {
+		c = "unknown"
+	}
+	if d == "" {
+		d = "unknown"
+	}
+	return v, c, d
+}
+
+// printVersion 输出版本信息,用于 version 命令及 --version
+func printVersion(w io.Writer) {
+	v, c, d := buildInfo()
+	fmt.Fprintf(w, "weapm_cli %s (commit %s, built %s, %s %s/%s)\n", v, c, d, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
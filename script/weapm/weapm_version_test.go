@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+)
+
+// ==================== 版本信息 ====================
+
+// setBuildVars 临时替换 -ldflags 注入的变量
+func setBuildVars(t *testing.T, v, c, d string) {
+	t.Helper()
+	oldVersion, oldCommit, oldDate := version, commit, buildDate
+	version, commit, buildDate = v, c, d
+	t.Cleanup(func() { version, commit, buildDate = oldVersion, oldCommit, oldDate })
+}
+
+func TestPrintVersion(t *testing.T) {
+	setBuildVars(t, "v1.2.0", "abc1234", "2026-01-15T00:00:00Z")
+
+	var buf bytes.Buffer
+	printVersion(&buf)
+	want := "weapm_cli v1.2.0 (commit abc1234, built 2026-01-15T00:00:00Z, " + runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH + ")\n"
+	if buf.String() != want {
+		t.Errorf("printVersion() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestBuildInfoFallback(t *testing.T) {
+	setBuildVars(t, "dev", "", "")
+
+	// 未注入时从 VCS 信息读取,测试二进制中没有 VCS 信息则为 unknown
+	v, c, d := buildInfo()
+	if v != "dev" || c == "" || d == "" {
+		t.Errorf("buildInfo() = %q, %q, %q, want dev and non-empty commit/date", v, c, d)
+	}
+
+	// 已注入的值优先于 VCS 信息
+	setBuildVars(t, "v1.2.0", "abc1234", "")
+	if _, c, _ := buildInfo(); c != "abc1234" {
+		t.Errorf("commit = %q, want the injected abc1234", c)
+	}
+}
@@ -0,0 +1,75 @@
+// Package weapmprom 提供 WEAPM 客户端请求指标的 Prometheus 实现
+//
+// Observer 实现了客户端的 MetricsObserver 接口:
+//
+//	observer, err := weapmprom.NewObserver(prometheus.DefaultRegisterer)
+//	if err != nil {
+//		return err
+//	}
+//	client.SetMetricsObserver(observer)
+//
+// endpoint 标签为不含查询参数的接口路径,路径中含集群名、子系统 ID 时标签数随之增长
+package weapmprom
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "weapm_client"
+
+// Observer 按 method、endpoint 记录请求耗时、重试次数及错误次数
+type Observer struct {
+	latency *prometheus.HistogramVec
+	retries *prometheus.CounterVec
+	errors  *prometheus.CounterVec
+}
+
+// NewObserver 创建 Observer 并将指标注册到 reg
+//
+//	weapm_client_request_duration_seconds{method,endpoint,code}  每次尝试的耗时
+//	weapm_client_retries_total{method,endpoint,reason}           重试次数
+//	weapm_client_errors_total{method,endpoint,code}              连接失败 (code="0") 及 HTTP 4xx/5xx 次数
+func NewObserver(reg prometheus.Registerer) (*Observer, error) {
+	o := &Observer{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "WEAPM API 单次请求尝试的耗时",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint", "code"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "WEAPM API 请求重试次数",
+		}, []string{"method", "endpoint", "reason"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "WEAPM API 请求失败次数 (连接失败及 HTTP 4xx/5xx)",
+		}, []string{"method", "endpoint", "code"}),
+	}
+
+	for _, c := range []prometheus.Collector{o.latency, o.retries, o.errors} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// ObserveRequest 记录一次尝试,status 为 0 表示连接失败
+func (o *Observer) ObserveRequest(method, endpoint string, status int, dur time.Duration) {
+	code := strconv.Itoa(status)
+	o.latency.WithLabelValues(method, endpoint, code).Observe(dur.Seconds())
+	if status == 0 || status >= 400 {
+		o.errors.WithLabelValues(method, endpoint, code).Inc()
+	}
+}
+
+// ObserveRetry 记录一次重试
+func (o *Observer) ObserveRetry(method, endpoint, reason string) {
+	o.retries.WithLabelValues(method, endpoint, reason).Inc()
+}
@@ -0,0 +1,56 @@
+package weapmprom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserver(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	observer, err := NewObserver(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observer.ObserveRequest("GET", "/operation/clusters", 0, 10*time.Millisecond)
+	observer.ObserveRequest("GET", "/operation/clusters", 503, 20*time.Millisecond)
+	observer.ObserveRequest("GET", "/operation/clusters", 200, 30*time.Millisecond)
+	observer.ObserveRetry("GET", "/operation/clusters", "network")
+	observer.ObserveRetry("GET", "/operation/clusters", "server_error")
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"errors code=0", testutil.ToFloat64(observer.errors.WithLabelValues("GET", "/operation/clusters", "0")), 1},
+		{"errors code=503", testutil.ToFloat64(observer.errors.WithLabelValues("GET", "/operation/clusters", "503")), 1},
+		{"retries network", testutil.ToFloat64(observer.retries.WithLabelValues("GET", "/operation/clusters", "network")), 1},
+		{"retries server_error", testutil.ToFloat64(observer.retries.WithLabelValues("GET", "/operation/clusters", "server_error")), 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	// 成功的请求只记录耗时,不计入错误
+	if n := testutil.CollectAndCount(observer.errors); n != 2 {
+		t.Errorf("error series = %d, want 2", n)
+	}
+	if n := testutil.CollectAndCount(observer.latency); n != 3 {
+		t.Errorf("latency series = %d, want 3 (one per code)", n)
+	}
+}
+
+func TestNewObserverDuplicateRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := NewObserver(reg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewObserver(reg); err == nil {
+		t.Error("second NewObserver on the same registry: error = nil, want a registration error")
+	}
+}